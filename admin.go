@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+const (
+	createUserUsage    = "create-user -username <name> -email <email> [-password <password>] [-verified]"
+	resetPasswordUsage = "reset-password [-password <password>] <username or email>"
+	seedUsage          = "seed [-seed <n>] [-users <n>] [-players <n>] [-doubles <n>] [-matches <n>] [-sets <n>] [-password <password>]"
+)
+
+// createUserCommand registers an account like the register route does,
+// without a session. A password is generated and printed when none is given.
+func createUserCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	username := flags.String("username", "", "name to log in with")
+	email := flags.String("email", "", "email address of the account")
+	password := flags.String("password", "", "password, generated when empty")
+	verified := flags.Bool("verified", false, "mark the email address as verified")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("usage: %s", createUserUsage)
+	}
+
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+	}
+	input := handler.RegisterInput{Username: *username, Email: *email, Password: *password, Confirm: *password}
+	if err := handler.Validate(input); err != nil {
+		return err
+	}
+
+	dbQueries := db.New(dbCon)
+	userHandler := handler.NewUserHandler(dbQueries, cfg)
+	user, err := userHandler.CreateUser(ctx, handler.CreateUserInput{
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+	})
+	if err != nil {
+		return err
+	}
+	if *verified {
+		if user, err = dbQueries.VerifyUserEmailById(ctx, user.ID); err != nil {
+			return handler.DatabaseError(err)
+		}
+	}
+
+	fmt.Fprintf(os.Stdout, "created %s (%s)\n", user.Username, user.ID)
+	if generated {
+		fmt.Fprintf(os.Stdout, "password: %s\n", *password)
+	}
+	return nil
+}
+
+// resetPasswordCommand sets a new password and ends every session and
+// personal access token of the user. A password is generated and printed when
+// none is given.
+func resetPasswordCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	password := flags.String("password", "", "new password, generated when empty")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return fmt.Errorf("usage: %s", resetPasswordUsage)
+	}
+
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+	}
+
+	dbQueries := db.New(dbCon)
+	user, err := findUser(ctx, dbQueries, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	userHandler := handler.NewUserHandler(dbQueries, cfg)
+	tokenHandler := handler.NewRefreshTokenHandler(dbQueries, cfg)
+	passwordHandler := handler.NewPasswordHandler(dbQueries, cfg, *userHandler, *tokenHandler, nil)
+	err = passwordHandler.SetPassword(ctx, user.ID, handler.PasswordSetInput{Password: *password})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "reset the password of %s and ended its sessions and tokens\n", user.Username)
+	if generated {
+		fmt.Fprintf(os.Stdout, "password: %s\n", *password)
+	}
+	return nil
+}
+
+func listUsersCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	users, err := db.New(dbCon).GetAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tVERIFIED\tCREATED")
+	for _, user := range users {
+		verified := "no"
+		if user.EmailVerifiedAt.Valid {
+			verified = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", user.ID, user.Username, user.Email, verified, user.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// recomputeStatsCommand derives the deuce stats of every game from its points
+// again, after points were fixed by hand or imported.
+func recomputeStatsCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	result, err := db.New(dbCon).RecomputeStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "recomputed %d stats, %d of them new\n", result.Updated+result.Inserted, result.Inserted)
+	return nil
+}
+
+// seedCommand fills the database with demo data in one transaction. The same
+// seed gives the same data, accounts are named demo-<seed>-<n>.
+func seedCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	seedCfg := utils.SeedConfig{}
+	flags.Int64Var(&seedCfg.Seed, "seed", 1, "seed the data is generated from")
+	flags.IntVar(&seedCfg.Users, "users", 3, "accounts, each with a club of its own")
+	flags.IntVar(&seedCfg.Players, "players", 12, "players per club")
+	flags.IntVar(&seedCfg.DoublesTeams, "doubles", 4, "doubles teams per club")
+	flags.IntVar(&seedCfg.Matches, "matches", 10, "matches per club")
+	flags.IntVar(&seedCfg.Sets, "sets", 3, "sets a match is played over")
+	flags.StringVar(&seedCfg.Password, "password", "Demo1234", "password of every account")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return fmt.Errorf("usage: %s", seedUsage)
+	}
+
+	var result utils.SeedResult
+	err := db.New(dbCon).InTx(ctx, func(q db.Querier) error {
+		var err error
+		result, err = utils.Seed(ctx, q, seedCfg, func(ctx context.Context, q db.Querier, username string, email string, password string) (db.User, error) {
+			return handler.NewUserHandler(q, cfg).CreateUser(ctx, handler.CreateUserInput{Username: username, Email: email, Password: password})
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "seeded %d users, %d players, %d teams and %d matches with %d points\n",
+		result.Users, result.Players, result.Teams, result.Matches, result.Points)
+	fmt.Fprintf(os.Stdout, "log in as demo-%d-1 with the password %s\n", seedCfg.Seed, seedCfg.Password)
+	return nil
+}
+
+// findUser looks a user up by email if name has an @, by username otherwise.
+func findUser(ctx context.Context, q db.Querier, name string) (db.User, error) {
+	var user db.User
+	var err error
+	if strings.Contains(name, "@") {
+		user, err = q.GetUserByEmail(ctx, name)
+	} else {
+		user, err = q.GetUserByUsername(ctx, name)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, fmt.Errorf("there is no user %q", name)
+	}
+	return user, err
+}
+
+// generatePassword returns a random password that passes the password rule.
+func generatePassword() (string, error) {
+	for {
+		token, err := utils.GenerateOpaqueToken()
+		if err != nil {
+			return "", err
+		}
+		password := token[:20]
+		if handler.Validate(handler.PasswordSetInput{Password: password}) == nil {
+			return password, nil
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type AccountRouter struct {
+	AccountHandler handler.AccountHandler
+}
+
+type RemovedResources struct {
+	Clubs    int64 `json:"clubs"`
+	Teams    int64 `json:"teams"`
+	Players  int64 `json:"players"`
+	Matches  int64 `json:"matches"`
+	Sessions int64 `json:"sessions"`
+}
+
+type AccountDeletionResponse struct {
+	Status  string           `json:"status"`
+	User    AccountResponse  `json:"user"`
+	Removed RemovedResources `json:"removed"`
+}
+
+func newAccountRouter(h handler.AccountHandler) *AccountRouter {
+	return &AccountRouter{AccountHandler: h}
+}
+
+func newAccountDeletionResponse(status string, deletion handler.AccountDeletion) AccountDeletionResponse {
+	return AccountDeletionResponse{
+		Status: status,
+		User:   newAccountResponse(deletion.User),
+		Removed: RemovedResources{
+			Clubs:    deletion.Removed.Clubs,
+			Teams:    deletion.Removed.Teams,
+			Players:  deletion.Removed.Players,
+			Matches:  deletion.Removed.Matches,
+			Sessions: deletion.Removed.Sessions,
+		},
+	}
+}
+
+func (r *AccountRouter) GetAccount(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, UserResponse{Status: "success", Data: newAccountResponse(user)})
+}
+
+func (r *AccountRouter) UpdateAccount(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.UpdateAccountInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	updated, err := r.AccountHandler.UpdateAccount(ctx.Request().Context(), user, *input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, UserResponse{Status: "success", Data: newAccountResponse(updated)})
+}
+
+func (r *AccountRouter) ChangePassword(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.ChangePasswordInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	updated, err := r.AccountHandler.ChangePassword(ctx.Request().Context(), user, currentSessionId(ctx), *input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, UserResponse{Status: "success", Data: newAccountResponse(updated)})
+}
+
+// PreviewDeletion is the first step of deleting an account, it shows what the
+// DELETE request would remove.
+func (r *AccountRouter) PreviewDeletion(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	deletion, err := r.AccountHandler.PreviewDeletion(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newAccountDeletionResponse("pending", deletion))
+}
+
+func (r *AccountRouter) DeleteAccount(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.DeleteAccountInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	deletion, err := r.AccountHandler.DeleteAccount(ctx.Request().Context(), user, *input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newAccountDeletionResponse("success", deletion))
+}
+
+func RegisterAccountRoute(baseUrl string, e *echo.Echo, r AccountRouter, middleware Middleware) {
+	e.GET(baseUrl+"/account", r.GetAccount, middleware.AuthMiddleware)
+	e.PUT(baseUrl+"/account", r.UpdateAccount, middleware.AuthMiddleware)
+	e.PUT(baseUrl+"/account/password", r.ChangePassword, middleware.AuthMiddleware)
+	e.GET(baseUrl+"/account/deletion", r.PreviewDeletion, middleware.AuthMiddleware)
+	e.DELETE(baseUrl+"/account", r.DeleteAccount, middleware.AuthMiddleware)
+}
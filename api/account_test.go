@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+var accountHandler = handler.NewAccountHandler(TestDb, *userHandler, *tokenHandler, *verificationHandler)
+var accountRouter = newAccountRouter(*accountHandler)
+
+func TestDeleteAccount(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+	DummyTeam(t, e, user.ID)
+
+	sessions, err := tokenHandler.GetAllTokensByUserId(context.Background(), user.ID)
+	assert.NoError(t, err)
+	session := sessions[0]
+
+	// every player comes with a team of its own, besides the team of both
+	wantRemoved := RemovedResources{Clubs: 1, Teams: 3, Players: 2, Matches: 0, Sessions: 1}
+
+	t.Run("preview deletion", func(t *testing.T) {
+		err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/account/deletion", "", asUser(user, session.ID, accountRouter.PreviewDeletion), "")
+		if assert.NoError(t, err) {
+			res := new(AccountDeletionResponse)
+			err := json.Unmarshal(rec.Body.Bytes(), res)
+			assert.NoError(t, err, "Couldn't decode deletion preview")
+			assert.Equal(t, "pending", res.Status)
+			assert.Equal(t, wantRemoved, res.Removed)
+		}
+	})
+
+	t.Run("delete without confirmation", func(t *testing.T) {
+		err, _, _ := DummyRequest(t, e, http.MethodDelete, "/api/account", `{"password":"Test1234","confirm":""}`, asUser(user, session.ID, accountRouter.DeleteAccount), "")
+		assert.Equal(t, handler.ValidationError(handler.DeletionNotConfirmed), err)
+	})
+
+	t.Run("delete with wrong password", func(t *testing.T) {
+		err, _, _ := DummyRequest(t, e, http.MethodDelete, "/api/account", `{"password":"Wrong","confirm":"laurin"}`, asUser(user, session.ID, accountRouter.DeleteAccount), "")
+		assert.Equal(t, handler.UnauthorizedError(handler.CurrentPasswordWrong), err)
+	})
+
+	t.Run("delete account", func(t *testing.T) {
+		err, rec, _ := DummyRequest(t, e, http.MethodDelete, "/api/account", `{"password":"Test1234","confirm":"laurin"}`, asUser(user, session.ID, accountRouter.DeleteAccount), "")
+		if assert.NoError(t, err) {
+			res := new(AccountDeletionResponse)
+			err := json.Unmarshal(rec.Body.Bytes(), res)
+			assert.NoError(t, err, "Couldn't decode deletion")
+			assert.Equal(t, user.ID, res.User.ID)
+			assert.Equal(t, wantRemoved, res.Removed)
+
+			_, err = userHandler.GetUserById(context.Background(), user.ID)
+			assert.Error(t, err, "user should be gone")
+		}
+	})
+}
@@ -10,26 +10,55 @@ import (
 )
 
 func NewApi(ctx context.Context, resource handler.ResourceHandlers, tokenGen utils.TokenGenerator) *echo.Echo {
-	baseUrl := "/api"
-	authRouter := NewAuthRouter(resource.UserHandler, resource.TokenHandler, tokenGen, resource.AuthHandler)
-	userRouter := newUserRouter(resource.UserHandler)
-	playerRouter := newPlayerRouter(resource.PlayerHandler, resource.TeamHandler, resource.UserHandler)
-	teamRouter := newTeamRouter(resource.PlayerHandler, resource.TeamHandler, resource.UserHandler)
-
-	customMiddleware := NewMiddleware(resource.AuthHandler)
+	customMiddleware := NewMiddleware(resource.AuthHandler, resource.AuthorizationHandler, resource.PersonalTokenHandler)
 
-	e := echo.New()
+	e := newEcho()
 
-	e.Use(middleware.Logger())
+	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Skipper: func(ctx echo.Context) bool { return healthPaths[ctx.Path()] },
+	}))
 	e.Use(middleware.CORS())
 	e.Use(middleware.Recover())
 
-	RegisterAuthRoute(baseUrl, e, *authRouter)
-
-	RegisterUserRoute(baseUrl, e, *userRouter, *customMiddleware)
-	RegisterPlayersRoute(baseUrl, e, *playerRouter, *customMiddleware)
-	RegisterTeamRoute(baseUrl, e, *teamRouter, *customMiddleware)
+	registerApiRoutes("/api", e, resource, tokenGen, *customMiddleware)
 	RegisterHtmlPageRoutes(e, *customMiddleware)
 
 	return e
 }
+
+// registerApiRoutes registers every route of the api, every one of them has
+// to be described in apiOperations.
+func registerApiRoutes(baseUrl string, e *echo.Echo, resource handler.ResourceHandlers, tokenGen utils.TokenGenerator, customMiddleware Middleware) {
+	authRouter := NewAuthRouter(resource.UserHandler, resource.TokenHandler, tokenGen, resource.AuthHandler, resource.LoginThrottleHandler, resource.TwoFactorHandler)
+	userRouter := newUserRouter(resource.UserHandler)
+	playerRouter := newPlayerRouter(resource.PlayerHandler, resource.TeamHandler, resource.UserHandler)
+	teamRouter := newTeamRouter(resource.PlayerHandler, resource.TeamHandler, resource.UserHandler)
+	sessionRouter := newSessionRouter(resource.TokenHandler)
+	passwordRouter := newPasswordRouter(resource.PasswordHandler)
+	verificationRouter := newVerificationRouter(resource.VerificationHandler)
+	accountRouter := newAccountRouter(resource.AccountHandler)
+	twoFactorRouter := newTwoFactorRouter(resource.TwoFactorHandler)
+	jwksRouter := newJwksRouter(tokenGen)
+	clubRouter := newClubRouter(resource.ClubHandler)
+	personalTokenRouter := newPersonalTokenRouter(resource.PersonalTokenHandler)
+	oidcRouter := newOIDCRouter(resource.OIDCHandler, resource.AuthHandler, resource.TwoFactorHandler)
+	openAPIRouter := newOpenAPIRouter()
+	healthRouter := newHealthRouter(resource.HealthHandler)
+
+	RegisterAuthRoute(baseUrl, e, *authRouter)
+	RegisterPasswordRoute(baseUrl, e, *passwordRouter)
+	RegisterOIDCRoute(baseUrl, e, *oidcRouter)
+	RegisterJwksRoute(e, *jwksRouter)
+	RegisterOpenAPIRoute(baseUrl, e, *openAPIRouter)
+	RegisterHealthRoute(e, *healthRouter)
+
+	RegisterUserRoute(baseUrl, e, *userRouter, customMiddleware)
+	RegisterAccountRoute(baseUrl, e, *accountRouter, customMiddleware)
+	RegisterTwoFactorRoute(baseUrl, e, *twoFactorRouter, customMiddleware)
+	RegisterSessionRoute(baseUrl, e, *sessionRouter, customMiddleware)
+	RegisterPersonalTokenRoute(baseUrl, e, *personalTokenRouter, customMiddleware)
+	RegisterVerificationRoute(baseUrl, e, *verificationRouter, customMiddleware)
+	RegisterPlayersRoute(baseUrl, e, *playerRouter, customMiddleware)
+	RegisterTeamRoute(baseUrl, e, *teamRouter, customMiddleware)
+	RegisterClubRoute(baseUrl, e, *clubRouter, customMiddleware)
+}
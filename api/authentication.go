@@ -1,23 +1,46 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/Laurin-Notemann/tennis-analysis/config"
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/handler"
 	"github.com/Laurin-Notemann/tennis-analysis/utils"
 )
 
 type AuthenticationRouter struct {
-	UserHandler  handler.UserHandler
-	TokenHandler handler.RefreshTokenHandler
-	TokenGen     utils.TokenGenerator
-	AuthHandler  handler.AuthenticationHandler
+	UserHandler      handler.UserHandler
+	TokenHandler     handler.RefreshTokenHandler
+	TokenGen         utils.TokenGenerator
+	AuthHandler      handler.AuthenticationHandler
+	LoginThrottle    handler.LoginThrottleHandler
+	TwoFactorHandler handler.TwoFactorHandler
+}
+
+// AuthResponse answers a login, registration or refresh. In cookie mode the
+// tokens are empty.
+type AuthResponse struct {
+	AccessToken  string          `json:"accessToken"`
+	RefreshToken string          `json:"refreshToken"`
+	User         AccountResponse `json:"user"`
+}
+
+func newAuthResponse(payload handler.ResponsePayload) AuthResponse {
+	return AuthResponse{
+		AccessToken:  payload.AccessToken,
+		RefreshToken: payload.RefreshToken,
+		User:         newAccountResponse(payload.User),
+	}
 }
 
 func NewAuthRouter(
@@ -25,117 +48,188 @@ func NewAuthRouter(
 	t handler.RefreshTokenHandler,
 	tg utils.TokenGenerator,
 	a handler.AuthenticationHandler,
+	lt handler.LoginThrottleHandler,
+	tf handler.TwoFactorHandler,
 ) *AuthenticationRouter {
 	return &AuthenticationRouter{
-		UserHandler:  h,
-		TokenHandler: t,
-		TokenGen:     tg,
-		AuthHandler:  a,
+		UserHandler:      h,
+		TokenHandler:     t,
+		TokenGen:         tg,
+		AuthHandler:      a,
+		LoginThrottle:    lt,
+		TwoFactorHandler: tf,
 	}
 }
 
 func (r AuthenticationRouter) Register(ctx echo.Context) (err error) {
 	registerInput := new(handler.RegisterInput)
-	if err = ctx.Bind(registerInput); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	if err = bind(ctx, registerInput); err != nil {
+		return err
 	}
 
-	err = r.AuthHandler.ValidateUserInputAndGetJwt(*registerInput)
+	registerPayload, err := r.AuthHandler.CreateUserAndToken(ctx, *registerInput)
 	if err != nil {
 		return err
 	}
 
-	user, err := r.AuthHandler.CreateUserAndToken(ctx, *registerInput)
-	if err != nil {
+	return respondWithSession(ctx, http.StatusCreated, r.sessionEnv(), registerPayload)
+}
+
+func (r AuthenticationRouter) refresh(ctx echo.Context) (err error) {
+	req := new(handler.RefreshReq)
+	if err = bind(ctx, req); err != nil {
 		return err
 	}
 
-	accessToken, err := r.AuthHandler.GenAccessToken(&user)
+	// in cookie mode the browser sends the refresh token on its own, so the
+	// request has to prove it came from our scripts
+	if req.RefreshToken == "" && cookieMode(r.sessionEnv()) {
+		if err = checkCsrf(ctx); err != nil {
+			return err
+		}
+		req.RefreshToken = cookieValue(ctx, refreshTokenCookie)
+	}
+
+	payload, err := r.AuthHandler.RotateRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		return err
 	}
 
-	registerPayload := handler.ResponsePayload{
-		AccessToken: accessToken,
-		User:        user,
-	}
-	return ctx.JSON(http.StatusCreated, registerPayload)
+	return respondWithSession(ctx, http.StatusOK, r.sessionEnv(), payload)
 }
 
-func (r AuthenticationRouter) refresh(ctx echo.Context) (err error) {
-	req := new(handler.RefreshReq)
-	if err = ctx.Bind(req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+func (r AuthenticationRouter) login(ctx echo.Context) (err error) {
+	loginReq := new(handler.LoginInput)
+	if err = bind(ctx, loginReq); err != nil {
+		return err
 	}
-	accessToken := req.AccessToken
 
-	user, validToken, err := r.AuthHandler.ParseTokenGetUser(accessToken, ctx)
+	reqCtx := ctx.Request().Context()
+	ip := ctx.RealIP()
+
+	err = r.LoginThrottle.Check(reqCtx, ip, uuid.Nil)
 	if err != nil {
-		return err
+		return loginThrottled(ctx, err)
 	}
 
-	payload, err := r.AuthHandler.ValidateAccessToken(accessToken, validToken, user)
-	if !errors.Is(err, handler.AccessTokenInvalid) {
-		return ctx.JSON(http.StatusOK, payload)
+	var user db.User
+	if strings.Contains(loginReq.UsernameOrEmail, "@") {
+		user, err = r.UserHandler.GetUserByEmail(reqCtx, loginReq.UsernameOrEmail)
+	} else {
+		user, err = r.UserHandler.GetUserByUsername(reqCtx, loginReq.UsernameOrEmail)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if failErr := r.LoginThrottle.Fail(reqCtx, ip, uuid.Nil); failErr != nil {
+			return failErr
+		}
+		return handler.UnauthorizedError(handler.CredentialsInvalid)
+	} else if err != nil {
+		return handler.DatabaseError(err)
 	}
 
-	err = r.AuthHandler.ValidateRefreshToken(ctx, user)
+	err = r.LoginThrottle.Check(reqCtx, ip, user.ID)
+	if err != nil {
+		return loginThrottled(ctx, err)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginReq.Password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		if failErr := r.LoginThrottle.Fail(reqCtx, ip, user.ID); failErr != nil {
+			return failErr
+		}
+		return handler.UnauthorizedError(handler.CredentialsInvalid)
+	} else if err != nil {
+		return handler.InternalError(err)
+	}
+
+	err = r.AuthHandler.VerificationHandler.AllowsLogin(user)
 	if err != nil {
 		return err
 	}
 
-	accessToken, err = r.AuthHandler.GenAccessToken(&user)
+	twoFactor, err := r.TwoFactorHandler.IsEnabled(reqCtx, user.ID)
 	if err != nil {
 		return err
 	}
+	if twoFactor {
+		// failures only reset once the second factor was right as well
+		challenge, err := r.TwoFactorHandler.CreateChallenge(reqCtx, user, loginReq.DeviceLabel)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(http.StatusOK, challenge)
+	}
 
-	args := handler.TokenHandlerInput{
-		UserId:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+	err = r.LoginThrottle.Succeed(reqCtx, user.ID)
+	if err != nil {
+		return err
 	}
-	_, err = r.TokenHandler.UpdateTokenByUserId(ctx.Request().Context(), args)
 
-	payload = handler.ResponsePayload{
-		AccessToken: accessToken,
-		User:        user,
+	payload, err := r.AuthHandler.IssueTokens(ctx, &user, loginReq.DeviceLabel)
+	if err != nil {
+		return err
 	}
-	return ctx.JSON(http.StatusOK, payload)
+
+	return respondWithSession(ctx, http.StatusOK, r.sessionEnv(), payload)
 }
 
-func (r AuthenticationRouter) login(ctx echo.Context) (err error) {
-	loginReq := new(handler.LoginInput)
-	if err = ctx.Bind(loginReq); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+// loginTwoFactor finishes a login that was answered with a challenge by login.
+func (r AuthenticationRouter) loginTwoFactor(ctx echo.Context) (err error) {
+	input := new(handler.TwoFactorLoginInput)
+	if err = bind(ctx, input); err != nil {
+		return err
 	}
 
-	var user db.User
-	if strings.Contains(loginReq.UsernameOrEmail, "@") {
-		user, err = r.UserHandler.GetUserByEmail(ctx.Request().Context(), loginReq.UsernameOrEmail)
-	} else {
-		user, err = r.UserHandler.GetUserByUsername(ctx.Request().Context(), loginReq.UsernameOrEmail)
+	reqCtx := ctx.Request().Context()
+	ip := ctx.RealIP()
+
+	err = r.LoginThrottle.Check(reqCtx, ip, uuid.Nil)
+	if err != nil {
+		return loginThrottled(ctx, err)
+	}
+
+	challenge, err := r.TwoFactorHandler.VerifyChallenge(reqCtx, *input)
+	if err != nil {
+		if handler.KindOf(err) == handler.KindUnauthorized {
+			if failErr := r.LoginThrottle.Fail(reqCtx, ip, challenge.UserID); failErr != nil {
+				return failErr
+			}
+		}
+		return err
 	}
+
+	err = r.LoginThrottle.Succeed(reqCtx, challenge.UserID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginReq.Password))
-	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
-	} else if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	user, err := r.UserHandler.GetUserById(reqCtx, challenge.UserID)
+	if err != nil {
+		return handler.DatabaseError(err)
+	}
+
+	payload, err := r.AuthHandler.IssueTokens(ctx, &user, challenge.DeviceLabel)
+	if err != nil {
+		return err
 	}
 
-	user, err = r.AuthHandler.LoginRefreshToken(ctx, &user)
+	return respondWithSession(ctx, http.StatusOK, r.sessionEnv(), payload)
+}
 
-	accessToken, err := r.AuthHandler.GenAccessToken(&user)
+func (r AuthenticationRouter) sessionEnv() config.SessionConfig {
+	return r.AuthHandler.UserHandler.Env.SESSION
+}
 
-	payload := handler.ResponsePayload{
-		AccessToken: accessToken,
-		User:        user,
+// loginThrottled tells the client how long to wait if the login was blocked.
+func loginThrottled(ctx echo.Context, err error) error {
+	var blocked *handler.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		return err
 	}
 
-	return ctx.JSON(http.StatusOK, payload)
+	retryAfter := int(math.Ceil(blocked.RetryAfter.Seconds()))
+	ctx.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return blocked.HTTPError()
 }
 
 func RegisterAuthRoute(baseUrl string, e *echo.Echo, r AuthenticationRouter) {
@@ -143,4 +237,5 @@ func RegisterAuthRoute(baseUrl string, e *echo.Echo, r AuthenticationRouter) {
 	e.POST(baseUrl+"/register", r.Register)
 	e.POST(baseUrl+"/refresh", r.refresh)
 	e.POST(baseUrl+"/login", r.login)
+	e.POST(baseUrl+"/login/2fa", r.loginTwoFactor)
 }
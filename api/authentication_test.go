@@ -9,12 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/handler"
 	"github.com/Laurin-Notemann/tennis-analysis/utils"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type (
@@ -23,20 +23,13 @@ type (
 		user  handler.RegisterInput
 	}
 	RefreshInputTest struct {
-		name       string
-		validation ValidationType
-		error      TestError
-		durations  TokenDuration
-	}
-
-	ValidationType struct {
-		validRefresh bool
-		vaildAccess  bool
+		name         string
+		error        TestError
+		refreshToken func(t *testing.T, e *echo.Echo, registered *AuthResponse) string
 	}
 
 	TokenDuration struct {
-		access  time.Duration
-		refresh time.Duration
+		access time.Duration
 	}
 
 	LoginInputTest struct {
@@ -48,14 +41,19 @@ type (
 )
 
 var tokeGen = utils.MockTokenGenerator{CallOut: 0}
-var userHandler = handler.NewUserHandler(utils.DbQueriesTest(), Cfg)
-var tokenHandler = handler.NewRefreshTokenHandler(utils.DbQueriesTest(), Cfg, &tokeGen)
-var authHandler = handler.NewAuthenticationHandler(utils.DbQueriesTest(), *userHandler, *tokenHandler, &tokeGen)
+var userHandler = handler.NewUserHandler(TestDb, Cfg)
+var tokenHandler = handler.NewRefreshTokenHandler(TestDb, Cfg)
+var verificationHandler = handler.NewEmailVerificationHandler(TestDb, Cfg, *userHandler, TestMailer)
+var authHandler = handler.NewAuthenticationHandler(TestDb, *userHandler, *tokenHandler, &tokeGen, *verificationHandler)
+
+var loginThrottleHandler = handler.NewLoginThrottleHandler(utils.NewMemoryLoginAttemptStore(), Cfg)
+
+var twoFactorHandler = handler.NewTwoFactorHandler(TestDb, Cfg)
 
-var authRouter = NewAuthRouter(*userHandler, *tokenHandler, &tokeGen, *authHandler)
+var authRouter = NewAuthRouter(*userHandler, *tokenHandler, &tokeGen, *authHandler, *loginThrottleHandler, *twoFactorHandler)
 
 func TestRegisterRoute(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 
 	testUserInputData := []TestRegisterInput{
 		{
@@ -66,117 +64,105 @@ func TestRegisterRoute(t *testing.T) {
 			user: handler.RegisterInput{
 				Username: "laurin",
 				Email:    "laurin@test.de",
-				Password: "Test",
-				Confirm:  "Test",
+				Password: "Test1234",
+				Confirm:  "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusBadRequest,
-					Message:  "Password and confirmation do not match.",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "confirm", Message: "must match password"}}),
 			},
 			user: handler.RegisterInput{
 				Username: "lennart",
 				Email:    "lennart@test.de",
-				Password: "Test",
+				Password: "Test1234",
 				Confirm:  "TestWrong",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusConflict,
-					Message:  "pq: duplicate key value violates unique constraint \"users_username_unique\"",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ConflictError(handler.UsernameTaken),
 			},
 			user: handler.RegisterInput{
 				Username: "laurin",
 				Email:    "laurin@test.de",
-				Password: "Test",
-				Confirm:  "Test",
+				Password: "Test1234",
+				Confirm:  "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusConflict,
-					Message:  "pq: duplicate key value violates unique constraint \"users_email_unique\"",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ConflictError(handler.EmailTaken),
 			},
 			user: handler.RegisterInput{
 				Username: "laulau",
 				Email:    "laurin@test.de",
-				Password: "Test",
-				Confirm:  "Test",
+				Password: "Test1234",
+				Confirm:  "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusBadRequest,
-					Message:  "Missing inputs.",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "email", Message: "is required"}}),
 			},
 			user: handler.RegisterInput{
 				Username: "tim",
-				Password: "Test",
-				Confirm:  "Test",
+				Password: "Test1234",
+				Confirm:  "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusBadRequest,
-					Message:  "Missing inputs.",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "confirm", Message: "is required"}}),
 			},
 			user: handler.RegisterInput{
 				Username: "tim",
 				Email:    "tim@test.de",
-				Password: "Test",
+				Password: "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusBadRequest,
-					Message:  "Missing inputs.",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "password", Message: "is required"}, {Field: "confirm", Message: "must match password"}}),
 			},
 			user: handler.RegisterInput{
 				Username: "tim",
 				Email:    "tim@test.de",
-				Confirm:  "Test",
+				Confirm:  "Test1234",
 			},
 		},
 		{
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusBadRequest,
-					Message:  "Missing inputs.",
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "username", Message: "is required"}}),
 			},
 			user: handler.RegisterInput{
 				Username: "",
 				Email:    "tim@test.de",
-				Confirm:  "Test",
+				Confirm:  "Test1234",
+				Password: "Test1234",
+			},
+		},
+		{
+			error: TestError{
+				IsError: true,
+				ExpectedError: handler.ValidationError(handler.FieldErrors{
+					{Field: "username", Message: "must be at least 3 characters"},
+					{Field: "email", Message: "must be a valid email address"},
+					{Field: "password", Message: "must have at least 8 characters with a letter and a digit"},
+				}),
+			},
+			user: handler.RegisterInput{
+				Username: "ti",
+				Email:    "tim.test.de",
 				Password: "Test",
+				Confirm:  "Test",
 			},
 		},
 	}
@@ -228,7 +214,7 @@ func TestRegisterRoute(t *testing.T) {
 			} else {
 				if assert.NoError(t, err) {
 					successAddToDb++
-					userRes := new(handler.ResponsePayload)
+					userRes := new(AuthResponse)
 					err := json.Unmarshal(rec.Body.Bytes(), userRes)
 					if err != nil {
 						t.Fatalf("Couldn't decode User %v", err)
@@ -255,103 +241,160 @@ func TestRegisterRoute(t *testing.T) {
 }
 
 func TestRefreshRoute(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 
 	userInput := handler.RegisterInput{
 		Username: "laurin",
 		Email:    "laurin@test.de",
-		Password: "Test",
-		Confirm:  "Test",
+		Password: "Test1234",
+		Confirm:  "Test1234",
 	}
+
+	expiredCfg := Cfg
+	expiredCfg.SESSION.RefreshTokenLifetime = -time.Minute
+	expiredTokenHandler := handler.NewRefreshTokenHandler(TestDb, expiredCfg)
+
 	testInputData := []RefreshInputTest{
 		{
-			name: "valid tokens",
-			validation: ValidationType{
-				validRefresh: true,
-				vaildAccess:  true,
-			},
+			name: "valid refresh token",
 			error: TestError{
 				IsError:       false,
 				ExpectedError: nil,
 			},
-			durations: TokenDuration{
-				access:  5 * time.Minute,
-				refresh: 5 * time.Minute,
+			refreshToken: func(t *testing.T, e *echo.Echo, registered *AuthResponse) string {
+				return registered.RefreshToken
 			},
 		},
 		{
-			name: "valid refresh token",
-			validation: ValidationType{
-				validRefresh: true,
-				vaildAccess:  false,
-			},
+			name: "expired refresh token",
 			error: TestError{
-				IsError:       false,
-				ExpectedError: nil,
+				IsError:       true,
+				ExpectedError: handler.UnauthorizedError(handler.RefreshTokenExpired),
 			},
-			durations: TokenDuration{
-				access:  time.Duration(0),
-				refresh: 5 * time.Minute,
+			refreshToken: func(t *testing.T, e *echo.Echo, registered *AuthResponse) string {
+				_, refreshToken, err := expiredTokenHandler.CreateToken(context.Background(), handler.TokenHandlerInput{UserId: registered.User.ID})
+				assert.NoError(t, err)
+				return refreshToken
 			},
 		},
 		{
-			name: "invalid tokens",
-			validation: ValidationType{
-				validRefresh: false,
-				vaildAccess:  false,
-			},
+			name: "unknown refresh token",
 			error: TestError{
 				IsError:       true,
-				ExpectedError: echo.NewHTTPError(http.StatusUnauthorized, jwt.ErrTokenInvalidClaims.Error()+": "+jwt.ErrTokenExpired.Error()),
+				ExpectedError: handler.UnauthorizedError(handler.RefreshTokenInvalid),
 			},
-			durations: TokenDuration{
-				access:  time.Duration(0),
-				refresh: time.Duration(0),
+			refreshToken: func(t *testing.T, e *echo.Echo, registered *AuthResponse) string {
+				return "not-a-refresh-token"
 			},
 		},
 	}
 
 	for _, data := range testInputData {
 		t.Run("/api/refresh "+data.name, func(t *testing.T) {
+			registeredUser := RegisterDummyUser(t, e, userInput, &tokeGen, 5*time.Minute)
 
-			err, rec, registeredUser, req := refreshUser(t, e, userInput, data.durations.access, data.durations.refresh)
+			err, rec := refreshRequest(t, e, data.refreshToken(t, e, registeredUser))
 
-			if !data.validation.vaildAccess && !data.validation.validRefresh {
+			if data.error.IsError {
 				if assert.Error(t, err) {
 					assert.Equal(t, data.error.ExpectedError, err)
 				}
-			} else if !data.validation.vaildAccess && data.validation.validRefresh {
+			} else {
 				if assert.NoError(t, err) {
-					refreshedUser := new(handler.ResponsePayload)
+					refreshedUser := new(AuthResponse)
 
 					err := json.Unmarshal(rec.Body.Bytes(), refreshedUser)
 					if err != nil {
 						t.Fatalf("Couldn't decode User %v", err)
 					}
 
-					assert.Equal(t, registeredUser.User, refreshedUser.User)
-					assert.NotEqual(t, registeredUser.AccessToken, refreshedUser.AccessToken)
-				}
-			} else if data.validation.vaildAccess && data.validation.validRefresh {
-				if assert.NoError(t, err) {
-					refreshedUser := new(handler.ResponsePayload)
-
-					err := json.Unmarshal(rec.Body.Bytes(), refreshedUser)
-					if err != nil {
-						t.Fatalf("Couldn't decode User %v", err)
-					}
-					assert.Equal(t, registeredUser.User, refreshedUser.User)
-					assert.Equal(t, registeredUser.AccessToken, refreshedUser.AccessToken)
+					assert.Equal(t, registeredUser.User.ID, refreshedUser.User.ID)
+					assert.NotEmpty(t, refreshedUser.AccessToken)
+					assert.NotEqual(t, registeredUser.RefreshToken, refreshedUser.RefreshToken)
 				}
 			}
-			_, err = userHandler.DeleteUserById(req.Context(), registeredUser.User.ID)
+			_, err = userHandler.DeleteUserById(context.Background(), registeredUser.User.ID)
 			assert.NoError(t, err)
 		})
 	}
+
+	t.Run("/api/refresh reuse revokes the rotated session", func(t *testing.T) {
+		registeredUser := RegisterDummyUser(t, e, userInput, &tokeGen, 5*time.Minute)
+
+		err, rec := refreshRequest(t, e, registeredUser.RefreshToken)
+		assert.NoError(t, err)
+		refreshedUser := new(AuthResponse)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), refreshedUser))
+
+		err, _ = refreshRequest(t, e, registeredUser.RefreshToken)
+		assert.Equal(t, handler.UnauthorizedError(handler.RefreshTokenReused), err)
+
+		err, _ = refreshRequest(t, e, refreshedUser.RefreshToken)
+		assert.Equal(t, handler.UnauthorizedError(handler.RefreshTokenInvalid), err)
+
+		_, err = userHandler.DeleteUserById(context.Background(), registeredUser.User.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("/api/refresh concurrent refresh within the grace keeps the session", func(t *testing.T) {
+		registeredUser := RegisterDummyUser(t, e, userInput, &tokeGen, 5*time.Minute)
+
+		graceCfg := Cfg
+		graceCfg.SESSION.RefreshReuseGrace = time.Minute
+		racingAuthHandler := *authHandler
+		racingAuthHandler.TokenHandler = *handler.NewRefreshTokenHandler(racingRefreshQuerier{TestDb}, graceCfg)
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/refresh", nil), httptest.NewRecorder())
+
+		payload, err := racingAuthHandler.RotateRefreshToken(c, registeredUser.RefreshToken)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, payload.AccessToken)
+		assert.Empty(t, payload.RefreshToken)
+		assert.Equal(t, registeredUser.User.ID, payload.User.ID)
+
+		sessions, err := tokenHandler.GetAllTokensByUserId(context.Background(), registeredUser.User.ID)
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 1)
+
+		_, err = userHandler.DeleteUserById(context.Background(), registeredUser.User.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("/api/refresh concurrent reuse after the grace revokes the session", func(t *testing.T) {
+		registeredUser := RegisterDummyUser(t, e, userInput, &tokeGen, 5*time.Minute)
+
+		racingAuthHandler := *authHandler
+		racingAuthHandler.TokenHandler = *handler.NewRefreshTokenHandler(racingRefreshQuerier{TestDb}, Cfg)
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/refresh", nil), httptest.NewRecorder())
+
+		_, err := racingAuthHandler.RotateRefreshToken(c, registeredUser.RefreshToken)
+		assert.Equal(t, handler.UnauthorizedError(handler.RefreshTokenReused), err)
+
+		sessions, err := tokenHandler.GetAllTokensByUserId(context.Background(), registeredUser.User.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+
+		_, err = userHandler.DeleteUserById(context.Background(), registeredUser.User.ID)
+		assert.NoError(t, err)
+	})
+}
+
+// racingRefreshQuerier rotates every token it looks up by hash before
+// returning it, like a second refresh with the same token that won the race.
+type racingRefreshQuerier struct {
+	db.Querier
+}
+
+func (q racingRefreshQuerier) GetTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	token, err := q.Querier.GetTokenByHash(ctx, tokenHash)
+	if err != nil {
+		return token, err
+	}
+	_, _, err = tokenHandler.RotateTokenById(ctx, token.ID, tokenHash)
+	return token, err
 }
 
 func TestLoginRoute(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 
 	testDataLogin := []LoginInputTest{
 		{
@@ -362,11 +405,10 @@ func TestLoginRoute(t *testing.T) {
 			},
 			userInput: handler.LoginInput{
 				UsernameOrEmail: "laurin",
-				Password:        "Test",
+				Password:        "Test1234",
 			},
 			durations: TokenDuration{
-				access:  5 * time.Minute,
-				refresh: 5 * time.Minute,
+				access: 5 * time.Minute,
 			},
 		},
 		{
@@ -377,30 +419,24 @@ func TestLoginRoute(t *testing.T) {
 			},
 			userInput: handler.LoginInput{
 				UsernameOrEmail: "laurin@test.de",
-				Password:        "Test",
+				Password:        "Test1234",
 			},
 			durations: TokenDuration{
-				access:  5 * time.Minute,
-				refresh: 5 * time.Minute,
+				access: 5 * time.Minute,
 			},
 		},
 		{
 			name: "wrong login with missmatched pw",
 			error: TestError{
-				IsError: true,
-				ExpectedError: &echo.HTTPError{
-					Code:     http.StatusUnauthorized,
-					Message:  bcrypt.ErrMismatchedHashAndPassword.Error(),
-					Internal: error(nil),
-				},
+				IsError:       true,
+				ExpectedError: handler.UnauthorizedError(handler.CredentialsInvalid),
 			},
 			userInput: handler.LoginInput{
 				UsernameOrEmail: "laurin",
 				Password:        "TestWrong",
 			},
 			durations: TokenDuration{
-				access:  5 * time.Minute,
-				refresh: 5 * time.Minute,
+				access: 5 * time.Minute,
 			},
 		},
 	}
@@ -424,7 +460,7 @@ func TestLoginRoute(t *testing.T) {
 				}
 			} else {
 				if assert.NoError(t, err) {
-					loggedInUser := new(handler.ResponsePayload)
+					loggedInUser := new(AuthResponse)
 					err := json.Unmarshal(rec.Body.Bytes(), loggedInUser)
 					assert.NoError(t, err, "Couldn't decode User")
 
@@ -432,7 +468,10 @@ func TestLoginRoute(t *testing.T) {
 					assert.Equal(t, user.ID, loggedInUser.User.ID)
 					assert.Equal(t, user.Username, loggedInUser.User.Username)
 					assert.Equal(t, user.Email, loggedInUser.User.Email)
-					assert.NotEqual(t, user.RefreshTokenID, loggedInUser.User.RefreshTokenID)
+
+					sessions, err := tokenHandler.GetAllTokensByUserId(req.Context(), user.ID)
+					assert.NoError(t, err)
+					assert.Equal(t, 2, len(sessions), "login shouldn't end the session created on register")
 				}
 			}
 			_, err = userHandler.DeleteUserById(req.Context(), user.ID)
@@ -442,27 +481,59 @@ func TestLoginRoute(t *testing.T) {
 
 }
 
-func refreshUser(
-	t *testing.T,
-	e *echo.Echo,
-	userInput handler.RegisterInput,
-	durAcc time.Duration,
-	durRef time.Duration,
-) (error, *httptest.ResponseRecorder, *handler.ResponsePayload, *http.Request) {
-	user := RegisterDummyUser(t, e, userInput, &tokeGen, durAcc, durRef)
+func TestLoginThrottle(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+
+	throttleCfg := Cfg
+	throttleCfg.LOGIN = config.LoginConfig{
+		FreeAttempts:    1,
+		IpFreeAttempts:  100,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Hour,
+		LockoutDuration: time.Hour,
+		AttemptWindow:   time.Hour,
+	}
+	throttle := handler.NewLoginThrottleHandler(utils.NewMemoryLoginAttemptStore(), throttleCfg)
+	router := NewAuthRouter(*userHandler, *tokenHandler, &tokeGen, *authHandler, *throttle, *twoFactorHandler)
 
-	encodeRefreshReq, err := json.Marshal(handler.RefreshReq{AccessToken: user.AccessToken})
-	assert.NoError(t, err)
+	login := func(password string) (error, *httptest.ResponseRecorder) {
+		encodeLoginReq, err := json.Marshal(handler.LoginInput{UsernameOrEmail: "laurin", Password: password})
+		assert.NoError(t, err)
 
-	if durAcc == time.Duration(0) {
-		tokeGen.ExpiryDateAccess = 5 * time.Minute
+		req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(string(encodeLoginReq)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		err = router.login(e.NewContext(req, rec))
+		return err, rec
 	}
 
+	err, _ := login("TestWrong")
+	assert.Equal(t, handler.KindUnauthorized, handler.KindOf(err))
+	err, _ = login("TestWrong")
+	assert.Equal(t, handler.KindUnauthorized, handler.KindOf(err))
+
+	t.Run("blocked even with the right password", func(t *testing.T) {
+		err, rec := login("Test1234")
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusTooManyRequests, err.(*echo.HTTPError).Code)
+			assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		}
+	})
+
+	_, err = userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
+
+func refreshRequest(t *testing.T, e *echo.Echo, refreshToken string) (error, *httptest.ResponseRecorder) {
+	encodeRefreshReq, err := json.Marshal(handler.RefreshReq{RefreshToken: refreshToken})
+	assert.NoError(t, err)
+
 	req := httptest.NewRequest(http.MethodPost, "/api/refresh", strings.NewReader(string(encodeRefreshReq)))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	err = authRouter.refresh(c)
 
-	return err, rec, user, req
+	return err, rec
 }
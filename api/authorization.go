@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+// activeClubHeader lets a client pick the club a request works in. Without it
+// requests act on the default club of the user.
+const activeClubHeader = "X-Club-Id"
+
+// clubResolver finds the club a request touches.
+type clubResolver func(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error)
+
+// Authorize lets the request through if the authenticated user has permission
+// in the club resolve points to and remembers that club for the router. It has
+// to run after AuthMiddleware.
+func (m *Middleware) Authorize(permission handler.Permission, resolve clubResolver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			user, err := currentUser(ctx)
+			if err != nil {
+				ctx.Error(err)
+				return nil
+			}
+
+			clubId, err := resolve(ctx, m.Authz)
+			if err != nil {
+				ctx.Error(err)
+				return nil
+			}
+
+			err = m.Authz.Authorize(ctx.Request().Context(), user, clubId, permission)
+			if err != nil {
+				ctx.Error(err)
+				return nil
+			}
+
+			ctx.Set(clubContextKey, clubId)
+			return next(ctx)
+		}
+	}
+}
+
+// currentClubId returns the club the request named, or the one Authorize
+// resolved for it when it named none.
+func currentClubId(ctx echo.Context, requested uuid.UUID) uuid.UUID {
+	if requested != uuid.Nil {
+		return requested
+	}
+	clubId, _ := ctx.Get(clubContextKey).(uuid.UUID)
+	return clubId
+}
+
+// activeClub is the club picked in the X-Club-Id header or the default club of
+// the user.
+func activeClub(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+	if header := ctx.Request().Header.Get(activeClubHeader); header != "" {
+		clubId, err := uuid.Parse(header)
+		if err != nil {
+			return uuid.Nil, handler.ValidationError(err)
+		}
+		return clubId, nil
+	}
+
+	user, err := currentUser(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return authz.DefaultClubId(ctx.Request().Context(), user)
+}
+
+func uuidParam(ctx echo.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(ctx.Param(name))
+	if err != nil {
+		return uuid.Nil, handler.ValidationError(err)
+	}
+	return id, nil
+}
+
+// clubFromParam reads the club straight from the path.
+func clubFromParam(name string) clubResolver {
+	return func(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+		return uuidParam(ctx, name)
+	}
+}
+
+func playerClubFromParam(name string) clubResolver {
+	return func(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+		playerId, err := uuidParam(ctx, name)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return authz.ClubOfPlayer(ctx.Request().Context(), playerId)
+	}
+}
+
+func teamClubFromParam(name string) clubResolver {
+	return func(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+		teamId, err := uuidParam(ctx, name)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return authz.ClubOfTeam(ctx.Request().Context(), teamId)
+	}
+}
+
+// clubBody holds the fields of player and team requests that say which club
+// they belong to. Field names match case-insensitively, so both the camelCase
+// requests and the raw db params decode into it.
+type clubBody struct {
+	ID        uuid.UUID  `json:"id"`
+	ClubId    uuid.UUID  `json:"clubId"`
+	PlayerOne *uuid.UUID `json:"playerOne"`
+	PlayerTwo *uuid.UUID `json:"playerTwo"`
+}
+
+// peekClubBody decodes the request body and puts it back for the router.
+func peekClubBody(ctx echo.Context) (clubBody, error) {
+	body := clubBody{}
+	raw, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return body, handler.ValidationError(err)
+	}
+	ctx.Request().Body = io.NopCloser(bytes.NewReader(raw))
+
+	if len(raw) == 0 {
+		return body, nil
+	}
+	if err = json.Unmarshal(raw, &body); err != nil {
+		return body, handler.ValidationError(err)
+	}
+	return body, nil
+}
+
+// clubFromBody is for creating players, which name their club in clubId or
+// go into the active club.
+func clubFromBody(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+	body, err := peekClubBody(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if body.ClubId == uuid.Nil {
+		return activeClub(ctx, authz)
+	}
+	return body.ClubId, nil
+}
+
+func playerClubFromBody(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+	body, err := peekClubBody(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return authz.ClubOfPlayer(ctx.Request().Context(), body.ID)
+}
+
+// teamClubFromBody resolves the club of a new (clubId or active club) or
+// existing (id) team and makes sure its players come from that same club.
+func teamClubFromBody(ctx echo.Context, authz handler.AuthorizationHandler) (uuid.UUID, error) {
+	body, err := peekClubBody(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	reqCtx := ctx.Request().Context()
+
+	clubId := body.ClubId
+	if body.ID != uuid.Nil {
+		clubId, err = authz.ClubOfTeam(reqCtx, body.ID)
+	} else if clubId == uuid.Nil {
+		clubId, err = activeClub(ctx, authz)
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	for _, playerId := range []*uuid.UUID{body.PlayerOne, body.PlayerTwo} {
+		if playerId == nil || *playerId == uuid.Nil {
+			continue
+		}
+		playerClub, err := authz.ClubOfPlayer(reqCtx, *playerId)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if playerClub != clubId {
+			return uuid.Nil, handler.ValidationError(handler.PlayerNotInClub)
+		}
+	}
+
+	return clubId, nil
+}
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type ClubRouter struct {
+	ClubHandler handler.ClubHandler
+}
+
+type ClubResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role"`
+}
+
+type ClubMemberResponse struct {
+	UserID    uuid.UUID `json:"userId"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ClubInvitationResponse never carries the token, only a new invitation comes
+// with its link.
+type ClubInvitationResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	ExpiryDate time.Time `json:"expiryDate"`
+	Link       string    `json:"link,omitempty"`
+}
+
+func newClubRouter(c handler.ClubHandler) *ClubRouter {
+	return &ClubRouter{ClubHandler: c}
+}
+
+func newClubMemberResponse(member db.ClubMember) ClubMemberResponse {
+	return ClubMemberResponse{
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+}
+
+func newClubInvitationResponse(invitation db.ClubInvitation) ClubInvitationResponse {
+	return ClubInvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		ExpiryDate: invitation.ExpiryDate,
+	}
+}
+
+// GetClubs lists the clubs the user is a member of.
+func (r *ClubRouter) GetClubs(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	clubs, err := r.ClubHandler.GetClubs(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	response := []ClubResponse{}
+	for _, club := range clubs {
+		response = append(response, ClubResponse{ID: club.ID, Name: club.Name, Role: club.Role})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (r *ClubRouter) CreateClub(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.CreateClubInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	club, err := r.ClubHandler.CreateClub(ctx.Request().Context(), user, *input)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, ClubResponse{ID: club.ID, Name: club.Name, Role: handler.RoleOwner})
+}
+
+func (r *ClubRouter) RenameClub(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.CreateClubInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	club, err := r.ClubHandler.RenameClub(ctx.Request().Context(), clubId, *input)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, ClubResponse{ID: club.ID, Name: club.Name, Role: handler.RoleOwner})
+}
+
+func (r *ClubRouter) DeleteClub(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	club, err := r.ClubHandler.DeleteClub(ctx.Request().Context(), clubId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, ClubResponse{ID: club.ID, Name: club.Name})
+}
+
+func (r *ClubRouter) GetMembers(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	members, err := r.ClubHandler.GetMembers(ctx.Request().Context(), clubId)
+	if err != nil {
+		return err
+	}
+
+	response := []ClubMemberResponse{}
+	for _, member := range members {
+		response = append(response, ClubMemberResponse{
+			UserID:    member.UserID,
+			Username:  member.Username,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (r *ClubRouter) ChangeRole(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+	userId, err := uuidParam(ctx, "userId")
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.ChangeRoleInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	member, err := r.ClubHandler.ChangeRole(ctx.Request().Context(), clubId, userId, *input)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, newClubMemberResponse(member))
+}
+
+func (r *ClubRouter) RemoveMember(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+	userId, err := uuidParam(ctx, "userId")
+	if err != nil {
+		return err
+	}
+
+	member, err := r.ClubHandler.RemoveMember(ctx.Request().Context(), clubId, userId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, newClubMemberResponse(member))
+}
+
+// LeaveClub removes the user from a club they are a member of.
+func (r *ClubRouter) LeaveClub(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	member, err := r.ClubHandler.RemoveMember(ctx.Request().Context(), clubId, user.ID)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, newClubMemberResponse(member))
+}
+
+func (r *ClubRouter) GetInvitations(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	invitations, err := r.ClubHandler.GetInvitations(ctx.Request().Context(), clubId)
+	if err != nil {
+		return err
+	}
+
+	response := []ClubInvitationResponse{}
+	for _, invitation := range invitations {
+		response = append(response, newClubInvitationResponse(invitation))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (r *ClubRouter) Invite(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.InviteInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	invite, err := r.ClubHandler.Invite(ctx.Request().Context(), user, clubId, *input)
+	if err != nil {
+		return err
+	}
+
+	response := newClubInvitationResponse(invite.Invitation)
+	response.Link = invite.Link
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (r *ClubRouter) RevokeInvitation(ctx echo.Context) (err error) {
+	clubId, err := uuidParam(ctx, "clubId")
+	if err != nil {
+		return err
+	}
+	invitationId, err := uuidParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	invitation, err := r.ClubHandler.RevokeInvitation(ctx.Request().Context(), clubId, invitationId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, newClubInvitationResponse(invitation))
+}
+
+func (r *ClubRouter) AcceptInvitation(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.AcceptInvitationInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	member, err := r.ClubHandler.AcceptInvitation(ctx.Request().Context(), user, *input)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, ClubResponse{ID: member.ClubID, Role: member.Role})
+}
+
+func RegisterClubRoute(baseUrl string, e *echo.Echo, r ClubRouter, middleware Middleware) {
+	club := clubFromParam("clubId")
+	member := middleware.Authorize(handler.PermissionViewRoster, club)
+	manageMembers := middleware.Authorize(handler.PermissionManageMembers, club)
+	manageClub := middleware.Authorize(handler.PermissionManageClub, club)
+
+	e.GET(baseUrl+"/clubs", r.GetClubs, middleware.AllowToken(handler.ScopeClubsRead))
+	e.POST(baseUrl+"/clubs", r.CreateClub, middleware.AuthMiddleware, middleware.VerifiedMiddleware)
+	e.PUT(baseUrl+"/clubs/:clubId", r.RenameClub, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageClub)
+	e.DELETE(baseUrl+"/clubs/:clubId", r.DeleteClub, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageClub)
+
+	e.GET(baseUrl+"/clubs/:clubId/members", r.GetMembers, middleware.AllowToken(handler.ScopeClubsRead), member)
+	e.PUT(baseUrl+"/clubs/:clubId/members/:userId", r.ChangeRole, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageMembers)
+	e.DELETE(baseUrl+"/clubs/:clubId/members/:userId", r.RemoveMember, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageMembers)
+	e.DELETE(baseUrl+"/clubs/:clubId/membership", r.LeaveClub, middleware.AuthMiddleware, member)
+
+	e.GET(baseUrl+"/clubs/:clubId/invitations", r.GetInvitations, middleware.AuthMiddleware, manageMembers)
+	e.POST(baseUrl+"/clubs/:clubId/invitations", r.Invite, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageMembers)
+	e.DELETE(baseUrl+"/clubs/:clubId/invitations/:id", r.RevokeInvitation, middleware.AuthMiddleware, middleware.VerifiedMiddleware,
+		manageMembers)
+	e.POST(baseUrl+"/club-invitations/accept", r.AcceptInvitation, middleware.AuthMiddleware)
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+var authorizationHandler = handler.NewAuthorizationHandler(TestDb, *userHandler)
+var clubHandler = handler.NewClubHandler(TestDb, Cfg, TestMailer)
+
+type TestClubRequest struct {
+	name   string
+	as     *AuthResponse
+	method string
+	url    string
+	body   interface{}
+	status int
+}
+
+func registerNamedUser(t *testing.T, e *echo.Echo, name string) *AuthResponse {
+	return RegisterDummyUser(t, e, handler.RegisterInput{
+		Username: name,
+		Email:    name + "@test.de",
+		Password: "Test1234",
+		Confirm:  "Test1234",
+	}, &utils.MockTokenGenerator{}, 5*time.Minute)
+}
+
+func clubApi() *echo.Echo {
+	e := newEcho()
+	middleware := NewMiddleware(*authHandler, *authorizationHandler, *personalTokenHandler)
+	RegisterPlayersRoute("/api", e, *playRouter, *middleware)
+	RegisterTeamRoute("/api", e, *teamRouter, *middleware)
+	RegisterClubRoute("/api", e, *newClubRouter(*clubHandler), *middleware)
+	return e
+}
+
+func clubRequest(t *testing.T, e *echo.Echo, data TestClubRequest) *httptest.ResponseRecorder {
+	var body string
+	if data.body != nil {
+		encoded, err := json.Marshal(data.body)
+		assert.NoError(t, err)
+		body = string(encoded)
+	}
+	req := httptest.NewRequest(data.method, data.url, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Authorization", "Bearer "+data.as.AccessToken)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// inviteToken creates a link invitation and returns the token from its link.
+func inviteToken(t *testing.T, e *echo.Echo, as *AuthResponse, clubId uuid.UUID, role string) string {
+	rec := clubRequest(t, e, TestClubRequest{
+		as:     as,
+		method: http.MethodPost,
+		url:    "/api/clubs/" + clubId.String() + "/invitations",
+		body:   handler.InviteInput{Role: role},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	invitation := ClubInvitationResponse{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &invitation))
+	link, err := url.Parse(invitation.Link)
+	assert.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestClubRoles(t *testing.T) {
+	e := clubApi()
+	owner := registerNamedUser(t, e, "club-owner")
+	coach := registerNamedUser(t, e, "club-coach")
+	viewer := registerNamedUser(t, e, "club-viewer")
+	stranger := registerNamedUser(t, e, "club-stranger")
+	for _, user := range []*AuthResponse{owner, coach, viewer, stranger} {
+		defer userHandler.DeleteUserById(context.Background(), user.User.ID)
+	}
+
+	clubId := owner.User.ID
+	clubUrl := "/api/clubs/" + clubId.String()
+	player := DummyPlayer(t, e, clubId)
+	strangerPlayer := addNewPlayer(t, e, db.CreateNewTeamWithOnePlayerParams{
+		FirstName: "Stranger",
+		LastName:  "Player",
+		ClubID:    stranger.User.ID,
+	})
+	newPlayer := CreatePlayerRequest{FirstName: "Coach", LastName: "Added", ClubId: clubId}
+
+	coachToken := inviteToken(t, e, owner, clubId, handler.RoleCoach)
+	viewerToken := inviteToken(t, e, owner, clubId, handler.RoleViewer)
+
+	steps := []TestClubRequest{
+		{"stranger can't see the club", stranger, http.MethodGet, "/api/players/" + clubId.String(), nil, http.StatusForbidden},
+		{"stranger can't invite", stranger, http.MethodPost, clubUrl + "/invitations",
+			handler.InviteInput{Role: handler.RoleCoach}, http.StatusForbidden},
+		{"owner can't invite with invalid roles", owner, http.MethodPost, clubUrl + "/invitations",
+			handler.InviteInput{Role: "admin"}, http.StatusBadRequest},
+		{"invalid tokens are rejected", coach, http.MethodPost, "/api/club-invitations/accept",
+			handler.AcceptInvitationInput{Token: "nope"}, http.StatusBadRequest},
+		{"coach accepts", coach, http.MethodPost, "/api/club-invitations/accept",
+			handler.AcceptInvitationInput{Token: coachToken}, http.StatusOK},
+		{"invitations are used once", stranger, http.MethodPost, "/api/club-invitations/accept",
+			handler.AcceptInvitationInput{Token: coachToken}, http.StatusBadRequest},
+		{"viewer accepts", viewer, http.MethodPost, "/api/club-invitations/accept",
+			handler.AcceptInvitationInput{Token: viewerToken}, http.StatusOK},
+		{"viewer sees the club", viewer, http.MethodGet, "/api/players/" + clubId.String(), nil, http.StatusOK},
+		{"viewer sees the members", viewer, http.MethodGet, clubUrl + "/members", nil, http.StatusOK},
+		{"viewer can't add players", viewer, http.MethodPost, "/api/players", newPlayer, http.StatusForbidden},
+		{"coach adds players", coach, http.MethodPost, "/api/players", newPlayer, http.StatusCreated},
+		{"coach edits players", coach, http.MethodPut, "/api/players",
+			db.UpdatePlayerByIdParams{ID: player.ID, FirstName: "Edited", LastName: "By Coach"}, http.StatusOK},
+		{"coach can't use players of other clubs", coach, http.MethodPost, "/api/teams",
+			db.CreateTeamWithTwoPlayersParams{Name: "Mixed", ClubID: clubId, PlayerOne: player.ID, PlayerTwo: &strangerPlayer.ID}, http.StatusBadRequest},
+		{"coach can't delete players", coach, http.MethodDelete, "/api/players/" + player.ID.String(), nil, http.StatusForbidden},
+		{"coach can't invite", coach, http.MethodPost, clubUrl + "/invitations",
+			handler.InviteInput{Role: handler.RoleCoach}, http.StatusForbidden},
+		{"coach can't rename the club", coach, http.MethodPut, clubUrl,
+			handler.CreateClubInput{Name: "Taken over"}, http.StatusForbidden},
+		{"stranger can't edit players", stranger, http.MethodPut, "/api/players",
+			db.UpdatePlayerByIdParams{ID: player.ID, FirstName: "Hacked", LastName: "Player"}, http.StatusForbidden},
+		{"the last owner can't step down", owner, http.MethodPut, clubUrl + "/members/" + owner.User.ID.String(),
+			handler.ChangeRoleInput{Role: handler.RoleCoach}, http.StatusBadRequest},
+		{"owner renames the club", owner, http.MethodPut, clubUrl,
+			handler.CreateClubInput{Name: "Club Owner TC"}, http.StatusOK},
+		{"owner removes coach", owner, http.MethodDelete, clubUrl + "/members/" + coach.User.ID.String(), nil, http.StatusOK},
+		{"removed coach can't see the club", coach, http.MethodGet, "/api/players/" + clubId.String(), nil, http.StatusForbidden},
+		{"owner deletes players", owner, http.MethodDelete, "/api/players/" + player.ID.String(), nil, http.StatusOK},
+	}
+
+	for _, data := range steps {
+		t.Run(data.name, func(t *testing.T) {
+			rec := clubRequest(t, e, data)
+			assert.Equal(t, data.status, rec.Code, rec.Body.String())
+		})
+	}
+
+	t.Run("members are listed", func(t *testing.T) {
+		rec := clubRequest(t, e, TestClubRequest{as: owner, method: http.MethodGet, url: clubUrl + "/members"})
+		members := []ClubMemberResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &members))
+		if assert.Len(t, members, 2) {
+			assert.Equal(t, "club-owner", members[0].Username)
+			assert.Equal(t, handler.RoleOwner, members[0].Role)
+			assert.Equal(t, "club-viewer", members[1].Username)
+			assert.Equal(t, handler.RoleViewer, members[1].Role)
+		}
+	})
+
+	t.Run("clubs are listed for the member", func(t *testing.T) {
+		rec := clubRequest(t, e, TestClubRequest{as: viewer, method: http.MethodGet, url: "/api/clubs"})
+		clubs := []ClubResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &clubs))
+		assert.Equal(t, []ClubResponse{
+			{ID: viewer.User.ID, Name: "club-viewer", Role: handler.RoleOwner},
+			{ID: clubId, Name: "Club Owner TC", Role: handler.RoleViewer},
+		}, clubs)
+	})
+
+	t.Run("viewer leaves the club", func(t *testing.T) {
+		rec := clubRequest(t, e, TestClubRequest{as: viewer, method: http.MethodDelete, url: clubUrl + "/membership"})
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		rec = clubRequest(t, e, TestClubRequest{as: viewer, method: http.MethodGet, url: "/api/players/" + clubId.String()})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("unknown players are not found", func(t *testing.T) {
+		rec := clubRequest(t, e, TestClubRequest{as: owner, method: http.MethodDelete, url: "/api/players/" + uuid.NewString()})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
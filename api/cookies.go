@@ -0,0 +1,111 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+const (
+	accessTokenCookie  = "access_token"
+	refreshTokenCookie = "refresh_token"
+	csrfTokenCookie    = "csrf_token"
+	csrfTokenHeader    = "X-CSRF-Token"
+)
+
+func cookieMode(env config.SessionConfig) bool {
+	return env.Mode == config.SessionModeCookie
+}
+
+func newSessionCookie(env config.SessionConfig, name string, value string, maxAge time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   env.CookieDomain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   env.CookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// setSessionCookies stores the tokens of payload in httpOnly cookies together
+// with a fresh CSRF token. The CSRF cookie is readable by the scripts, which
+// send it back in the X-CSRF-Token header (double submit). A payload without a
+// refresh token, from a concurrent refresh, only renews the access token and
+// leaves the cookies the other refresh set.
+func setSessionCookies(ctx echo.Context, env config.SessionConfig, payload handler.ResponsePayload) error {
+	ctx.SetCookie(newSessionCookie(env, accessTokenCookie, payload.AccessToken, utils.OneDay))
+	if payload.RefreshToken == "" {
+		return nil
+	}
+
+	csrfToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return handler.InternalError(err)
+	}
+
+	csrfCookie := newSessionCookie(env, csrfTokenCookie, csrfToken, env.RefreshTokenLifetime)
+	csrfCookie.HttpOnly = false
+
+	ctx.SetCookie(newSessionCookie(env, refreshTokenCookie, payload.RefreshToken, env.RefreshTokenLifetime))
+	ctx.SetCookie(csrfCookie)
+	return nil
+}
+
+func clearSessionCookies(ctx echo.Context, env config.SessionConfig) {
+	for _, name := range []string{accessTokenCookie, refreshTokenCookie, csrfTokenCookie} {
+		cookie := newSessionCookie(env, name, "", 0)
+		cookie.MaxAge = -1
+		ctx.SetCookie(cookie)
+	}
+}
+
+// respondWithSession answers a login, registration or refresh. In cookie mode
+// the tokens go into cookies and never reach the scripts.
+func respondWithSession(ctx echo.Context, status int, env config.SessionConfig, payload handler.ResponsePayload) error {
+	response := newAuthResponse(payload)
+	if !cookieMode(env) || payload.AccessToken == "" {
+		return ctx.JSON(status, response)
+	}
+
+	err := setSessionCookies(ctx, env, payload)
+	if err != nil {
+		return err
+	}
+
+	response.AccessToken = ""
+	response.RefreshToken = ""
+	return ctx.JSON(status, response)
+}
+
+// cookieValue returns the value of the named cookie or "" if it wasn't sent.
+func cookieValue(ctx echo.Context, name string) string {
+	cookie, err := ctx.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// checkCsrf makes sure a request authenticated by cookie also carries the CSRF
+// token, which another site can't read and therefore can't send along.
+func checkCsrf(ctx echo.Context) error {
+	cookie := cookieValue(ctx, csrfTokenCookie)
+	header := ctx.Request().Header.Get(csrfTokenHeader)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		return handler.ForbiddenError(CsrfTokenInvalid)
+	}
+	return nil
+}
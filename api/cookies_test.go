@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type TestCookieAuthInput struct {
+	name   string
+	req    *http.Request
+	status int
+}
+
+func cookieModeConfig() config.Config {
+	cfg := Cfg
+	cfg.SESSION.Mode = config.SessionModeCookie
+	cfg.SESSION.CookieSecure = false
+	cfg.SESSION.RefreshReuseGrace = time.Minute
+	return cfg
+}
+
+var cookieCfg = cookieModeConfig()
+var cookieUserHandler = handler.NewUserHandler(TestDb, cookieCfg)
+var cookieTokenHandler = handler.NewRefreshTokenHandler(TestDb, cookieCfg)
+var cookieAuthHandler = handler.NewAuthenticationHandler(TestDb, *cookieUserHandler, *cookieTokenHandler, &tokeGen, *verificationHandler)
+var cookieAuthRouter = NewAuthRouter(*cookieUserHandler, *cookieTokenHandler, &tokeGen, *cookieAuthHandler, *loginThrottleHandler, *twoFactorHandler)
+var cookieMiddleware = NewMiddleware(*cookieAuthHandler, *authorizationHandler, *personalTokenHandler)
+
+func responseCookies(rec *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range rec.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+// registerWithCookies registers a user in cookie mode and returns the cookies
+// the browser would keep.
+func registerWithCookies(t *testing.T, e *echo.Echo) (AuthResponse, map[string]*http.Cookie) {
+	input, _ := json.Marshal(handler.RegisterInput{
+		Username: "cookie-user",
+		Email:    "cookie-user@test.de",
+		Password: "Test1234",
+		Confirm:  "Test1234",
+	})
+	err, rec, _ := DummyRequest(t, e, http.MethodPost, "/api/register", string(input), cookieAuthRouter.Register, "")
+	assert.NoError(t, err)
+
+	payload := AuthResponse{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload))
+	return payload, responseCookies(rec)
+}
+
+func cookieRequest(method string, target string, cookies map[string]*http.Cookie, csrf bool) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for _, cookie := range cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	if csrf {
+		req.Header.Set(csrfTokenHeader, cookies[csrfTokenCookie].Value)
+	}
+	return req
+}
+
+func TestCookieSessionIssued(t *testing.T) {
+	e := newEcho()
+	payload, cookies := registerWithCookies(t, e)
+	defer cookieUserHandler.DeleteUserById(context.Background(), payload.User.ID)
+
+	assert.Empty(t, payload.AccessToken, "tokens must not reach the scripts")
+	assert.Empty(t, payload.RefreshToken, "tokens must not reach the scripts")
+	assert.Equal(t, "cookie-user", payload.User.Username)
+
+	for _, name := range []string{accessTokenCookie, refreshTokenCookie} {
+		if assert.Contains(t, cookies, name) {
+			assert.NotEmpty(t, cookies[name].Value)
+			assert.True(t, cookies[name].HttpOnly, name+" has to be httpOnly")
+			assert.Equal(t, http.SameSiteLaxMode, cookies[name].SameSite)
+		}
+	}
+	if assert.Contains(t, cookies, csrfTokenCookie) {
+		assert.False(t, cookies[csrfTokenCookie].HttpOnly, "the scripts need to read the csrf token")
+	}
+}
+
+func TestCookieAuthMiddlewareCsrf(t *testing.T) {
+	e := newEcho()
+	payload, cookies := registerWithCookies(t, e)
+	defer cookieUserHandler.DeleteUserById(context.Background(), payload.User.ID)
+
+	ok := func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }
+	e.GET("/protected", ok, cookieMiddleware.AuthMiddleware)
+	e.POST("/protected", ok, cookieMiddleware.AuthMiddleware)
+
+	testInput := []TestCookieAuthInput{
+		{"GET with cookie", cookieRequest(http.MethodGet, "/protected", cookies, false), http.StatusOK},
+		{"POST without csrf token", cookieRequest(http.MethodPost, "/protected", cookies, false), http.StatusForbidden},
+		{"POST with csrf token", cookieRequest(http.MethodPost, "/protected", cookies, true), http.StatusOK},
+		{"POST without cookies", cookieRequest(http.MethodPost, "/protected", nil, false), http.StatusUnauthorized},
+	}
+
+	wrongCsrf := cookieRequest(http.MethodPost, "/protected", cookies, false)
+	wrongCsrf.Header.Set(csrfTokenHeader, "wrong")
+	testInput = append(testInput, TestCookieAuthInput{"POST with wrong csrf token", wrongCsrf, http.StatusForbidden})
+
+	bearer := httptest.NewRequest(http.MethodPost, "/protected", nil)
+	bearer.Header.Set("Authorization", "Bearer "+cookies[accessTokenCookie].Value)
+	testInput = append(testInput, TestCookieAuthInput{"POST with bearer token needs no csrf token", bearer, http.StatusOK})
+
+	junkBearer := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	junkBearer.Header.Set("Authorization", "Bearer not-a-jwt")
+	testInput = append(testInput, TestCookieAuthInput{"GET with malformed bearer token", junkBearer, http.StatusUnauthorized})
+
+	for _, data := range testInput {
+		t.Run(data.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, data.req)
+			assert.Equal(t, data.status, rec.Code)
+		})
+	}
+}
+
+func TestCookieRefresh(t *testing.T) {
+	e := newEcho()
+	payload, cookies := registerWithCookies(t, e)
+	defer cookieUserHandler.DeleteUserById(context.Background(), payload.User.ID)
+	e.POST("/api/refresh", cookieAuthRouter.refresh)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, cookieRequest(http.MethodPost, "/api/refresh", cookies, false))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, cookieRequest(http.MethodPost, "/api/refresh", cookies, true))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	refreshed := responseCookies(rec)
+	if assert.Contains(t, refreshed, refreshTokenCookie) {
+		assert.NotEqual(t, cookies[refreshTokenCookie].Value, refreshed[refreshTokenCookie].Value)
+	}
+	assert.NotContains(t, rec.Body.String(), refreshed[refreshTokenCookie].Value)
+}
+
+func TestPageMiddleware(t *testing.T) {
+	e := newEcho()
+	payload, cookies := registerWithCookies(t, e)
+	defer cookieUserHandler.DeleteUserById(context.Background(), payload.User.ID)
+
+	page := func(ctx echo.Context) error {
+		user, err := currentUser(ctx)
+		if err != nil {
+			return err
+		}
+		return ctx.String(http.StatusOK, user.Username)
+	}
+	e.GET("/players", page, cookieMiddleware.PageMiddleware)
+
+	t.Run("redirects without session", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, cookieRequest(http.MethodGet, "/players", nil, false))
+		assert.Equal(t, http.StatusSeeOther, rec.Code)
+		assert.Equal(t, "/login", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("redirects with malformed access cookie", func(t *testing.T) {
+		junk := map[string]*http.Cookie{accessTokenCookie: {Name: accessTokenCookie, Value: "not-a-jwt"}}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, cookieRequest(http.MethodGet, "/players", junk, false))
+		assert.Equal(t, http.StatusSeeOther, rec.Code)
+		assert.Equal(t, "/login", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("renders with access cookie", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, cookieRequest(http.MethodGet, "/players", cookies, false))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "cookie-user", rec.Body.String())
+	})
+
+	t.Run("renews an expired access cookie", func(t *testing.T) {
+		onlyRefresh := map[string]*http.Cookie{refreshTokenCookie: cookies[refreshTokenCookie]}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, cookieRequest(http.MethodGet, "/players", onlyRefresh, false))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, responseCookies(rec), accessTokenCookie)
+	})
+
+	t.Run("renews two pages loaded with the same refresh cookie", func(t *testing.T) {
+		_, refreshToken, err := cookieTokenHandler.CreateToken(context.Background(), handler.TokenHandlerInput{UserId: payload.User.ID})
+		assert.NoError(t, err)
+		onlyRefresh := map[string]*http.Cookie{refreshTokenCookie: {Name: refreshTokenCookie, Value: refreshToken}}
+
+		first := httptest.NewRecorder()
+		e.ServeHTTP(first, cookieRequest(http.MethodGet, "/players", onlyRefresh, false))
+		second := httptest.NewRecorder()
+		e.ServeHTTP(second, cookieRequest(http.MethodGet, "/players", onlyRefresh, false))
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Contains(t, responseCookies(first), refreshTokenCookie)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Contains(t, responseCookies(second), accessTokenCookie)
+		assert.NotContains(t, responseCookies(second), refreshTokenCookie)
+	})
+
+	t.Run("leaves pages open in bearer mode", func(t *testing.T) {
+		bearerMiddleware := NewMiddleware(*authHandler, *authorizationHandler, *personalTokenHandler)
+		e := newEcho()
+		e.GET("/players", func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }, bearerMiddleware.PageMiddleware)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/players", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestLogoutClearsCookies(t *testing.T) {
+	e := newEcho()
+	payload, cookies := registerWithCookies(t, e)
+	defer cookieUserHandler.DeleteUserById(context.Background(), payload.User.ID)
+	RegisterSessionRoute("/api", e, *newSessionRouter(*cookieTokenHandler), *cookieMiddleware)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, cookieRequest(http.MethodPost, "/api/logout", cookies, true))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	cleared := responseCookies(rec)
+	for _, name := range []string{accessTokenCookie, refreshTokenCookie, csrfTokenCookie} {
+		if assert.Contains(t, cleared, name) {
+			assert.Equal(t, -1, cleared[name].MaxAge)
+		}
+	}
+
+	sessions, err := cookieTokenHandler.GetAllTokensByUserId(context.Background(), payload.User.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+var (
+	CsrfTokenInvalid    = errors.New("CSRF token is missing or invalid")
+	NoAuthenticatedUser = errors.New("No authenticated user")
+	InternalFailure     = errors.New("Something went wrong on our side")
+)
+
+// ErrorResponse is the body of every error. Code is meant for clients to
+// switch on and stays stable, Message is meant for people. Fields lists every
+// invalid field when the input didn't pass validation.
+type ErrorResponse struct {
+	Status  string              `json:"status"`
+	Code    string              `json:"code"`
+	Message string              `json:"message"`
+	Fields  handler.FieldErrors `json:"fields,omitempty"`
+}
+
+// newEcho creates an echo instance that answers errors with an ErrorResponse
+// and validates inputs.
+func newEcho() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = errorHandler
+	e.Validator = requestValidator{}
+	return e
+}
+
+// errorHandler answers every error a route returned. Internal errors are only
+// logged, the client gets a generic message so no SQL leaks.
+func errorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	status, body := errorResponse(err)
+	if status >= http.StatusInternalServerError {
+		ctx.Logger().Error(err)
+	}
+
+	if ctx.Request().Method == http.MethodHead {
+		err = ctx.NoContent(status)
+	} else {
+		err = ctx.JSON(status, body)
+	}
+	if err != nil {
+		ctx.Logger().Error(err)
+	}
+}
+
+func errorResponse(err error) (int, ErrorResponse) {
+	var domainErr *handler.DomainError
+	if !errors.As(err, &domainErr) {
+		// errors of echo itself, like unknown routes, and the login throttle
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			message := fmt.Sprint(httpErr.Message)
+			if httpErr.Code >= http.StatusInternalServerError {
+				message = InternalFailure.Error()
+			}
+			return httpErr.Code, ErrorResponse{Status: "error", Code: statusCode(httpErr.Code), Message: message}
+		}
+		errors.As(handler.DatabaseError(err), &domainErr)
+	}
+
+	body := ErrorResponse{Status: "error", Code: string(domainErr.Kind), Message: domainErr.Err.Error()}
+	if domainErr.Kind == handler.KindInternal {
+		body.Message = InternalFailure.Error()
+	}
+	if domainErr.Kind == handler.KindValidation {
+		errors.As(domainErr.Err, &body.Fields)
+	}
+	return domainErr.Kind.Status(), body
+}
+
+// statusCode is the code of an error that only has a status.
+func statusCode(status int) string {
+	for _, kind := range []handler.ErrorKind{
+		handler.KindValidation,
+		handler.KindUnauthorized,
+		handler.KindForbidden,
+		handler.KindNotFound,
+		handler.KindConflict,
+		handler.KindInternal,
+	} {
+		if kind.Status() == status {
+			return string(kind)
+		}
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
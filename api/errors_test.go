@@ -0,0 +1,51 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		body   ErrorResponse
+	}{
+		{"domain errors", handler.ForbiddenError(handler.ScopeNotGranted), http.StatusForbidden,
+			ErrorResponse{Status: "error", Code: "forbidden", Message: handler.ScopeNotGranted.Error()}},
+		{"missing rows", sql.ErrNoRows, http.StatusNotFound,
+			ErrorResponse{Status: "error", Code: "not_found", Message: handler.RecordNotFound.Error()}},
+		{"unique violations", &pq.Error{Code: "23505", Constraint: "users_username_unique"}, http.StatusConflict,
+			ErrorResponse{Status: "error", Code: "conflict", Message: handler.UsernameTaken.Error()}},
+		{"internal errors don't leak", handler.InternalError(errors.New("pq: relation \"users\" does not exist")), http.StatusInternalServerError,
+			ErrorResponse{Status: "error", Code: "internal_error", Message: InternalFailure.Error()}},
+		{"echo errors", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed,
+			ErrorResponse{Status: "error", Code: "method_not_allowed", Message: "Method Not Allowed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEcho()
+			e.GET("/", func(ctx echo.Context) error { return tt.err })
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.status, rec.Code)
+
+			body := ErrorResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
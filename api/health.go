@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type HealthRouter struct {
+	HealthHandler handler.HealthHandler
+}
+
+func newHealthRouter(h handler.HealthHandler) *HealthRouter {
+	return &HealthRouter{HealthHandler: h}
+}
+
+// healthPaths are the probes, they are left out of the request log.
+var healthPaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// Live answers as long as the process serves requests at all.
+func (r *HealthRouter) Live(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, MessageResponse{Status: handler.HealthOK, Message: "alive"})
+}
+
+// Ready answers 503 while the database is unreachable, its schema isn't the
+// one of this binary or the server is shutting down.
+func (r *HealthRouter) Ready(ctx echo.Context) error {
+	readiness := r.HealthHandler.Ready(ctx.Request().Context())
+	if !readiness.Ready() {
+		return ctx.JSON(http.StatusServiceUnavailable, readiness)
+	}
+	return ctx.JSON(http.StatusOK, readiness)
+}
+
+func RegisterHealthRoute(e *echo.Echo, r HealthRouter) {
+	e.GET("/healthz", r.Live)
+	e.GET("/readyz", r.Ready)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type fakeDB struct{ err error }
+
+func (f *fakeDB) PingContext(ctx context.Context) error { return f.err }
+
+type fakeSchema struct{ pending int }
+
+func (f *fakeSchema) Check(ctx context.Context) (int, error) { return f.pending, nil }
+
+func TestHealthRoutes(t *testing.T) {
+	database := &fakeDB{}
+	schema := &fakeSchema{}
+	healthHandler := handler.NewHealthHandler(database, schema)
+	e := newEcho()
+	RegisterHealthRoute(e, *newHealthRouter(*healthHandler))
+
+	probe := func(path string) (int, handler.Readiness) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var readiness handler.Readiness
+		json.Unmarshal(rec.Body.Bytes(), &readiness)
+		return rec.Code, readiness
+	}
+
+	code, _ := probe("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	code, readiness := probe("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, handler.HealthOK, readiness.Checks["database"])
+
+	database.err = errors.New("connection refused")
+	code, readiness = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, handler.HealthUnavailable, readiness.Checks["database"])
+	database.err = nil
+
+	schema.pending = 1
+	code, _ = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	schema.pending = 0
+
+	healthHandler.Drain()
+	code, readiness = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, handler.HealthDraining, readiness.Status)
+	code, _ = probe("/healthz")
+	assert.Equal(t, http.StatusOK, code, "a draining server is still alive")
+}
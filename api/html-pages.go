@@ -24,50 +24,70 @@ func RegisterHtmlPageRoutes(e *echo.Echo, middleware Middleware) {
 		templates: template.Must(template.ParseGlob("public/views/*.html")),
 	}
 	e.Renderer = t
-  e.Static("/static", "public/static")
+	e.Static("/static", "public/static")
 	e.GET("/", indexRoute)
 	e.GET("/login", loginRoute)
 	e.GET("/register", registerRoute)
-	e.GET("/create-player", createPlayerRoute)
-	e.GET("/players", playersRoute)
-  e.GET("/edit-player/:id", editPlayerRoute)
-	e.GET("/create-team", createTeamRoute)
-	e.GET("/teams", teamsRoute)
-  e.GET("/edit-team/:id", editTeamRoute)
+	e.GET("/reset-password", resetPasswordRoute)
+	e.GET("/verify-email", verifyEmailRoute)
+	e.GET("/docs", docsRoute)
+	e.GET("/join-club", joinClubRoute, middleware.PageMiddleware)
+	e.GET("/create-player", createPlayerRoute, middleware.PageMiddleware)
+	e.GET("/players", playersRoute, middleware.PageMiddleware)
+	e.GET("/edit-player/:id", editPlayerRoute, middleware.PageMiddleware)
+	e.GET("/create-team", createTeamRoute, middleware.PageMiddleware)
+	e.GET("/teams", teamsRoute, middleware.PageMiddleware)
+	e.GET("/edit-team/:id", editTeamRoute, middleware.PageMiddleware)
 }
 
 func indexRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "index.html", "")
+	return c.Render(http.StatusOK, "index.html", "")
 }
 
 func loginRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "login.html", "")
+	return c.Render(http.StatusOK, "login.html", "")
 }
 
 func registerRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "register.html", "")
+	return c.Render(http.StatusOK, "register.html", "")
+}
+
+func resetPasswordRoute(c echo.Context) error {
+	return c.Render(http.StatusOK, "reset-password.html", "")
+}
+
+func verifyEmailRoute(c echo.Context) error {
+	return c.Render(http.StatusOK, "verify-email.html", "")
+}
+
+func docsRoute(c echo.Context) error {
+	return c.Render(http.StatusOK, "docs.html", "")
+}
+
+func joinClubRoute(c echo.Context) error {
+	return c.Render(http.StatusOK, "join-club.html", "")
 }
 
 func playersRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "players.html", "")
+	return c.Render(http.StatusOK, "players.html", "")
 }
 
 func createPlayerRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "create-player.html", "")
+	return c.Render(http.StatusOK, "create-player.html", "")
 }
 
 func editPlayerRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "edit-player.html", "")
+	return c.Render(http.StatusOK, "edit-player.html", "")
 }
 
 func teamsRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "teams.html", "")
+	return c.Render(http.StatusOK, "teams.html", "")
 }
 
 func createTeamRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "create-team.html", "")
+	return c.Render(http.StatusOK, "create-team.html", "")
 }
 
 func editTeamRoute(c echo.Context) error {
-  return c.Render(http.StatusOK, "edit-team.html", "")
+	return c.Render(http.StatusOK, "edit-team.html", "")
 }
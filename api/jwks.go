@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+type JwksRouter struct {
+	TokenGen utils.TokenGenerator
+}
+
+func newJwksRouter(tg utils.TokenGenerator) *JwksRouter {
+	return &JwksRouter{TokenGen: tg}
+}
+
+// GetJwks publishes the public keys access tokens are verified with, so other
+// services can check them without knowing any secret.
+func (r *JwksRouter) GetJwks(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=300")
+	return ctx.JSON(http.StatusOK, r.TokenGen.JWKS())
+}
+
+func RegisterJwksRoute(e *echo.Echo, r JwksRouter) {
+	e.GET("/.well-known/jwks.json", r.GetJwks)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestGetJwks(t *testing.T) {
+	e := newEcho()
+	key, _, err := utils.GenerateSigningKey("2024-01", "EdDSA")
+	assert.NoError(t, err)
+	keys, err := utils.NewKeySet(key)
+	assert.NoError(t, err)
+	RegisterJwksRoute(e, *newJwksRouter(&utils.ProdTokenGenerator{Keys: keys}))
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "public, max-age=300", rec.Header().Get(echo.HeaderCacheControl))
+
+	jwks := new(utils.JsonWebKeySet)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), jwks))
+	if assert.Len(t, jwks.Keys, 1) {
+		assert.Equal(t, "2024-01", jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+	}
+}
@@ -5,44 +5,201 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	userContextKey    = "user"
+	sessionContextKey = "sessionId"
+	clubContextKey    = "clubId"
+	tokenContextKey   = "personalToken"
+	scopeContextKey   = "scope"
+)
+
 type Middleware struct {
 	AuthHandler handler.AuthenticationHandler
+	Authz       handler.AuthorizationHandler
+	Tokens      handler.PersonalTokenHandler
 }
 
 func NewMiddleware(
 	a handler.AuthenticationHandler,
+	authz handler.AuthorizationHandler,
+	tokens handler.PersonalTokenHandler,
 ) *Middleware {
 	return &Middleware{
 		AuthHandler: a,
+		Authz:       authz,
+		Tokens:      tokens,
 	}
 }
 
 func (m *Middleware) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
+		env := m.AuthHandler.UserHandler.Env.SESSION
+
+		accessToken := ""
 		headers := ctx.Request().Header.Get("Authorization")
-		if headers == "" {
-			ctx.Error(echo.NewHTTPError(http.StatusUnauthorized, "No bearer of token"))
+		if headers != "" {
+			token := strings.Split(headers, " ")
+			accessToken = token[1]
+		} else if cookieMode(env) {
+			accessToken = cookieValue(ctx, accessTokenCookie)
+			if accessToken != "" && !safeMethod(ctx.Request().Method) {
+				if err := checkCsrf(ctx); err != nil {
+					ctx.Error(err)
+					return nil
+				}
+			}
+		}
+		if accessToken == "" {
+			ctx.Error(handler.UnauthorizedError(handler.AccessTokenMissing))
+			return nil
+		}
+
+		if handler.IsPersonalToken(accessToken) {
+			user, token, err := m.authenticatePersonalToken(ctx, accessToken)
+			if err != nil {
+				ctx.Error(err)
+				return nil
+			}
+
+			ctx.Set(userContextKey, user)
+			ctx.Set(tokenContextKey, token)
+			return next(ctx)
+		}
+
+		user, sessionId, err := m.authenticate(ctx, accessToken)
+		if err != nil {
+			ctx.Error(err)
 			return nil
 		}
 
-		token := strings.Split(headers, " ")
+		ctx.Set(userContextKey, user)
+		ctx.Set(sessionContextKey, sessionId)
+
+		return next(ctx)
+	}
+}
+
+// PageMiddleware protects the html pages in cookie mode. Visitors without a
+// session are sent to the login, an expired access token is renewed with the
+// refresh cookie on the way. In bearer mode the scripts handle this themselves.
+func (m *Middleware) PageMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		env := m.AuthHandler.UserHandler.Env.SESSION
+		if !cookieMode(env) {
+			return next(ctx)
+		}
+
+		if accessToken := cookieValue(ctx, accessTokenCookie); accessToken != "" {
+			user, sessionId, err := m.authenticate(ctx, accessToken)
+			if err == nil {
+				ctx.Set(userContextKey, user)
+				ctx.Set(sessionContextKey, sessionId)
+				return next(ctx)
+			}
+		}
+
+		payload, err := m.AuthHandler.RotateRefreshToken(ctx, cookieValue(ctx, refreshTokenCookie))
+		if err != nil {
+			clearSessionCookies(ctx, env)
+			return ctx.Redirect(http.StatusSeeOther, "/login")
+		}
+
+		err = setSessionCookies(ctx, env, payload)
+		if err != nil {
+			return err
+		}
+		ctx.Set(userContextKey, payload.User)
+
+		return next(ctx)
+	}
+}
+
+// AllowToken is AuthMiddleware for routes that personal access tokens with the
+// given scope may use as well. Everywhere else only logins are accepted.
+func (m *Middleware) AllowToken(scope handler.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		auth := m.AuthMiddleware(next)
+		return func(ctx echo.Context) error {
+			ctx.Set(scopeContextKey, scope)
+			return auth(ctx)
+		}
+	}
+}
+
+// authenticatePersonalToken checks a personal access token against the scope
+// the route allows tokens with.
+func (m *Middleware) authenticatePersonalToken(ctx echo.Context, secret string) (db.User, db.PersonalAccessToken, error) {
+	user, token, err := m.Tokens.Authenticate(ctx.Request().Context(), secret)
+	if err != nil {
+		return db.User{}, db.PersonalAccessToken{}, err
+	}
+
+	scope, ok := ctx.Get(scopeContextKey).(handler.Scope)
+	if !ok || !handler.HasScope(token, scope) {
+		return db.User{}, db.PersonalAccessToken{}, handler.ForbiddenError(handler.ScopeNotGranted)
+	}
+	return user, token, nil
+}
+
+// authenticate checks the access token and the session it belongs to.
+func (m *Middleware) authenticate(ctx echo.Context, accessToken string) (db.User, uuid.UUID, error) {
+	user, validToken, err := m.AuthHandler.ParseTokenGetUser(accessToken, ctx)
+	if err != nil {
+		return db.User{}, uuid.Nil, handler.UnauthorizedError(err)
+	}
 
-		user, validToken, err := m.AuthHandler.ParseTokenGetUser(token[1], ctx)
+	_, err = m.AuthHandler.ValidateAccessToken(accessToken, validToken, user)
+	if errors.Is(err, handler.AccessTokenInvalid) {
+		return db.User{}, uuid.Nil, handler.UnauthorizedError(err)
+	}
+
+	sessionId := validToken.Claims.(*utils.CustomTokenClaim).SessionID
+	_, err = m.AuthHandler.ValidateSession(ctx.Request().Context(), user, sessionId)
+	if err != nil {
+		return db.User{}, uuid.Nil, err
+	}
+
+	return user, sessionId, nil
+}
+
+// VerifiedMiddleware keeps users without a verified email from changing data
+// if the verification policy asks for it. It has to run after AuthMiddleware.
+func (m *Middleware) VerifiedMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		user, err := currentUser(ctx)
 		if err != nil {
-			ctx.Error(echo.NewHTTPError(http.StatusUnauthorized, err.Error()))
+			ctx.Error(err)
 			return nil
 		}
 
-		_, err = m.AuthHandler.ValidateAccessToken(token[1], validToken, user)
-		if errors.Is(err, handler.AccessTokenInvalid) {
-			ctx.Error(echo.NewHTTPError(http.StatusUnauthorized, err.Error()))
+		err = m.AuthHandler.VerificationHandler.AllowsWrite(user)
+		if err != nil {
+			ctx.Error(err)
 			return nil
 		}
 
 		return next(ctx)
 	}
 }
+
+// currentUser returns the user that was authenticated by AuthMiddleware.
+func currentUser(ctx echo.Context) (db.User, error) {
+	user, ok := ctx.Get(userContextKey).(db.User)
+	if !ok {
+		return db.User{}, handler.UnauthorizedError(NoAuthenticatedUser)
+	}
+	return user, nil
+}
+
+// currentSessionId returns the session the request was authenticated with.
+func currentSessionId(ctx echo.Context) uuid.UUID {
+	sessionId, _ := ctx.Get(sessionContextKey).(uuid.UUID)
+	return sessionId
+}
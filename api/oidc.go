@@ -0,0 +1,131 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+const (
+	oidcStateCookie   = "oidc_state"
+	oidcStateLifetime = 10 * time.Minute
+)
+
+type OIDCRouter struct {
+	OIDCHandler      handler.OIDCHandler
+	AuthHandler      handler.AuthenticationHandler
+	TwoFactorHandler handler.TwoFactorHandler
+}
+
+type OIDCConfigResponse struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"providerName"`
+}
+
+func newOIDCRouter(o handler.OIDCHandler, a handler.AuthenticationHandler, tf handler.TwoFactorHandler) *OIDCRouter {
+	return &OIDCRouter{
+		OIDCHandler:      o,
+		AuthHandler:      a,
+		TwoFactorHandler: tf,
+	}
+}
+
+func (r *OIDCRouter) sessionEnv() config.SessionConfig {
+	return r.AuthHandler.UserHandler.Env.SESSION
+}
+
+// Config tells the login page whether to show the single sign-on button.
+func (r *OIDCRouter) Config(ctx echo.Context) (err error) {
+	return ctx.JSON(http.StatusOK, OIDCConfigResponse{
+		Enabled:      r.OIDCHandler.Enabled(),
+		ProviderName: r.OIDCHandler.Env.OIDC.ProviderName,
+	})
+}
+
+// Login sends the browser to the provider. State and nonce are kept in a
+// short lived cookie, so only the browser that started the login can finish it.
+func (r *OIDCRouter) Login(ctx echo.Context) (err error) {
+	login, err := r.OIDCHandler.Start()
+	if err != nil {
+		return err
+	}
+
+	ctx.SetCookie(newSessionCookie(r.sessionEnv(), oidcStateCookie, login.State+"."+login.Nonce, oidcStateLifetime))
+	return ctx.Redirect(http.StatusFound, login.Url)
+}
+
+// Callback is where the provider sends the browser back to. It ends on the
+// login page, which picks up the session or the two-factor challenge from the
+// fragment, since fragments never reach a server log.
+func (r *OIDCRouter) Callback(ctx echo.Context) (err error) {
+	stored := cookieValue(ctx, oidcStateCookie)
+	expired := newSessionCookie(r.sessionEnv(), oidcStateCookie, "", 0)
+	expired.MaxAge = -1
+	ctx.SetCookie(expired)
+
+	if providerErr := ctx.QueryParam("error"); providerErr != "" {
+		return r.fail(ctx, handler.UnauthorizedError(fmt.Errorf("%w: %s", handler.OIDCLoginFailed, providerErr)))
+	}
+
+	state, nonce, found := strings.Cut(stored, ".")
+	if !found || subtle.ConstantTimeCompare([]byte(state), []byte(ctx.QueryParam("state"))) != 1 {
+		return r.fail(ctx, handler.UnauthorizedError(handler.OIDCStateInvalid))
+	}
+
+	reqCtx := ctx.Request().Context()
+	user, err := r.OIDCHandler.Finish(reqCtx, ctx.QueryParam("code"), nonce)
+	if err != nil {
+		return r.fail(ctx, err)
+	}
+
+	twoFactor, err := r.TwoFactorHandler.IsEnabled(reqCtx, user.ID)
+	if err != nil {
+		return r.fail(ctx, err)
+	}
+	if twoFactor {
+		challenge, err := r.TwoFactorHandler.CreateChallenge(reqCtx, user, "")
+		if err != nil {
+			return r.fail(ctx, err)
+		}
+		return redirectToLogin(ctx, url.Values{"challengeToken": {challenge.ChallengeToken}})
+	}
+
+	payload, err := r.AuthHandler.IssueTokens(ctx, &user, "")
+	if err != nil {
+		return r.fail(ctx, err)
+	}
+
+	if cookieMode(r.sessionEnv()) {
+		err = setSessionCookies(ctx, r.sessionEnv(), payload)
+		if err != nil {
+			return r.fail(ctx, err)
+		}
+		return redirectToLogin(ctx, url.Values{"sso": {"success"}})
+	}
+	return redirectToLogin(ctx, url.Values{"sso": {"success"}, "refreshToken": {payload.RefreshToken}})
+}
+
+// fail sends the browser back to the login page with the error, a JSON error
+// would leave the user on a blank page after the provider redirect.
+func (r *OIDCRouter) fail(ctx echo.Context, err error) error {
+	_, body := errorResponse(err)
+	return redirectToLogin(ctx, url.Values{"error": {body.Message}})
+}
+
+func redirectToLogin(ctx echo.Context, fragment url.Values) error {
+	return ctx.Redirect(http.StatusFound, "/login#"+fragment.Encode())
+}
+
+func RegisterOIDCRoute(baseUrl string, e *echo.Echo, r OIDCRouter) {
+	e.GET(baseUrl+"/oidc/config", r.Config)
+	e.GET(baseUrl+"/oidc/login", r.Login)
+	e.GET(baseUrl+"/oidc/callback", r.Callback)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+// oidcLogin runs a login through the stub and returns the response of the
+// callback. The state cookie is only sent if keepState is set.
+func oidcLogin(t *testing.T, e *echo.Echo, keepState bool) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil))
+	assert.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(rec.Header().Get("Location"))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	callback, err := url.Parse(res.Header.Get("Location"))
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	if keepState {
+		for _, cookie := range rec.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	return rec
+}
+
+func loginFragment(t *testing.T, rec *httptest.ResponseRecorder) url.Values {
+	location := rec.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, "/login#"), location)
+	fragment, err := url.ParseQuery(strings.TrimPrefix(location, "/login#"))
+	assert.NoError(t, err)
+	return fragment
+}
+
+func TestOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	stub, err := utils.NewStubOIDCServer("tennis", "secret")
+	assert.NoError(t, err)
+	defer stub.Close()
+
+	provider, err := utils.NewOIDCClient(ctx, stub.Config(), nil)
+	assert.NoError(t, err)
+
+	oidcHandler := handler.NewOIDCHandler(TestDb, Cfg, *userHandler, provider)
+	e := newEcho()
+	RegisterOIDCRoute("/api", e, *newOIDCRouter(*oidcHandler, *authHandler, *twoFactorHandler))
+
+	stub.LoginAs(utils.OIDCIdentity{Subject: "oidc-api", Email: "oidc-api@test.de", EmailVerified: true})
+
+	t.Run("logins without the state cookie are rejected", func(t *testing.T) {
+		fragment := loginFragment(t, oidcLogin(t, e, false))
+		assert.Equal(t, handler.OIDCStateInvalid.Error(), fragment.Get("error"))
+	})
+
+	t.Run("new identities are logged in", func(t *testing.T) {
+		fragment := loginFragment(t, oidcLogin(t, e, true))
+		assert.Equal(t, "success", fragment.Get("sso"))
+		assert.NotEmpty(t, fragment.Get("refreshToken"))
+
+		user, err := userHandler.GetUserByEmail(ctx, "oidc-api@test.de")
+		assert.NoError(t, err)
+		defer userHandler.DeleteUserById(ctx, user.ID)
+		assert.Equal(t, "oidc-api", user.Username)
+		assert.True(t, user.EmailVerifiedAt.Valid)
+
+		payload, err := authHandler.RotateRefreshToken(newEcho().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder()), fragment.Get("refreshToken"))
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, payload.User.ID)
+	})
+
+	t.Run("disabled without a provider", func(t *testing.T) {
+		disabled := newEcho()
+		RegisterOIDCRoute("/api", disabled, *newOIDCRouter(*handler.NewOIDCHandler(TestDb, Cfg, *userHandler, nil), *authHandler, *twoFactorHandler))
+
+		rec := httptest.NewRecorder()
+		disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/oidc/login", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
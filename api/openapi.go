@@ -0,0 +1,454 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+type jsonObject map[string]interface{}
+
+// apiOperation describes a route for the OpenAPI document. Request and
+// Response are values of the bodies, their schemas come from the json and
+// validate tags of the types. Params is a value of the query input, its fields
+// with a query tag are described like the bodies.
+type apiOperation struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Auth     bool
+	Scope    handler.Scope
+	Query    []string
+	Params   interface{}
+	Request  interface{}
+	Status   int
+	Response interface{}
+}
+
+// oneOf is a response that comes in different shapes.
+type oneOf []interface{}
+
+// apiOperations are all routes of the api. TestOpenAPIDocumentsEveryRoute
+// fails when a route is registered without an entry here.
+var apiOperations = []apiOperation{
+	{Method: http.MethodPost, Path: "/api/register", Tag: "Authentication", Summary: "Register a new user and start a session",
+		Request: handler.RegisterInput{}, Status: http.StatusCreated, Response: AuthResponse{}},
+	{Method: http.MethodPost, Path: "/api/refresh", Tag: "Authentication", Summary: "Trade a refresh token for new tokens",
+		Request: handler.RefreshReq{}, Status: http.StatusOK, Response: AuthResponse{}},
+	{Method: http.MethodPost, Path: "/api/login", Tag: "Authentication", Summary: "Log in, or get a challenge when two-factor authentication is on",
+		Request: handler.LoginInput{}, Status: http.StatusOK, Response: oneOf{AuthResponse{}, handler.TwoFactorChallenge{}}},
+	{Method: http.MethodPost, Path: "/api/login/2fa", Tag: "Authentication", Summary: "Finish a login with the second factor",
+		Request: handler.TwoFactorLoginInput{}, Status: http.StatusOK, Response: AuthResponse{}},
+	{Method: http.MethodPost, Path: "/api/password-reset", Tag: "Authentication", Summary: "Mail a password reset link",
+		Request: handler.PasswordResetRequestInput{}, Status: http.StatusAccepted, Response: MessageResponse{}},
+	{Method: http.MethodPost, Path: "/api/password-reset/confirm", Tag: "Authentication", Summary: "Set a new password with a reset token",
+		Request: handler.PasswordResetConfirmInput{}, Status: http.StatusOK, Response: MessageResponse{}},
+	{Method: http.MethodGet, Path: "/api/oidc/config", Tag: "Authentication", Summary: "Show whether single sign-on is available",
+		Status: http.StatusOK, Response: OIDCConfigResponse{}},
+	{Method: http.MethodGet, Path: "/api/oidc/login", Tag: "Authentication", Summary: "Redirect to the single sign-on provider",
+		Status: http.StatusFound},
+	{Method: http.MethodGet, Path: "/api/oidc/callback", Tag: "Authentication", Summary: "Finish single sign-on and redirect to the login page",
+		Query: []string{"code", "state", "error"}, Status: http.StatusFound},
+	{Method: http.MethodGet, Path: "/.well-known/jwks.json", Tag: "Authentication", Summary: "List the public keys that sign access tokens",
+		Status: http.StatusOK, Response: utils.JsonWebKeySet{}},
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "Documentation", Summary: "This document",
+		Status: http.StatusOK, Response: jsonObject{}},
+	{Method: http.MethodGet, Path: "/healthz", Tag: "Health", Summary: "Check that the server is alive",
+		Status: http.StatusOK, Response: MessageResponse{}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "Health", Summary: "Check that the server can take requests, 503 while it can't",
+		Status: http.StatusOK, Response: handler.Readiness{}},
+
+	{Method: http.MethodGet, Path: "/api/users/:id", Tag: "Users", Summary: "Get a user", Auth: true,
+		Status: http.StatusOK, Response: UserResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/account", Tag: "Account", Summary: "Get the logged in user", Auth: true,
+		Status: http.StatusOK, Response: UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/account", Tag: "Account", Summary: "Change username and email", Auth: true,
+		Request: handler.UpdateAccountInput{}, Status: http.StatusOK, Response: UserResponse{}},
+	{Method: http.MethodPut, Path: "/api/account/password", Tag: "Account", Summary: "Change the password, end the other sessions and revoke the personal access tokens", Auth: true,
+		Request: handler.ChangePasswordInput{}, Status: http.StatusOK, Response: UserResponse{}},
+	{Method: http.MethodGet, Path: "/api/account/deletion", Tag: "Account", Summary: "Show what deleting the account removes", Auth: true,
+		Status: http.StatusOK, Response: AccountDeletionResponse{}},
+	{Method: http.MethodDelete, Path: "/api/account", Tag: "Account", Summary: "Delete the account", Auth: true,
+		Request: handler.DeleteAccountInput{}, Status: http.StatusOK, Response: AccountDeletionResponse{}},
+	{Method: http.MethodPost, Path: "/api/account/2fa", Tag: "Account", Summary: "Start enrolling in two-factor authentication", Auth: true,
+		Status: http.StatusCreated, Response: handler.TwoFactorEnrollment{}},
+	{Method: http.MethodPost, Path: "/api/account/2fa/confirm", Tag: "Account", Summary: "Turn on two-factor authentication", Auth: true,
+		Request: handler.TwoFactorCodeInput{}, Status: http.StatusOK, Response: RecoveryCodesResponse{}},
+	{Method: http.MethodDelete, Path: "/api/account/2fa", Tag: "Account", Summary: "Turn off two-factor authentication", Auth: true,
+		Request: handler.TwoFactorCodeInput{}, Status: http.StatusOK, Response: MessageResponse{}},
+	{Method: http.MethodPost, Path: "/api/verify-email", Tag: "Account", Summary: "Verify an email with the token from the mail",
+		Request: handler.VerifyEmailInput{}, Status: http.StatusOK, Response: UserResponse{}},
+	{Method: http.MethodPost, Path: "/api/verify-email/resend", Tag: "Account", Summary: "Send the verification mail again", Auth: true,
+		Status: http.StatusAccepted, Response: MessageResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/sessions", Tag: "Sessions", Summary: "List the sessions of the user", Auth: true,
+		Status: http.StatusOK, Response: []SessionResponse{}},
+	{Method: http.MethodDelete, Path: "/api/sessions/:id", Tag: "Sessions", Summary: "End a session", Auth: true,
+		Status: http.StatusOK, Response: SessionResponse{}},
+	{Method: http.MethodPost, Path: "/api/logout", Tag: "Sessions", Summary: "End the current session", Auth: true,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/api/tokens", Tag: "Personal access tokens", Summary: "List the personal access tokens", Auth: true,
+		Status: http.StatusOK, Response: []PersonalTokenResponse{}},
+	{Method: http.MethodPost, Path: "/api/tokens", Tag: "Personal access tokens", Summary: "Create a personal access token, its secret is only shown once", Auth: true,
+		Request: handler.CreatePersonalTokenInput{}, Status: http.StatusCreated, Response: PersonalTokenResponse{}},
+	{Method: http.MethodDelete, Path: "/api/tokens/:id", Tag: "Personal access tokens", Summary: "Revoke a personal access token", Auth: true,
+		Status: http.StatusOK, Response: PersonalTokenResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/clubs", Tag: "Clubs", Summary: "List the clubs of the user", Auth: true, Scope: handler.ScopeClubsRead,
+		Status: http.StatusOK, Response: []ClubResponse{}},
+	{Method: http.MethodPost, Path: "/api/clubs", Tag: "Clubs", Summary: "Create a club", Auth: true,
+		Request: handler.CreateClubInput{}, Status: http.StatusCreated, Response: ClubResponse{}},
+	{Method: http.MethodPut, Path: "/api/clubs/:clubId", Tag: "Clubs", Summary: "Rename a club", Auth: true,
+		Request: handler.CreateClubInput{}, Status: http.StatusOK, Response: ClubResponse{}},
+	{Method: http.MethodDelete, Path: "/api/clubs/:clubId", Tag: "Clubs", Summary: "Delete a club with its roster", Auth: true,
+		Status: http.StatusOK, Response: ClubResponse{}},
+	{Method: http.MethodGet, Path: "/api/clubs/:clubId/members", Tag: "Clubs", Summary: "List the members of a club", Auth: true, Scope: handler.ScopeClubsRead,
+		Status: http.StatusOK, Response: []ClubMemberResponse{}},
+	{Method: http.MethodPut, Path: "/api/clubs/:clubId/members/:userId", Tag: "Clubs", Summary: "Change the role of a member", Auth: true,
+		Request: handler.ChangeRoleInput{}, Status: http.StatusOK, Response: ClubMemberResponse{}},
+	{Method: http.MethodDelete, Path: "/api/clubs/:clubId/members/:userId", Tag: "Clubs", Summary: "Remove a member", Auth: true,
+		Status: http.StatusOK, Response: ClubMemberResponse{}},
+	{Method: http.MethodDelete, Path: "/api/clubs/:clubId/membership", Tag: "Clubs", Summary: "Leave a club", Auth: true,
+		Status: http.StatusOK, Response: ClubMemberResponse{}},
+	{Method: http.MethodGet, Path: "/api/clubs/:clubId/invitations", Tag: "Clubs", Summary: "List the open invitations of a club", Auth: true,
+		Status: http.StatusOK, Response: []ClubInvitationResponse{}},
+	{Method: http.MethodPost, Path: "/api/clubs/:clubId/invitations", Tag: "Clubs", Summary: "Invite someone, the link is only shown once", Auth: true,
+		Request: handler.InviteInput{}, Status: http.StatusCreated, Response: ClubInvitationResponse{}},
+	{Method: http.MethodDelete, Path: "/api/clubs/:clubId/invitations/:id", Tag: "Clubs", Summary: "Revoke an invitation", Auth: true,
+		Status: http.StatusOK, Response: ClubInvitationResponse{}},
+	{Method: http.MethodPost, Path: "/api/club-invitations/accept", Tag: "Clubs", Summary: "Join a club with an invitation", Auth: true,
+		Request: handler.AcceptInvitationInput{}, Status: http.StatusOK, Response: ClubResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/players", Tag: "Players", Summary: "Create a player in the given or active club", Auth: true, Scope: handler.ScopePlayersWrite,
+		Request: CreatePlayerRequest{}, Status: http.StatusCreated, Response: PlayerResponse{}},
+	{Method: http.MethodGet, Path: "/api/players", Tag: "Players", Summary: "List the players of the active club", Auth: true, Scope: handler.ScopePlayersRead,
+		Params: handler.PlayerListInput{}, Status: http.StatusOK, Response: PlayerPageResponse{}},
+	{Method: http.MethodGet, Path: "/api/players/:id", Tag: "Players", Summary: "List the players of the club with this id", Auth: true, Scope: handler.ScopePlayersRead,
+		Params: handler.PlayerListInput{}, Status: http.StatusOK, Response: PlayerPageResponse{}},
+	{Method: http.MethodDelete, Path: "/api/players/:id", Tag: "Players", Summary: "Delete a player", Auth: true, Scope: handler.ScopePlayersWrite,
+		Status: http.StatusOK, Response: PlayerResponse{}},
+	{Method: http.MethodPut, Path: "/api/players", Tag: "Players", Summary: "Rename a player", Auth: true, Scope: handler.ScopePlayersWrite,
+		Request: UpdatePlayerRequest{}, Status: http.StatusOK, Response: PlayerResponse{}},
+
+	{Method: http.MethodPost, Path: "/api/teams", Tag: "Teams", Summary: "Create a team in the given or active club", Auth: true, Scope: handler.ScopeTeamsWrite,
+		Request: CreateTeamRequest{}, Status: http.StatusCreated, Response: TeamResponse{}},
+	{Method: http.MethodGet, Path: "/api/teams", Tag: "Teams", Summary: "List the teams of the active club", Auth: true, Scope: handler.ScopeTeamsRead,
+		Params: handler.TeamListInput{}, Status: http.StatusOK, Response: TeamPageResponse{}},
+	{Method: http.MethodGet, Path: "/api/teams/:clubId", Tag: "Teams", Summary: "List the teams of a club", Auth: true, Scope: handler.ScopeTeamsRead,
+		Params: handler.TeamListInput{}, Status: http.StatusOK, Response: TeamPageResponse{}},
+	{Method: http.MethodDelete, Path: "/api/teams/:id", Tag: "Teams", Summary: "Delete a team", Auth: true, Scope: handler.ScopeTeamsWrite,
+		Status: http.StatusOK, Response: TeamResponse{}},
+	{Method: http.MethodPut, Path: "/api/teams", Tag: "Teams", Summary: "Change a team", Auth: true, Scope: handler.ScopeTeamsWrite,
+		Request: UpdateTeamRequest{}, Status: http.StatusOK, Response: TeamResponse{}},
+}
+
+var pathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+// openAPIPath turns the echo path /api/players/:id into /api/players/{id}.
+func openAPIPath(echoPath string) string {
+	return pathParamPattern.ReplaceAllString(echoPath, "{$1}")
+}
+
+// openAPIDocument builds the OpenAPI 3 document of apiOperations.
+func openAPIDocument() jsonObject {
+	schemas := newOpenAPISchemas()
+	paths := map[string]jsonObject{}
+	for _, op := range apiOperations {
+		path := openAPIPath(op.Path)
+		if paths[path] == nil {
+			paths[path] = jsonObject{}
+		}
+		paths[path][strings.ToLower(op.Method)] = op.document(schemas)
+	}
+
+	return jsonObject{
+		"openapi": "3.0.3",
+		"info": jsonObject{
+			"title":       "Tennis Analysis API",
+			"version":     "1.0.0",
+			"description": "Errors are answered with an ErrorResponse, invalid inputs list every invalid field in fields.",
+		},
+		"paths": paths,
+		"components": jsonObject{
+			"schemas": schemas.components,
+			"securitySchemes": jsonObject{
+				"bearerAuth": jsonObject{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "An access token, or a personal access token on routes that name a scope.",
+				},
+				"cookieAuth": jsonObject{
+					"type":        "apiKey",
+					"in":          "cookie",
+					"name":        accessTokenCookie,
+					"description": "The session cookie of the cookie mode. Changing requests also need the X-CSRF-Token header.",
+				},
+			},
+		},
+	}
+}
+
+func (op apiOperation) document(schemas *openAPISchemas) jsonObject {
+	operation := jsonObject{
+		"tags":    []string{op.Tag},
+		"summary": op.Summary,
+	}
+
+	parameters := []jsonObject{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+		parameters = append(parameters, jsonObject{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   jsonObject{"type": "string", "format": "uuid"},
+		})
+	}
+	for _, name := range op.Query {
+		parameters = append(parameters, jsonObject{
+			"name":   name,
+			"in":     "query",
+			"schema": jsonObject{"type": "string"},
+		})
+	}
+	if op.Params != nil {
+		parameters = append(parameters, schemas.queryParameters(reflect.TypeOf(op.Params))...)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.Request != nil {
+		operation["requestBody"] = jsonObject{
+			"required": true,
+			"content":  jsonObject{echo.MIMEApplicationJSON: jsonObject{"schema": schemas.of(reflect.TypeOf(op.Request))}},
+		}
+	}
+
+	success := jsonObject{"description": http.StatusText(op.Status)}
+	if alternatives, ok := op.Response.(oneOf); ok {
+		options := []jsonObject{}
+		for _, alternative := range alternatives {
+			options = append(options, schemas.of(reflect.TypeOf(alternative)))
+		}
+		success["content"] = jsonObject{echo.MIMEApplicationJSON: jsonObject{"schema": jsonObject{"oneOf": options}}}
+	} else if op.Response != nil {
+		success["content"] = jsonObject{echo.MIMEApplicationJSON: jsonObject{"schema": schemas.of(reflect.TypeOf(op.Response))}}
+	}
+	operation["responses"] = jsonObject{
+		fmt.Sprint(op.Status): success,
+		"default": jsonObject{
+			"description": "Error",
+			"content":     jsonObject{echo.MIMEApplicationJSON: jsonObject{"schema": schemas.of(reflect.TypeOf(ErrorResponse{}))}},
+		},
+	}
+
+	if op.Auth {
+		operation["security"] = []jsonObject{{"bearerAuth": []string{}}, {"cookieAuth": []string{}}}
+	}
+	if op.Scope != "" {
+		operation["description"] = fmt.Sprintf("Personal access tokens need the %s scope.", op.Scope)
+	}
+	return operation
+}
+
+// openAPISchemas collects the schemas of the structs a document refers to.
+type openAPISchemas struct {
+	components jsonObject
+	names      map[reflect.Type]string
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{components: jsonObject{}, names: map[reflect.Type]string{}}
+}
+
+func (s *openAPISchemas) of(t reflect.Type) jsonObject {
+	switch t {
+	case reflect.TypeOf(uuid.UUID{}):
+		return jsonObject{"type": "string", "format": "uuid"}
+	case reflect.TypeOf(time.Time{}):
+		return jsonObject{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.of(t.Elem())
+		if _, ref := schema["$ref"]; ref {
+			return jsonObject{"allOf": []jsonObject{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return jsonObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return jsonObject{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return jsonObject{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonObject{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return jsonObject{"type": "number"}
+	case reflect.String:
+		return jsonObject{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonObject{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return jsonObject{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Interface:
+		return jsonObject{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+	panic("openapi: no schema for " + t.String())
+}
+
+func (s *openAPISchemas) ref(t reflect.Type) jsonObject {
+	name, ok := s.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := s.components[name]; taken {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		s.names[t] = name
+		s.components[name] = jsonObject{}
+		s.components[name] = s.object(t)
+	}
+	return jsonObject{"$ref": "#/components/schemas/" + name}
+}
+
+func (s *openAPISchemas) object(t reflect.Type) jsonObject {
+	properties := jsonObject{}
+	required := []string{}
+	s.addFields(t, properties, &required)
+
+	schema := jsonObject{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *openAPISchemas) addFields(t reflect.Type, properties jsonObject, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			s.addFields(field.Type, properties, required)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := s.of(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "required" {
+				*required = append(*required, name)
+				continue
+			}
+			describeRule(schema, rule, t)
+		}
+		properties[name] = schema
+	}
+}
+
+// queryParameters describes the fields of t with a query tag.
+func (s *openAPISchemas) queryParameters(t reflect.Type) []jsonObject {
+	parameters := []jsonObject{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			parameters = append(parameters, s.queryParameters(field.Type)...)
+			continue
+		}
+		name := field.Tag.Get("query")
+		if !field.IsExported() || name == "" {
+			continue
+		}
+
+		schema := s.of(field.Type)
+		delete(schema, "nullable")
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			describeRule(schema, rule, t)
+		}
+		parameters = append(parameters, jsonObject{"name": name, "in": "query", "schema": schema})
+	}
+	return parameters
+}
+
+// describeRule adds a validate rule of handler.Validate to the schema of the
+// field.
+func describeRule(schema jsonObject, rule string, parent reflect.Type) {
+	if _, ref := schema["$ref"]; ref || rule == "" {
+		return
+	}
+	name, param, _ := strings.Cut(rule, "=")
+	switch name {
+	case "min", "max":
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		keywords := map[interface{}][2]string{
+			"string":  {"minLength", "maxLength"},
+			"array":   {"minItems", "maxItems"},
+			"integer": {"minimum", "maximum"},
+		}[schema["type"]]
+		if name == "min" && keywords[0] != "" {
+			schema[keywords[0]] = limit
+		} else if name == "max" && keywords[1] != "" {
+			schema[keywords[1]] = limit
+		}
+	case "maxbytes":
+		description, _ := schema["description"].(string)
+		schema["description"] = strings.TrimSpace(description + " At most " + param + " bytes in UTF-8.")
+	case "email":
+		schema["format"] = "email"
+	case "password":
+		schema["minLength"] = 8
+		schema["description"] = "At least 8 characters with a letter and a digit."
+	case "eqfield":
+		if other, ok := parent.FieldByName(param); ok {
+			other, _, _ := strings.Cut(other.Tag.Get("json"), ",")
+			schema["description"] = "Must match " + other + "."
+		}
+	case "oneof":
+		schema["enum"] = strings.Fields(param)
+	}
+}
+
+type OpenAPIRouter struct {
+	Document jsonObject
+}
+
+func newOpenAPIRouter() *OpenAPIRouter {
+	return &OpenAPIRouter{Document: openAPIDocument()}
+}
+
+func (r *OpenAPIRouter) GetDocument(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, r.Document)
+}
+
+func RegisterOpenAPIRoute(baseUrl string, e *echo.Echo, r OpenAPIRouter) {
+	e.GET(baseUrl+"/openapi.json", r.GetDocument)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	e := newEcho()
+	registerApiRoutes("/api", e, handler.ResourceHandlers{}, nil, Middleware{})
+
+	paths := openAPIDocument()["paths"].(map[string]jsonObject)
+	registered := map[string]bool{}
+	for _, route := range e.Routes() {
+		path := openAPIPath(route.Path)
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		assert.Contains(t, paths[path], method, "%s %s is missing in the OpenAPI document", route.Method, route.Path)
+	}
+
+	for path, operations := range paths {
+		for method := range operations {
+			assert.True(t, registered[method+" "+path], "%s %s is documented but not registered", method, path)
+		}
+	}
+}
+
+func TestOpenAPIRoute(t *testing.T) {
+	e := newEcho()
+	RegisterOpenAPIRoute("/api", e, *newOpenAPIRouter())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var document struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                          `json:"required"`
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+
+	register := document.Components.Schemas["RegisterInput"]
+	assert.Equal(t, []string{"username", "email", "password", "confirm"}, register.Required)
+	assert.Equal(t, float64(3), register.Properties["username"]["minLength"])
+	assert.Equal(t, "email", register.Properties["email"]["format"])
+	assert.Equal(t, float64(8), register.Properties["password"]["minLength"])
+
+	role := document.Components.Schemas["ChangeRoleInput"].Properties["role"]
+	assert.Equal(t, []interface{}{"owner", "coach", "viewer"}, role["enum"])
+
+	assert.NotContains(t, document.Components.Schemas["AccountResponse"].Properties, "passwordHash")
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type PasswordRouter struct {
+	PasswordHandler handler.PasswordHandler
+}
+
+type MessageResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func newPasswordRouter(h handler.PasswordHandler) *PasswordRouter {
+	return &PasswordRouter{PasswordHandler: h}
+}
+
+func (r *PasswordRouter) RequestPasswordReset(ctx echo.Context) (err error) {
+	input := new(handler.PasswordResetRequestInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	r.PasswordHandler.RequestReset(input.Email)
+
+	return ctx.JSON(http.StatusAccepted, MessageResponse{
+		Status:  "success",
+		Message: "If an account with this email exists, a reset link is on its way.",
+	})
+}
+
+func (r *PasswordRouter) ConfirmPasswordReset(ctx echo.Context) (err error) {
+	input := new(handler.PasswordResetConfirmInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	err = r.PasswordHandler.ConfirmReset(ctx.Request().Context(), *input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, MessageResponse{
+		Status:  "success",
+		Message: "Password was reset, please log in again.",
+	})
+}
+
+func RegisterPasswordRoute(baseUrl string, e *echo.Echo, r PasswordRouter) {
+	e.POST(baseUrl+"/password-reset", r.RequestPasswordReset)
+	e.POST(baseUrl+"/password-reset/confirm", r.ConfirmPasswordReset)
+}
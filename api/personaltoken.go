@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type PersonalTokenRouter struct {
+	PersonalTokenHandler handler.PersonalTokenHandler
+}
+
+// PersonalTokenResponse describes a token. Only the response to creating one
+// carries the token itself.
+type PersonalTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiryDate *time.Time `json:"expiryDate"`
+	Token      string     `json:"token,omitempty"`
+}
+
+func newPersonalTokenRouter(p handler.PersonalTokenHandler) *PersonalTokenRouter {
+	return &PersonalTokenRouter{PersonalTokenHandler: p}
+}
+
+func newPersonalTokenResponse(token db.PersonalAccessToken) PersonalTokenResponse {
+	response := PersonalTokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.LastUsedAt.Valid {
+		response.LastUsedAt = &token.LastUsedAt.Time
+	}
+	if token.ExpiryDate.Valid {
+		response.ExpiryDate = &token.ExpiryDate.Time
+	}
+	return response
+}
+
+func (r *PersonalTokenRouter) GetTokens(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := r.PersonalTokenHandler.GetTokens(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	response := []PersonalTokenResponse{}
+	for _, token := range tokens {
+		response = append(response, newPersonalTokenResponse(token))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (r *PersonalTokenRouter) CreateToken(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.CreatePersonalTokenInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	created, err := r.PersonalTokenHandler.CreateToken(ctx.Request().Context(), user, *input)
+	if err != nil {
+		return err
+	}
+
+	response := newPersonalTokenResponse(created.Token)
+	response.Token = created.Secret
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (r *PersonalTokenRouter) RevokeToken(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := uuidParam(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	token, err := r.PersonalTokenHandler.RevokeToken(ctx.Request().Context(), user, id)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, newPersonalTokenResponse(token))
+}
+
+// RegisterPersonalTokenRoute only accepts logins, so a token can't be used to
+// create or revoke tokens.
+func RegisterPersonalTokenRoute(baseUrl string, e *echo.Echo, r PersonalTokenRouter, middleware Middleware) {
+	e.GET(baseUrl+"/tokens", r.GetTokens, middleware.AuthMiddleware)
+	e.POST(baseUrl+"/tokens", r.CreateToken, middleware.AuthMiddleware, middleware.VerifiedMiddleware)
+	e.DELETE(baseUrl+"/tokens/:id", r.RevokeToken, middleware.AuthMiddleware)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+var personalTokenHandler = handler.NewPersonalTokenHandler(TestDb, *userHandler)
+
+func TestPersonalTokens(t *testing.T) {
+	e := newEcho()
+	middleware := NewMiddleware(*authHandler, *authorizationHandler, *personalTokenHandler)
+	RegisterPlayersRoute("/api", e, *playRouter, *middleware)
+	RegisterPersonalTokenRoute("/api", e, *newPersonalTokenRouter(*personalTokenHandler), *middleware)
+
+	user := registerNamedUser(t, e, "token-user")
+	defer userHandler.DeleteUserById(context.Background(), user.User.ID)
+	clubId := user.User.ID.String()
+
+	rec := clubRequest(t, e, TestClubRequest{
+		as:     user,
+		method: http.MethodPost,
+		url:    "/api/tokens",
+		body:   handler.CreatePersonalTokenInput{Name: "stats script", Scopes: []string{"players:read"}},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	created := PersonalTokenResponse{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, handler.IsPersonalToken(created.Token))
+
+	script := &AuthResponse{AccessToken: created.Token}
+	steps := []TestClubRequest{
+		{"token reads players", script, http.MethodGet, "/api/players/" + clubId, nil, http.StatusOK},
+		{"token can't write without scope", script, http.MethodPost, "/api/players",
+			CreatePlayerRequest{FirstName: "Token", LastName: "Player"}, http.StatusForbidden},
+		{"token can't manage tokens", script, http.MethodGet, "/api/tokens", nil, http.StatusForbidden},
+		{"unknown tokens are rejected", &AuthResponse{AccessToken: handler.PersonalTokenPrefix + "nope"},
+			http.MethodGet, "/api/players/" + clubId, nil, http.StatusUnauthorized},
+		{"owner revokes the token", user, http.MethodDelete, "/api/tokens/" + created.ID.String(), nil, http.StatusOK},
+		{"revoked token is rejected", script, http.MethodGet, "/api/players/" + clubId, nil, http.StatusUnauthorized},
+	}
+
+	for _, data := range steps {
+		t.Run(data.name, func(t *testing.T) {
+			rec := clubRequest(t, e, data)
+			assert.Equal(t, data.status, rec.Code, rec.Body.String())
+		})
+	}
+
+	t.Run("listed tokens don't show the secret", func(t *testing.T) {
+		rec := clubRequest(t, e, TestClubRequest{as: user, method: http.MethodGet, url: "/api/tokens"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), created.Token)
+	})
+}
@@ -2,12 +2,12 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/handler"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"github.com/lib/pq"
 )
 
 type PlayerRouter struct {
@@ -25,111 +25,161 @@ func newPlayerRouter(
 }
 
 type CreatePlayerRequest struct {
+	FirstName string    `json:"firstName" validate:"required,max=50"`
+	LastName  string    `json:"lastName" validate:"required,max=50"`
+	ClubId    uuid.UUID `json:"clubId"`
+}
+
+type PlayerResponse struct {
+	ID        uuid.UUID `json:"id"`
 	FirstName string    `json:"firstName"`
 	LastName  string    `json:"lastName"`
-	UserId    uuid.UUID `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-func (r *PlayerRouter) CreatePlayer(ctx echo.Context) (err error) {
-	request := new(CreatePlayerRequest)
-	if err = ctx.Bind(request); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	if request.FirstName == "" || request.LastName == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "missing first or last name")
+func newPlayerResponse(player db.Player) PlayerResponse {
+	return PlayerResponse{
+		ID:        player.ID,
+		FirstName: player.FirstName,
+		LastName:  player.LastName,
+		CreatedAt: player.CreatedAt,
+		UpdatedAt: player.UpdatedAt,
 	}
+}
 
-	teamParams := db.CreateNewTeamWithOnePlayerParams{
-		FirstName: request.FirstName,
-		LastName:  request.LastName,
-		Name: request.FirstName + " " + request.LastName,
-		UserID: request.UserId,
-	}
+// PlayerSummaryResponse is a player in a list, with the number of teams they
+// play in besides their own and when they played their last match.
+type PlayerSummaryResponse struct {
+	PlayerResponse
+	TeamCount    int64      `json:"teamCount"`
+	MatchCount   int64      `json:"matchCount"`
+	LastPlayedAt *time.Time `json:"lastPlayedAt"`
+}
 
-	team, err := r.TeamHandler.CreateTeamWithOnePlayer(ctx.Request().Context(), teamParams)
-	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-		return echo.NewHTTPError(http.StatusConflict, "player already exists")
+func newPlayerSummaryResponse(row db.PlayerSummary) PlayerSummaryResponse {
+	response := PlayerSummaryResponse{
+		PlayerResponse: newPlayerResponse(db.Player{
+			ID:        row.ID,
+			FirstName: row.FirstName,
+			LastName:  row.LastName,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			ClubID:    row.ClubID,
+		}),
+		TeamCount:  row.TeamCount,
+		MatchCount: row.MatchCount,
 	}
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-
-	player, err := r.PlayerHandler.GetPlayerById(ctx.Request().Context(), team.PlayerOne)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	if row.LastPlayedAt.Valid {
+		response.LastPlayedAt = &row.LastPlayedAt.Time
 	}
+	return response
+}
 
-	return ctx.JSON(http.StatusCreated, player)
+// PlayerPageResponse is one page of players. NextCursor is left out on the
+// last page.
+type PlayerPageResponse struct {
+	Data       []PlayerSummaryResponse `json:"data"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
 }
 
-func (r *PlayerRouter) GetAllPlayersByUserId(ctx echo.Context) (err error) {
-	param := ctx.Param("id")
-	userId, err := uuid.Parse(param)
-	if err != nil {
-    return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-  }
+type UpdatePlayerRequest struct {
+	ID        uuid.UUID `json:"id" validate:"required"`
+	FirstName string    `json:"firstName" validate:"required,max=50"`
+	LastName  string    `json:"lastName" validate:"required,max=50"`
+}
 
-	teams, err := r.TeamHandler.DB.GetAllTeamsByUserId(ctx.Request().Context(), userId)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+func (r *PlayerRouter) CreatePlayer(ctx echo.Context) (err error) {
+	request := new(CreatePlayerRequest)
+	if err = bind(ctx, request); err != nil {
+		return err
 	}
 
-	var allPlayerIds []uuid.UUID
+	teamParams := db.CreateNewTeamWithOnePlayerParams{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Name:      request.FirstName + " " + request.LastName,
+		ClubID:    currentClubId(ctx, request.ClubId),
+	}
 
-	for _, team := range teams {
-		if team.PlayerTwo == nil {
-			allPlayerIds = append(allPlayerIds, team.PlayerOne)
-		}
+	player, err := r.PlayerHandler.CreatePlayer(ctx.Request().Context(), teamParams)
+	if err != nil {
+		return err
 	}
 
-	var allPlayer []db.Player
+	return ctx.JSON(http.StatusCreated, newPlayerResponse(player))
+}
 
-	for _, id := range allPlayerIds {
-		player, err := r.PlayerHandler.GetPlayerById(ctx.Request().Context(), id)
+// GetAllPlayersByClubId lists a page of the players of the club in the path,
+// or of the active club without one.
+func (r *PlayerRouter) GetAllPlayersByClubId(ctx echo.Context) (err error) {
+	clubId := currentClubId(ctx, uuid.Nil)
+	if param := ctx.Param("id"); param != "" {
+		clubId, err = uuid.Parse(param)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return handler.ValidationError(err)
 		}
+	}
+	input := new(handler.PlayerListInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
 
-		allPlayer = append(allPlayer, player)
+	page, err := r.PlayerHandler.ListPlayers(ctx.Request().Context(), clubId, *input)
+	if err != nil {
+		return err
+	}
+	response := PlayerPageResponse{Data: []PlayerSummaryResponse{}, NextCursor: page.NextCursor}
+	for _, player := range page.Items {
+		response.Data = append(response.Data, newPlayerSummaryResponse(player))
 	}
 
-	return ctx.JSON(http.StatusOK, allPlayer)
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func (r *PlayerRouter) DeletePlayerById(ctx echo.Context) (err error) {
 	playerId, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.ValidationError(err)
 	}
 
 	player, err := r.PlayerHandler.DeletePlayerById(ctx.Request().Context(), playerId)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.DatabaseError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, player)
+	return ctx.JSON(http.StatusOK, newPlayerResponse(player))
 }
 
 func (r *PlayerRouter) UpdatePlayerById(ctx echo.Context) (err error) {
-	request := new(db.UpdatePlayerByIdParams)
-	if err = ctx.Bind(request); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	request := new(UpdatePlayerRequest)
+	if err = bind(ctx, request); err != nil {
+		return err
 	}
 
-	player, err := r.PlayerHandler.UpdatePlayerById(ctx.Request().Context(), *request)
+	player, err := r.PlayerHandler.UpdatePlayerById(ctx.Request().Context(), db.UpdatePlayerByIdParams{
+		ID:        request.ID,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.DatabaseError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, player)
+	return ctx.JSON(http.StatusOK, newPlayerResponse(player))
 }
 
 func RegisterPlayersRoute(baseUrl string, e *echo.Echo, r PlayerRouter, middleware Middleware) {
-	e.POST(baseUrl+"/players", r.CreatePlayer, middleware.AuthMiddleware)
-	e.GET(baseUrl+"/players/:id", r.GetAllPlayersByUserId, middleware.AuthMiddleware)
-	e.DELETE(baseUrl+"/players/:id", r.DeletePlayerById, middleware.AuthMiddleware)
-	e.PUT(baseUrl+"/players", r.UpdatePlayerById, middleware.AuthMiddleware)
+	e.POST(baseUrl+"/players", r.CreatePlayer, middleware.AllowToken(handler.ScopePlayersWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionEditRoster, clubFromBody))
+	e.GET(baseUrl+"/players", r.GetAllPlayersByClubId, middleware.AllowToken(handler.ScopePlayersRead),
+		middleware.Authorize(handler.PermissionViewRoster, activeClub))
+	e.GET(baseUrl+"/players/:id", r.GetAllPlayersByClubId, middleware.AllowToken(handler.ScopePlayersRead),
+		middleware.Authorize(handler.PermissionViewRoster, clubFromParam("id")))
+	e.DELETE(baseUrl+"/players/:id", r.DeletePlayerById, middleware.AllowToken(handler.ScopePlayersWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionDeleteRoster, playerClubFromParam("id")))
+	e.PUT(baseUrl+"/players", r.UpdatePlayerById, middleware.AllowToken(handler.ScopePlayersWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionEditRoster, playerClubFromBody))
 }
 
 func filter() {
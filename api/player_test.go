@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
@@ -27,12 +29,12 @@ type TestGetAllPlayer struct {
 	expectedLength int
 }
 
-var playerHandler = handler.NewPlayerHandler(utils.DbQueriesTest())
-var teamHandler = handler.NewTeamHandler(utils.DbQueriesTest())
+var playerHandler = handler.NewPlayerHandler(TestDb)
+var teamHandler = handler.NewTeamHandler(TestDb)
 var playRouter = newPlayerRouter(*playerHandler, *teamHandler, *userHandler)
 
 func TestCreatePlayer(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 	user := DummyUser(t, e)
 	userId := user.ID
 	testCreatePlayerInput := []TestPlayerInput{
@@ -46,46 +48,46 @@ func TestCreatePlayer(t *testing.T) {
 				FirstName: "Oskar",
 				LastName:  "Kuech",
 				Name:      "Oskar Kuech",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 		{
 			name: "error new player",
 			error: TestError{
 				IsError:       true,
-				ExpectedError: &echo.HTTPError{Code: 400, Message: "missing first or last name", Internal: error(nil)},
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "firstName", Message: "is required"}}),
 			},
 			input: db.CreateNewTeamWithOnePlayerParams{
 				FirstName: "",
 				LastName:  "Kuech",
 				Name:      "",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 		{
 			name: "error new player",
 			error: TestError{
 				IsError:       true,
-				ExpectedError: &echo.HTTPError{Code: 400, Message: "missing first or last name", Internal: error(nil)},
+				ExpectedError: handler.ValidationError(handler.FieldErrors{{Field: "lastName", Message: "is required"}}),
 			},
 			input: db.CreateNewTeamWithOnePlayerParams{
 				FirstName: "Oskar",
 				LastName:  "",
 				Name:      "",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 		{
 			name: "error new player",
 			error: TestError{
 				IsError:       true,
-				ExpectedError: &echo.HTTPError{Code: 409, Message: "player already exists", Internal: error(nil)},
+				ExpectedError: handler.ConflictError(handler.PlayerExists),
 			},
 			input: db.CreateNewTeamWithOnePlayerParams{
 				FirstName: "Oskar",
 				LastName:  "Kuech",
 				Name:      "",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 		{
@@ -98,7 +100,7 @@ func TestCreatePlayer(t *testing.T) {
 				FirstName: "Oskar",
 				LastName:  "Test",
 				Name:      "",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 		{
@@ -111,7 +113,7 @@ func TestCreatePlayer(t *testing.T) {
 				FirstName: "Laurin",
 				LastName:  "Test",
 				Name:      "",
-				UserID:    userId,
+				ClubID:    userId,
 			},
 		},
 	}
@@ -149,8 +151,8 @@ func TestCreatePlayer(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestGetAllPlayersByUserId(t *testing.T) {
-	e := echo.New()
+func TestGetAllPlayersByClubId(t *testing.T) {
+	e := newEcho()
 
 	user := DummyUser(t, e)
 	userId := user.ID
@@ -168,13 +170,13 @@ func TestGetAllPlayersByUserId(t *testing.T) {
 					FirstName: "Laurin",
 					LastName:  "Notemann",
 					Name:      "",
-					UserID:    userId,
+					ClubID:    userId,
 				},
 				{
 					FirstName: "Max",
 					LastName:  "Mustermann",
 					Name:      "",
-					UserID:    userId,
+					ClubID:    userId,
 				},
 			},
 			expectedLength: 2,
@@ -192,7 +194,7 @@ func TestGetAllPlayersByUserId(t *testing.T) {
 				http.MethodGet,
 				url,
 				string(""),
-				playRouter.GetAllPlayersByUserId,
+				playRouter.GetAllPlayersByClubId,
 				userIdString,
 			)
 			if data.error.IsError {
@@ -201,11 +203,12 @@ func TestGetAllPlayersByUserId(t *testing.T) {
 				}
 			} else {
 				if assert.NoError(t, err, "Error with CreatePlayer route") {
-					allPlayers := new([]db.Player)
+					allPlayers := new(PlayerPageResponse)
 					err := json.Unmarshal(rec.Body.Bytes(), allPlayers)
 					assert.NoError(t, err, "Couldn't decode list of Players")
 
-					assert.Equal(t, data.expectedLength, len(*allPlayers))
+					assert.Equal(t, data.expectedLength, len(allPlayers.Data))
+					assert.Empty(t, allPlayers.NextCursor)
 				}
 			}
 		})
@@ -214,6 +217,180 @@ func TestGetAllPlayersByUserId(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListPlayerPages(t *testing.T) {
+	e := newEcho()
+
+	user := DummyUser(t, e)
+	userId := user.ID
+	addMultiplePlayers(t, e, []db.CreateNewTeamWithOnePlayerParams{
+		{FirstName: "Laurin", LastName: "Notemann", ClubID: userId},
+		{FirstName: "Max", LastName: "Mustermann", ClubID: userId},
+		{FirstName: "Oskar", LastName: "Kuech", ClubID: userId},
+	})
+
+	list := func(query string) PlayerPageResponse {
+		err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/players/:id?"+query, "", playRouter.GetAllPlayersByClubId, userId.String())
+		assert.NoError(t, err)
+		page := PlayerPageResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return page
+	}
+	lastNames := func(page PlayerPageResponse) []string {
+		names := []string{}
+		for _, player := range page.Data {
+			names = append(names, player.LastName)
+		}
+		return names
+	}
+
+	first := list("limit=2")
+	assert.Equal(t, []string{"Kuech", "Mustermann"}, lastNames(first))
+	assert.NotEmpty(t, first.NextCursor)
+	second := list("limit=2&cursor=" + first.NextCursor)
+	assert.Equal(t, []string{"Notemann"}, lastNames(second))
+	assert.Empty(t, second.NextCursor)
+
+	assert.Equal(t, []string{"Notemann", "Mustermann", "Kuech"}, lastNames(list("sort=firstName")))
+	assert.Equal(t, []string{"Mustermann"}, lastNames(list("name=max%20m")))
+	assert.Equal(t, []string{"Notemann"}, lastNames(list("name=note")))
+
+	err, _, _ := DummyRequest(t, e, http.MethodGet, "/api/players/:id?sort=lastName&cursor="+first.NextCursor+"x", "", playRouter.GetAllPlayersByClubId, userId.String())
+	assert.Equal(t, handler.KindValidation, handler.KindOf(err))
+	err, _, _ = DummyRequest(t, e, http.MethodGet, "/api/players/:id?sort=-lastName&cursor="+first.NextCursor, "", playRouter.GetAllPlayersByClubId, userId.String())
+	assert.Equal(t, handler.ValidationError(handler.CursorInvalid), err)
+
+	_, err = userHandler.DeleteUserById(context.Background(), userId)
+	assert.NoError(t, err)
+}
+
+func TestListPlayerStats(t *testing.T) {
+	e := newEcho()
+
+	user := DummyUser(t, e)
+	team, laurin, oskar := DummyTeam(t, e, user.ID)
+	mustermann := addNewPlayer(t, e, db.CreateNewTeamWithOnePlayerParams{FirstName: "Max", LastName: "Mustermann", ClubID: user.ID})
+
+	ctx := context.Background()
+	teams, err := teamHandler.ListTeams(ctx, user.ID, handler.TeamListInput{ListInput: handler.ListInput{Name: "max"}})
+	assert.NoError(t, err)
+	assert.Len(t, teams.Items, 1)
+	match, err := TestDb.CreateMatch(ctx, db.CreateMatchParams{ClubID: user.ID, TeamOne: team.ID, TeamTwo: teams.Items[0].ID})
+	assert.NoError(t, err)
+
+	err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/players/:id", "", playRouter.GetAllPlayersByClubId, user.ID.String())
+	assert.NoError(t, err)
+	page := PlayerPageResponse{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+
+	stats := map[uuid.UUID]PlayerSummaryResponse{}
+	for _, player := range page.Data {
+		stats[player.ID] = player
+	}
+	assert.Len(t, stats, 3)
+	for _, player := range []db.Player{laurin, oskar} {
+		assert.Equal(t, int64(1), stats[player.ID].TeamCount)
+		assert.Equal(t, int64(1), stats[player.ID].MatchCount)
+		if assert.NotNil(t, stats[player.ID].LastPlayedAt) {
+			assert.True(t, match.CreatedAt.Equal(*stats[player.ID].LastPlayedAt))
+		}
+	}
+	assert.Equal(t, int64(0), stats[mustermann.ID].TeamCount)
+	assert.Equal(t, int64(1), stats[mustermann.ID].MatchCount)
+
+	_, err = userHandler.DeleteUserById(ctx, user.ID)
+	assert.NoError(t, err)
+}
+
+// BenchmarkListPlayers lists a page of 100 players of a club with 200 players
+// in 100 doubles teams and almost 300 matches. The page is read with one
+// query, more players must not make it slower per player. It needs the test
+// database, the in-memory one says nothing about the query.
+func BenchmarkListPlayers(b *testing.B) {
+	ctx := context.Background()
+	queries := utils.DbQueriesTest()
+	router := newPlayerRouter(*handler.NewPlayerHandler(queries), *handler.NewTeamHandler(queries), *handler.NewUserHandler(queries, Cfg))
+	club, err := queries.CreateClub(ctx, db.CreateClubParams{ID: uuid.New(), Name: "Benchmark"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer queries.DeleteClubById(ctx, club.ID)
+	seedPlayers(b, queries, club.ID, 200)
+
+	e := newEcho()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/players/"+club.ID.String()+"?limit=100", nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues(club.ID.String())
+		if err := router.GetAllPlayersByClubId(c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// seedPlayers adds count players to the club. Every two of them form a
+// doubles team, every player plays a singles match against the next one and
+// every team a doubles match against the next team.
+func seedPlayers(b *testing.B, queries db.Querier, clubId uuid.UUID, count int) {
+	ctx := context.Background()
+	singles := []db.Team{}
+	for i := 0; i < count; i++ {
+		team, err := queries.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{
+			FirstName: "Bench",
+			LastName:  fmt.Sprintf("%s-%03d", clubId.String()[:8], i),
+			Name:      fmt.Sprintf("Bench %03d", i),
+			ClubID:    clubId,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		singles = append(singles, team)
+	}
+
+	doubles := []db.Team{}
+	for i := 0; i+1 < len(singles); i += 2 {
+		team, err := queries.CreateTeamWithTwoPlayers(ctx, db.CreateTeamWithTwoPlayersParams{
+			Name:      fmt.Sprintf("Bench doubles %03d", i/2),
+			ClubID:    clubId,
+			PlayerOne: singles[i].PlayerOne,
+			PlayerTwo: &singles[i+1].PlayerOne,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		doubles = append(doubles, team)
+	}
+
+	for _, teams := range [][]db.Team{singles, doubles} {
+		for i := 0; i+1 < len(teams); i++ {
+			_, err := queries.CreateMatch(ctx, db.CreateMatchParams{ClubID: clubId, TeamOne: teams[i].ID, TeamTwo: teams[i+1].ID})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestListPlayersQueryValidation(t *testing.T) {
+	e := newEcho()
+	router := newPlayerRouter(handler.PlayerHandler{DB: utils.NewDBQueriesMock()}, handler.TeamHandler{}, handler.UserHandler{})
+	clubId := uuid.NewString()
+
+	err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/players/:id?createdAfter=2024-01-02T00:00:00Z&sort=createdAt", "", router.GetAllPlayersByClubId, clubId)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data":[]}`, rec.Body.String())
+
+	err, _, _ = DummyRequest(t, e, http.MethodGet, "/api/players/:id?limit=many", "", router.GetAllPlayersByClubId, clubId)
+	assert.Equal(t, handler.ValidationError(RequestInvalid), err)
+
+	err, _, _ = DummyRequest(t, e, http.MethodGet, "/api/players/:id?limit=500&sort=age", "", router.GetAllPlayersByClubId, clubId)
+	assert.Equal(t, handler.ValidationError(handler.FieldErrors{
+		{Field: "limit", Message: "must be at most 100"},
+		{Field: "sort", Message: "must be one of firstName, -firstName, lastName, -lastName, createdAt, -createdAt"},
+	}), err)
+}
+
 type TestDeletePlayer struct {
 	name  string
 	error TestError
@@ -221,7 +398,7 @@ type TestDeletePlayer struct {
 }
 
 func TestDeletePlayerById(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 
 	user := DummyUser(t, e)
 	userId := user.ID
@@ -280,7 +457,7 @@ type TestUpdatePlayer struct {
 }
 
 func TestUpdatePlayerById(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 
 	user := DummyUser(t, e)
 	userId := user.ID
@@ -350,7 +527,7 @@ func DummyPlayer(t *testing.T, e *echo.Echo, userId uuid.UUID) db.Player {
 		FirstName: "Laurin",
 		LastName:  "Notemann",
 		Name:      "",
-		UserID:    userId,
+		ClubID:    userId,
 	}
 	return addNewPlayer(t, e, seed)
 }
@@ -360,7 +537,7 @@ func DummyPlayerTwo(t *testing.T, e *echo.Echo, userId uuid.UUID) db.Player {
 		FirstName: "Oskar",
 		LastName:  "Kuech",
 		Name:      "",
-		UserID:    userId,
+		ClubID:    userId,
 	}
 	return addNewPlayer(t, e, seed)
 }
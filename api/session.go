@@ -0,0 +1,107 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type SessionRouter struct {
+	TokenHandler handler.RefreshTokenHandler
+}
+
+type SessionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	DeviceLabel string    `json:"deviceLabel"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUsedAt  time.Time `json:"lastUsedAt"`
+	ExpiryDate  time.Time `json:"expiryDate"`
+	Current     bool      `json:"current"`
+}
+
+func newSessionRouter(t handler.RefreshTokenHandler) *SessionRouter {
+	return &SessionRouter{TokenHandler: t}
+}
+
+func newSessionResponse(token db.RefreshToken, currentSession uuid.UUID) SessionResponse {
+	return SessionResponse{
+		ID:          token.ID,
+		DeviceLabel: token.DeviceLabel,
+		CreatedAt:   token.CreatedAt,
+		LastUsedAt:  token.LastUsedAt,
+		ExpiryDate:  token.ExpiryDate,
+		Current:     token.ID == currentSession,
+	}
+}
+
+func (r *SessionRouter) GetAllSessions(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := r.TokenHandler.GetAllTokensByUserId(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	sessionId := currentSessionId(ctx)
+	sessions := []SessionResponse{}
+	for _, token := range tokens {
+		sessions = append(sessions, newSessionResponse(token, sessionId))
+	}
+
+	return ctx.JSON(http.StatusOK, sessions)
+}
+
+func (r *SessionRouter) RevokeSessionById(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return handler.ValidationError(err)
+	}
+
+	token, err := r.TokenHandler.DeleteUserTokenById(ctx.Request().Context(), id, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return handler.NotFoundError(handler.SessionNotFound)
+	}
+	if err != nil {
+		return handler.DatabaseError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, newSessionResponse(token, currentSessionId(ctx)))
+}
+
+// Logout ends the session the request was made with. In cookie mode this is
+// the only way to get rid of the httpOnly cookies.
+func (r *SessionRouter) Logout(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.TokenHandler.DeleteUserTokenById(ctx.Request().Context(), currentSessionId(ctx), user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return handler.DatabaseError(err)
+	}
+
+	clearSessionCookies(ctx, r.TokenHandler.Env.SESSION)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func RegisterSessionRoute(baseUrl string, e *echo.Echo, r SessionRouter, middleware Middleware) {
+	e.GET(baseUrl+"/sessions", r.GetAllSessions, middleware.AuthMiddleware)
+	e.DELETE(baseUrl+"/sessions/:id", r.RevokeSessionById, middleware.AuthMiddleware)
+	e.POST(baseUrl+"/logout", r.Logout, middleware.AuthMiddleware)
+}
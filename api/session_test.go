@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var sessionRouter = newSessionRouter(*tokenHandler)
+
+func TestSessions(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+
+	phone, _, err := tokenHandler.CreateToken(context.Background(), handler.TokenHandlerInput{
+		UserId:      user.ID,
+		DeviceLabel: "phone",
+	})
+	assert.NoError(t, err)
+
+	sessions, err := tokenHandler.GetAllTokensByUserId(context.Background(), user.ID)
+	assert.NoError(t, err)
+	laptop := sessions[len(sessions)-1]
+
+	t.Run("list sessions", func(t *testing.T) {
+		err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/sessions", "", asUser(user, laptop.ID, sessionRouter.GetAllSessions), "")
+		if assert.NoError(t, err) {
+			allSessions := new([]SessionResponse)
+			err := json.Unmarshal(rec.Body.Bytes(), allSessions)
+			assert.NoError(t, err, "Couldn't decode list of sessions")
+
+			assert.Equal(t, 2, len(*allSessions))
+			for _, session := range *allSessions {
+				assert.Equal(t, session.ID == laptop.ID, session.Current)
+			}
+		}
+	})
+
+	t.Run("revoke session", func(t *testing.T) {
+		err, rec, _ := DummyRequest(t, e, http.MethodDelete, "/api/sessions/:id", "", asUser(user, laptop.ID, sessionRouter.RevokeSessionById), phone.ID.String())
+		if assert.NoError(t, err) {
+			revoked := new(SessionResponse)
+			err := json.Unmarshal(rec.Body.Bytes(), revoked)
+			assert.NoError(t, err, "Couldn't decode revoked session")
+			assert.Equal(t, "phone", revoked.DeviceLabel)
+
+			sessions, err := tokenHandler.GetAllTokensByUserId(context.Background(), user.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(sessions))
+		}
+	})
+
+	t.Run("revoke unknown session", func(t *testing.T) {
+		err, _, _ := DummyRequest(t, e, http.MethodDelete, "/api/sessions/:id", "", asUser(user, laptop.ID, sessionRouter.RevokeSessionById), phone.ID.String())
+		assert.Equal(t, handler.NotFoundError(handler.SessionNotFound), err)
+	})
+
+	_, err = userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
+
+// asUser wraps a route like AuthMiddleware would after a successful login.
+func asUser(user db.User, sessionId uuid.UUID, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		ctx.Set(userContextKey, user)
+		ctx.Set(sessionContextKey, sessionId)
+		return next(ctx)
+	}
+}
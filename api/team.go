@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/handler"
@@ -23,57 +24,131 @@ func newTeamRouter(
 	return &TeamRouter{PlayerHandler: p, TeamHandler: t, UserHandler: u}
 }
 
+type CreateTeamRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	ClubId    uuid.UUID  `json:"clubId"`
+	PlayerOne uuid.UUID  `json:"playerOne" validate:"required"`
+	PlayerTwo *uuid.UUID `json:"playerTwo"`
+}
+
+type UpdateTeamRequest struct {
+	ID        uuid.UUID  `json:"id" validate:"required"`
+	Name      string     `json:"name" validate:"required,max=100"`
+	PlayerOne uuid.UUID  `json:"playerOne" validate:"required"`
+	PlayerTwo *uuid.UUID `json:"playerTwo"`
+}
+
+type TeamResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	ClubId    uuid.UUID  `json:"clubId"`
+	PlayerOne uuid.UUID  `json:"playerOne"`
+	PlayerTwo *uuid.UUID `json:"playerTwo"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+func newTeamResponse(team db.Team) TeamResponse {
+	return TeamResponse{
+		ID:        team.ID,
+		Name:      team.Name,
+		ClubId:    team.ClubID,
+		PlayerOne: team.PlayerOne,
+		PlayerTwo: team.PlayerTwo,
+		CreatedAt: team.CreatedAt,
+		UpdatedAt: team.UpdatedAt,
+	}
+}
+
+// TeamPageResponse is one page of teams. NextCursor is left out on the last
+// page.
+type TeamPageResponse struct {
+	Data       []TeamResponse `json:"data"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
 func (r *TeamRouter) CreateTeam(ctx echo.Context) (err error) {
-	request := new(db.CreateTeamWithTwoPlayersParams)
-	if err = ctx.Bind(request); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	request := new(CreateTeamRequest)
+	if err = bind(ctx, request); err != nil {
+		return err
 	}
-	team, err := r.TeamHandler.CreateTeamWithTwoPlayers(ctx.Request().Context(), *request)
+	team, err := r.TeamHandler.CreateTeamWithTwoPlayers(ctx.Request().Context(), db.CreateTeamWithTwoPlayersParams{
+		Name:      request.Name,
+		ClubID:    currentClubId(ctx, request.ClubId),
+		PlayerOne: request.PlayerOne,
+		PlayerTwo: request.PlayerTwo,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.DatabaseError(err)
 	}
-	return ctx.JSON(http.StatusCreated, team)
+	return ctx.JSON(http.StatusCreated, newTeamResponse(team))
 }
 
-func (r *TeamRouter) GetAllTeamsByUserId(ctx echo.Context) (err error) {
-	param := ctx.Param("userId")
-	userId, err := uuid.Parse(param)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+// GetAllTeamsByClubId lists a page of the teams of the club in the path, or
+// of the active club without one.
+func (r *TeamRouter) GetAllTeamsByClubId(ctx echo.Context) (err error) {
+	clubId := currentClubId(ctx, uuid.Nil)
+	if param := ctx.Param("clubId"); param != "" {
+		clubId, err = uuid.Parse(param)
+		if err != nil {
+			return handler.ValidationError(err)
+		}
+	}
+	input := new(handler.TeamListInput)
+	if err = bind(ctx, input); err != nil {
+		return err
 	}
 
-	teams, err := r.TeamHandler.GetAllTeamsByUserId(ctx.Request().Context(), userId)
+	page, err := r.TeamHandler.ListTeams(ctx.Request().Context(), clubId, *input)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
+	}
+	response := TeamPageResponse{Data: []TeamResponse{}, NextCursor: page.NextCursor}
+	for _, team := range page.Items {
+		response.Data = append(response.Data, newTeamResponse(team))
 	}
-	return ctx.JSON(http.StatusOK, teams)
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func (r *TeamRouter) DeleteTeamById(ctx echo.Context) (err error) {
 	param := ctx.Param("id")
 	id, err := uuid.Parse(param)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.ValidationError(err)
 	}
 	team, err := r.TeamHandler.DeleteTeamById(ctx.Request().Context(), id)
-	return ctx.JSON(http.StatusOK, team)
+	if err != nil {
+		return handler.DatabaseError(err)
+	}
+	return ctx.JSON(http.StatusOK, newTeamResponse(team))
 }
 
 func (r *TeamRouter) UpdateTeamById(ctx echo.Context) (err error) {
-	request := new(db.UpdateTeamByIdParams)
-	if err = ctx.Bind(request); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	request := new(UpdateTeamRequest)
+	if err = bind(ctx, request); err != nil {
+		return err
 	}
-	team, err := r.TeamHandler.UpdateTeamById(ctx.Request().Context(), *request)
+	team, err := r.TeamHandler.UpdateTeamById(ctx.Request().Context(), db.UpdateTeamByIdParams{
+		ID:        request.ID,
+		Name:      request.Name,
+		PlayerOne: request.PlayerOne,
+		PlayerTwo: request.PlayerTwo,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.DatabaseError(err)
 	}
-	return ctx.JSON(http.StatusOK, team)
+	return ctx.JSON(http.StatusOK, newTeamResponse(team))
 }
 
 func RegisterTeamRoute(baseUrl string, e *echo.Echo, r TeamRouter, middleware Middleware) {
-	e.POST(baseUrl+"/teams", r.CreateTeam, middleware.AuthMiddleware)
-	e.GET(baseUrl+"/teams/:userId", r.GetAllTeamsByUserId, middleware.AuthMiddleware)
-	e.DELETE(baseUrl+"/teams/:id", r.DeleteTeamById, middleware.AuthMiddleware)
-	e.PUT(baseUrl+"/teams", r.UpdateTeamById, middleware.AuthMiddleware)
+	e.POST(baseUrl+"/teams", r.CreateTeam, middleware.AllowToken(handler.ScopeTeamsWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionEditRoster, teamClubFromBody))
+	e.GET(baseUrl+"/teams", r.GetAllTeamsByClubId, middleware.AllowToken(handler.ScopeTeamsRead),
+		middleware.Authorize(handler.PermissionViewRoster, activeClub))
+	e.GET(baseUrl+"/teams/:clubId", r.GetAllTeamsByClubId, middleware.AllowToken(handler.ScopeTeamsRead),
+		middleware.Authorize(handler.PermissionViewRoster, clubFromParam("clubId")))
+	e.DELETE(baseUrl+"/teams/:id", r.DeleteTeamById, middleware.AllowToken(handler.ScopeTeamsWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionDeleteRoster, teamClubFromParam("id")))
+	e.PUT(baseUrl+"/teams", r.UpdateTeamById, middleware.AllowToken(handler.ScopeTeamsWrite), middleware.VerifiedMiddleware,
+		middleware.Authorize(handler.PermissionEditRoster, teamClubFromBody))
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +24,7 @@ type TestCreateTeamInput struct {
 var teamRouter = newTeamRouter(*playerHandler, *teamHandler, *userHandler)
 
 func TestCreateTeam(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 	userId, playerOneId, playerTwoId := registerAndCreateTwoPlayers(t, e)
 
 	testInput := []TestCreateTeamInput{
@@ -32,7 +35,7 @@ func TestCreateTeam(t *testing.T) {
 				ExpectedError: nil,
 			},
 			input: db.CreateTeamWithTwoPlayersParams{
-				UserID:    userId,
+				ClubID:    userId,
 				PlayerOne: playerOneId,
 				PlayerTwo: playerTwoId,
 				Name:      "Test Name",
@@ -78,7 +81,7 @@ type TestUpdateTeamInput struct {
 }
 
 func TestUpdateTeam(t *testing.T) {
-	e := echo.New()
+	e := newEcho()
 	user := DummyUser(t, e)
 	userId := user.ID
 
@@ -88,7 +91,7 @@ func TestUpdateTeam(t *testing.T) {
 		FirstName: "Leonard",
 		LastName:  "Hopp",
 		Name:      "",
-		UserID:    userId,
+		ClubID:    userId,
 	}
 	newPlayerOne := addNewPlayer(t, e, inputPlayerOne)
 
@@ -96,7 +99,7 @@ func TestUpdateTeam(t *testing.T) {
 		FirstName: "Dongs",
 		LastName:  "Dings",
 		Name:      "",
-		UserID:    userId,
+		ClubID:    userId,
 	}
 	newPlayerTwo := addNewPlayer(t, e, inputPlayerTwo)
 
@@ -146,6 +149,25 @@ func TestUpdateTeam(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeleteTeam(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+	team, _, _ := DummyTeam(t, e, user.ID)
+
+	err, rec, _ := DummyRequest(t, e, http.MethodDelete, "/api/teams/:id", "", teamRouter.DeleteTeamById, team.ID.String())
+	if assert.NoError(t, err) {
+		deleted := new(db.Team)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), deleted))
+		assert.Equal(t, team.ID, deleted.ID)
+	}
+
+	err, _, _ = DummyRequest(t, e, http.MethodDelete, "/api/teams/:id", "", teamRouter.DeleteTeamById, team.ID.String())
+	assert.Equal(t, handler.NotFoundError(handler.RecordNotFound), err)
+
+	_, err = userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
+
 func registerAndCreateTwoPlayers(t *testing.T, e *echo.Echo) (uuid.UUID, uuid.UUID, *uuid.UUID) {
 
 	user := DummyUser(t, e)
@@ -162,7 +184,7 @@ func DummyTeam(t *testing.T, e *echo.Echo, userId uuid.UUID) (db.Team, db.Player
 		PlayerOne: playerOne.ID,
 		PlayerTwo: &playerTwo.ID,
 		Name:      "Test Team one",
-		UserID:    userId,
+		ClubID:    userId,
 	}
 	team := addNewteam(t, e, input)
 	return team, playerOne, playerTwo
@@ -189,3 +211,37 @@ func addNewteam(t *testing.T, e *echo.Echo, data db.CreateTeamWithTwoPlayersPara
 
 	return *team
 }
+
+func TestListTeams(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+	team, _, _ := DummyTeam(t, e, user.ID)
+
+	list := func(query string) TeamPageResponse {
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/teams/"+user.ID.String()+"?"+query, nil), rec)
+		ctx.SetParamNames("clubId")
+		ctx.SetParamValues(user.ID.String())
+		assert.NoError(t, teamRouter.GetAllTeamsByClubId(ctx))
+		page := TeamPageResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return page
+	}
+
+	teams := list("hasSecondPlayer=true")
+	if assert.Len(t, teams.Data, 1) {
+		assert.Equal(t, team.ID, teams.Data[0].ID)
+	}
+	assert.Len(t, list("hasSecondPlayer=false").Data, 2)
+	assert.Len(t, list("name=test%20team").Data, 1)
+	assert.Len(t, list("createdAfter="+time.Now().Add(time.Hour).UTC().Format(time.RFC3339)).Data, 0)
+
+	first := list("sort=-createdAt&limit=2")
+	assert.Len(t, first.Data, 2)
+	second := list("sort=-createdAt&limit=2&cursor=" + first.NextCursor)
+	assert.Len(t, second.Data, 1)
+	assert.Empty(t, second.NextCursor)
+
+	_, err := userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type TwoFactorRouter struct {
+	TwoFactorHandler handler.TwoFactorHandler
+}
+
+type RecoveryCodesResponse struct {
+	Status        string   `json:"status"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func newTwoFactorRouter(h handler.TwoFactorHandler) *TwoFactorRouter {
+	return &TwoFactorRouter{TwoFactorHandler: h}
+}
+
+func (r *TwoFactorRouter) Enroll(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := r.TwoFactorHandler.Enroll(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, enrollment)
+}
+
+func (r *TwoFactorRouter) Confirm(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.TwoFactorCodeInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	codes, err := r.TwoFactorHandler.Confirm(ctx.Request().Context(), user, input.Code)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, RecoveryCodesResponse{Status: "success", RecoveryCodes: codes})
+}
+
+func (r *TwoFactorRouter) Disable(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	input := new(handler.TwoFactorCodeInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	err = r.TwoFactorHandler.Disable(ctx.Request().Context(), user, input.Code)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, MessageResponse{
+		Status:  "success",
+		Message: "Two-factor authentication is disabled.",
+	})
+}
+
+func RegisterTwoFactorRoute(baseUrl string, e *echo.Echo, r TwoFactorRouter, middleware Middleware) {
+	e.POST(baseUrl+"/account/2fa", r.Enroll, middleware.AuthMiddleware)
+	e.POST(baseUrl+"/account/2fa/confirm", r.Confirm, middleware.AuthMiddleware)
+	e.DELETE(baseUrl+"/account/2fa", r.Disable, middleware.AuthMiddleware)
+}
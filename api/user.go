@@ -1,7 +1,10 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -14,28 +17,55 @@ type UserRouter struct {
 	UserHandler handler.UserHandler
 }
 
+// AccountResponse is a user as the API shows it. The password hash never
+// leaves the server.
+type AccountResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
 type UserResponse struct {
-	Status string  `json:"status"`
-	Data   db.User `json:"data"`
+	Status string          `json:"status"`
+	Data   AccountResponse `json:"data"`
 }
 
 func newUserRouter(h handler.UserHandler) *UserRouter {
 	return &UserRouter{UserHandler: h}
 }
 
+func newAccountResponse(user db.User) AccountResponse {
+	response := AccountResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.EmailVerifiedAt.Valid {
+		response.EmailVerifiedAt = &user.EmailVerifiedAt.Time
+	}
+	return response
+}
+
 func (r *UserRouter) getUserById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	userId, err := uuid.Parse(id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return handler.ValidationError(err)
 	}
 	user, err := r.UserHandler.GetUserById(ctx.Request().Context(), userId)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	if errors.Is(err, sql.ErrNoRows) {
+		return handler.NotFoundError(handler.UserNotFound)
+	} else if err != nil {
+		return handler.DatabaseError(err)
 	}
 	res := UserResponse{
 		Status: "success",
-		Data:   user,
+		Data:   newAccountResponse(user),
 	}
 	return ctx.JSON(http.StatusOK, res)
 }
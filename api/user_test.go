@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+func TestResponsesHideSecrets(t *testing.T) {
+	verifiedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := db.User{
+		ID:              uuid.New(),
+		Username:        "laurin",
+		Email:           "laurin@test.de",
+		PasswordHash:    "$2a$10$secret",
+		EmailVerifiedAt: sql.NullTime{Time: verifiedAt, Valid: true},
+	}
+
+	encoded, err := json.Marshal(newAuthResponse(handler.ResponsePayload{AccessToken: "access", RefreshToken: "refresh", User: user}))
+	assert.NoError(t, err)
+
+	fields := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	account := fields["user"].(map[string]interface{})
+	assert.NotContains(t, account, "passwordHash")
+	assert.NotContains(t, string(encoded), user.PasswordHash)
+	assert.Equal(t, user.ID.String(), account["id"])
+	assert.Equal(t, verifiedAt.Format(time.RFC3339), account["emailVerifiedAt"])
+
+	encoded, err = json.Marshal(newTeamResponse(db.Team{ID: uuid.New(), PlayerOne: uuid.New()}))
+	assert.NoError(t, err)
+	fields = map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	for _, key := range []string{"id", "name", "clubId", "playerOne", "playerTwo", "createdAt", "updatedAt"} {
+		assert.Contains(t, fields, key)
+	}
+}
+
+func TestGetUserById(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+	userRouter := newUserRouter(*userHandler)
+
+	err, rec, _ := DummyRequest(t, e, http.MethodGet, "/api/users/:id", "", userRouter.getUserById, user.ID.String())
+	if assert.NoError(t, err) {
+		assert.Contains(t, rec.Body.String(), user.ID.String())
+	}
+
+	err, _, _ = DummyRequest(t, e, http.MethodGet, "/api/users/:id", "", userRouter.getUserById, uuid.NewString())
+	assert.Equal(t, handler.NotFoundError(handler.UserNotFound), err)
+
+	_, err = userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -27,27 +29,43 @@ var Cfg = config.Config{
 		TestUrl: "",
 	},
 	JWT: config.JwtConfig{
-		AccessToken:  "Test",
-		RefreshToken: "Test",
+		AccessToken: "Test",
+	},
+	SESSION: config.SessionConfig{
+		RefreshTokenLifetime: utils.OneMonth,
+	},
+	AUTH: config.AuthConfig{
+		PasswordResetLifetime:     time.Hour,
+		EmailVerificationLifetime: 48 * time.Hour,
+		ClubInvitationLifetime:    7 * 24 * time.Hour,
+		RequireVerifiedEmail:      config.RequireVerifiedNone,
 	},
 }
 
-func RegisterDummyUser(t *testing.T, e *echo.Echo, userData handler.RegisterInput, tokenGen *utils.MockTokenGenerator, durAcc time.Duration, durRef time.Duration) *handler.ResponsePayload {
-	var TestDb = utils.DbQueriesTest()
+// TestDb is the database of the api tests, it is kept in memory so they run
+// without Postgres.
+var TestDb = utils.NewDBQueriesMock()
+
+// TestMailer collects every mail the api tests send instead of delivering it.
+var TestMailer = &utils.LogMailer{Out: io.Discard}
+
+func RegisterDummyUser(t *testing.T, e *echo.Echo, userData handler.RegisterInput, tokenGen *utils.MockTokenGenerator, durAcc time.Duration) *AuthResponse {
 	var userHandler = handler.NewUserHandler(TestDb, Cfg)
-	var tokenHandler = handler.NewRefreshTokenHandler(TestDb, Cfg, tokenGen)
-	var authHandler = handler.NewAuthenticationHandler(TestDb, *userHandler, *tokenHandler, tokenGen)
-	var authRouter = NewAuthRouter(*userHandler, *tokenHandler, tokenGen, *authHandler)
+	var tokenHandler = handler.NewRefreshTokenHandler(TestDb, Cfg)
+	var verificationHandler = handler.NewEmailVerificationHandler(TestDb, Cfg, *userHandler, TestMailer)
+	var authHandler = handler.NewAuthenticationHandler(TestDb, *userHandler, *tokenHandler, tokenGen, *verificationHandler)
+	var loginThrottle = handler.NewLoginThrottleHandler(utils.NewMemoryLoginAttemptStore(), Cfg)
+	var twoFactorHandler = handler.NewTwoFactorHandler(TestDb, Cfg)
+	var authRouter = NewAuthRouter(*userHandler, *tokenHandler, tokenGen, *authHandler, *loginThrottle, *twoFactorHandler)
 
 	tokenGen.ExpiryDateAccess = durAcc
-	tokenGen.ExpiryDateRefresh = durRef
 	encodeUser, err := json.Marshal(userData)
 	assert.NoError(t, err, "Problem with encoding the user")
 
 	err, rec, _ := DummyRequest(t, e, http.MethodPost, "/api/register", string(encodeUser), authRouter.Register, "")
 	assert.NoError(t, err, "Problem with registering test user")
 
-	user := new(handler.ResponsePayload)
+	user := new(AuthResponse)
 	err = json.Unmarshal(rec.Body.Bytes(), user)
 	if err != nil {
 		t.Fatalf("Couldn't decode User %v", err)
@@ -60,11 +78,15 @@ func DummyUser(t *testing.T, e *echo.Echo) db.User {
 	testUserInput := handler.RegisterInput{
 		Username: "laurin",
 		Email:    "laurin@test.de",
-		Password: "Test",
-		Confirm:  "Test",
+		Password: "Test1234",
+		Confirm:  "Test1234",
 	}
-	user := RegisterDummyUser(t, e, testUserInput, &utils.MockTokenGenerator{}, 5*time.Minute, 5*time.Minute)
-	return user.User
+	registered := RegisterDummyUser(t, e, testUserInput, &utils.MockTokenGenerator{}, 5*time.Minute)
+
+	// the response leaves out the password hash, the routes need the whole user
+	user, err := TestDb.GetUserById(context.Background(), registered.User.ID)
+	assert.NoError(t, err, "Problem with loading the registered user")
+	return user
 }
 
 func DummyRequest(
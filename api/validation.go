@@ -0,0 +1,28 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+var RequestInvalid = errors.New("Request body or query parameters are not valid for this route")
+
+// requestValidator lets echo check inputs with the validate tags of their
+// fields.
+type requestValidator struct{}
+
+func (requestValidator) Validate(input interface{}) error {
+	return handler.Validate(input)
+}
+
+// bind reads the request into input and validates it, so routes only see
+// inputs that passed their rules.
+func bind(ctx echo.Context, input interface{}) error {
+	if err := ctx.Bind(input); err != nil {
+		return handler.ValidationError(RequestInvalid)
+	}
+	return ctx.Validate(input)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+type VerificationRouter struct {
+	VerificationHandler handler.EmailVerificationHandler
+}
+
+func newVerificationRouter(h handler.EmailVerificationHandler) *VerificationRouter {
+	return &VerificationRouter{VerificationHandler: h}
+}
+
+func (r *VerificationRouter) VerifyEmail(ctx echo.Context) (err error) {
+	input := new(handler.VerifyEmailInput)
+	if err = bind(ctx, input); err != nil {
+		return err
+	}
+
+	user, err := r.VerificationHandler.ConfirmVerification(ctx.Request().Context(), input.Token)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, UserResponse{
+		Status: "success",
+		Data:   newAccountResponse(user),
+	})
+}
+
+func (r *VerificationRouter) ResendVerification(ctx echo.Context) (err error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = r.VerificationHandler.SendVerification(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusAccepted, MessageResponse{
+		Status:  "success",
+		Message: "A new verification link is on its way.",
+	})
+}
+
+func RegisterVerificationRoute(baseUrl string, e *echo.Echo, r VerificationRouter, middleware Middleware) {
+	e.POST(baseUrl+"/verify-email", r.VerifyEmail)
+	e.POST(baseUrl+"/verify-email/resend", r.ResendVerification, middleware.AuthMiddleware)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+)
+
+var verificationRouter = newVerificationRouter(*verificationHandler)
+
+func TestVerifyEmailRoute(t *testing.T) {
+	e := newEcho()
+	user := DummyUser(t, e)
+	assert.False(t, user.EmailVerifiedAt.Valid, "new users should not be verified")
+
+	msg, ok := TestMailer.LastTo(user.Email)
+	if !assert.True(t, ok, "register should send a verification mail") {
+		return
+	}
+	_, token, _ := strings.Cut(msg.Body, "/verify-email?token=")
+	token = strings.Fields(token)[0]
+
+	t.Run("unknown token", func(t *testing.T) {
+		err, _, _ := DummyRequest(t, e, http.MethodPost, "/api/verify-email", `{"token":"unknown"}`, verificationRouter.VerifyEmail, "")
+		assert.Equal(t, handler.ValidationError(handler.VerificationTokenInvalid), err)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		err, rec, _ := DummyRequest(t, e, http.MethodPost, "/api/verify-email", `{"token":"`+token+`"}`, verificationRouter.VerifyEmail, "")
+		if assert.NoError(t, err) {
+			res := new(UserResponse)
+			err := json.Unmarshal(rec.Body.Bytes(), res)
+			assert.NoError(t, err, "Couldn't decode verified user")
+			assert.NotNil(t, res.Data.EmailVerifiedAt)
+		}
+	})
+
+	_, err := userHandler.DeleteUserById(context.Background(), user.ID)
+	assert.NoError(t, err)
+}
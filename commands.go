@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+// command is a subcommand of the binary, run with the arguments after its name.
+// Without a command the binary serves the api.
+type command struct {
+	usage string
+	run   func(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error
+}
+
+var commands = map[string]command{
+	"serve": {
+		usage: "serve",
+		run:   serve,
+	},
+	"migrate": {
+		usage: migrateUsage,
+		run:   migrateCommand,
+	},
+	"create-user": {
+		usage: createUserUsage,
+		run:   createUserCommand,
+	},
+	"reset-password": {
+		usage: resetPasswordUsage,
+		run:   resetPasswordCommand,
+	},
+	"list-users": {
+		usage: "list-users",
+		run:   listUsersCommand,
+	},
+	"recompute-stats": {
+		usage: "recompute-stats",
+		run:   recomputeStatsCommand,
+	},
+	"seed": {
+		usage: seedUsage,
+		run:   seedCommand,
+	},
+}
+
+// runCommand runs the subcommand named by args[0].
+func runCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], commandUsage())
+	}
+	if err := cmd.run(ctx, dbCon, cfg, args[1:]); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+func commandUsage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	usage := "usage:\n"
+	for _, name := range names {
+		usage += "  " + commands[name].usage + "\n"
+	}
+	return usage
+}
+
+const migrateUsage = "migrate up | down | to <version> | version"
+
+func migrateCommand(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	migrator, err := db.NewMigrator(dbCon)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("missing action, usage: %s", migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return fmt.Errorf("missing version, usage: %s", migrateUsage)
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("version %q isn't a number", args[1])
+		}
+		err = migrator.To(ctx, uint(version))
+	case "version":
+	default:
+		return fmt.Errorf("unknown action %q, usage: %s", args[0], migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "version %d of %d", version, migrator.Latest())
+	if dirty {
+		fmt.Fprint(os.Stdout, " (dirty)")
+	}
+	fmt.Fprintln(os.Stdout)
+	return nil
+}
+
+// checkSchema migrates the database if cfg asks for it and refuses to serve a
+// schema the queries of this binary don't know.
+func checkSchema(ctx context.Context, migrator *db.Migrator, cfg config.Config) error {
+	if cfg.DB.MigrateOnStart {
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	}
+
+	pending, err := migrator.Check(ctx)
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		log.Printf("%d migrations are pending, run `migrate up` or set DB_MIGRATE_ON_START\n", pending)
+	}
+	return nil
+}
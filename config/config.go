@@ -1,22 +1,120 @@
 package config
 
+import "time"
+
 type Config struct {
-	DB   DBConfig
-	JWT  JwtConfig
-	ECHO EchoConfig
+	DB      DBConfig
+	JWT     JwtConfig
+	SESSION SessionConfig
+	AUTH    AuthConfig
+	LOGIN   LoginConfig
+	MAIL    MailConfig
+	OIDC    OIDCConfig
+	ECHO    EchoConfig
 }
 
+// DBConfig.MigrateOnStart applies the migrations built into the binary before
+// the server starts. Without it the server only refuses to start against a
+// schema newer than the binary knows and logs the migrations still pending.
 type DBConfig struct {
-	Url     string `required:"true" split_words:"true"`
-	TestUrl string `required:"false" split_words:"true"`
+	Url            string `required:"true" split_words:"true"`
+	TestUrl        string `required:"false" split_words:"true"`
+	MigrateOnStart bool   `default:"false" split_words:"true"`
 }
 
+// JwtConfig selects how access tokens are signed. KeyFiles are PEM encoded
+// Ed25519 or RSA private keys, the first one signs and the rest only verify, so
+// a rotated out key keeps working until its tokens expire. Without key files
+// tokens are signed with the shared AccessToken secret (HS256).
 type JwtConfig struct {
-	AccessToken  string `required:"true" split_words:"true"`
-	RefreshToken string `required:"true" split_words:"true"`
+	AccessToken string   `split_words:"true"`
+	KeyFiles    []string `split_words:"true"`
+}
+
+// Values for SessionConfig.Mode. In "bearer" mode clients keep the tokens and
+// send them in the Authorization header, in "cookie" mode the server keeps them
+// in httpOnly cookies and protects state changing requests with a CSRF token.
+const (
+	SessionModeBearer = "bearer"
+	SessionModeCookie = "cookie"
+)
+
+// SessionConfig controls the sessions of logins. A rotated refresh token that
+// comes back within RefreshReuseGrace is taken for a concurrent refresh of two
+// tabs or a retry, later it revokes the session.
+type SessionConfig struct {
+	RefreshTokenLifetime time.Duration `default:"720h" split_words:"true"`
+	RefreshReuseGrace    time.Duration `default:"10s" split_words:"true"`
+	Mode                 string        `default:"bearer"`
+	CookieSecure         bool          `default:"true" split_words:"true"`
+	CookieDomain         string        `split_words:"true"`
+}
+
+// Values for AuthConfig.RequireVerifiedEmail. With "login" unverified users
+// can't log in at all, with "writes" they can only read their data.
+const (
+	RequireVerifiedNone   = "none"
+	RequireVerifiedLogin  = "login"
+	RequireVerifiedWrites = "writes"
+)
+
+type AuthConfig struct {
+	PasswordResetLifetime      time.Duration `default:"1h" split_words:"true"`
+	EmailVerificationLifetime  time.Duration `default:"48h" split_words:"true"`
+	RequireVerifiedEmail       string        `default:"none" split_words:"true"`
+	TwoFactorIssuer            string        `default:"Tennis Analysis" split_words:"true"`
+	TwoFactorChallengeLifetime time.Duration `default:"5m" split_words:"true"`
+	ClubInvitationLifetime     time.Duration `default:"168h" split_words:"true"`
+}
+
+// LoginConfig controls the brute-force protection of the login. After
+// FreeAttempts failures every further failure doubles the wait before the next
+// try, starting at BaseDelay and capped at MaxDelay. Reaching the lockout
+// threshold blocks the account (or IP) for LockoutDuration. A threshold of 0
+// disables the lockout. Failures older than AttemptWindow are forgotten.
+type LoginConfig struct {
+	Store              string        `default:"memory"`
+	FreeAttempts       int32         `default:"3" split_words:"true"`
+	IpFreeAttempts     int32         `default:"20" split_words:"true"`
+	BaseDelay          time.Duration `default:"1s" split_words:"true"`
+	MaxDelay           time.Duration `default:"5m" split_words:"true"`
+	LockoutThreshold   int32         `default:"10" split_words:"true"`
+	IpLockoutThreshold int32         `default:"100" split_words:"true"`
+	LockoutDuration    time.Duration `default:"15m" split_words:"true"`
+	AttemptWindow      time.Duration `default:"1h" split_words:"true"`
+}
+
+// MailConfig selects how mails are delivered. "smtp" sends them through the
+// configured server, "log" writes them to LogFile (or stdout) for development.
+type MailConfig struct {
+	Driver       string `default:"log"`
+	From         string `default:"no-reply@tennis.laurinnotemann.dev"`
+	SmtpHost     string `split_words:"true"`
+	SmtpPort     int    `default:"587" split_words:"true"`
+	SmtpUsername string `split_words:"true"`
+	SmtpPassword string `split_words:"true"`
+	LogFile      string `split_words:"true"`
+}
+
+// OIDCConfig enables the login through an OpenID Connect provider, it stays
+// off while Issuer is empty. The provider has to allow PublicUrl +
+// "/api/oidc/callback" as redirect uri.
+type OIDCConfig struct {
+	Issuer       string
+	ClientId     string   `split_words:"true"`
+	ClientSecret string   `split_words:"true"`
+	Scopes       []string `default:"openid,email,profile"`
+	ProviderName string   `default:"Single sign-on" split_words:"true"`
 }
 
+// EchoConfig.DrainDelay is how long the server keeps answering after SIGTERM
+// while /readyz already reports it as draining, so the load balancer stops
+// sending requests first. ShutdownTimeout is how long it then waits for the
+// requests in flight.
 type EchoConfig struct {
-	Port int `required:"true" split_words:"true"`
-  Host string `required:"true" split_words:"true"`
+	Port            int           `required:"true" split_words:"true"`
+	Host            string        `required:"true" split_words:"true"`
+	PublicUrl       string        `default:"http://localhost:3000" split_words:"true"`
+	DrainDelay      time.Duration `default:"0s" split_words:"true"`
+	ShutdownTimeout time.Duration `default:"15s" split_words:"true"`
 }
@@ -0,0 +1,288 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock that keeps two instances from migrating
+// at the same time.
+const migrationLock = 7446_2023
+
+var (
+	ErrSchemaNewer   = errors.New("the database schema is newer than this binary, update the binary")
+	ErrSchemaDirty   = errors.New("the last migration of the database failed halfway, fix it by hand and set the version")
+	ErrSchemaUnknown = errors.New("the database schema has a version this binary doesn't know")
+	ErrNoMigration   = errors.New("there is no migration with this version")
+)
+
+var migrationName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one step of the schema in db/migrations.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrations are the migrations built into the binary, oldest first.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	files := map[uint]int{}
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s isn't named version_name.up.sql or version_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(migrationFiles, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+		files[m.Version]++
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if files[m.Version] != 2 {
+			return nil, fmt.Errorf("migration %d_%s needs one up and one down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies the built in migrations. It keeps the version in the
+// schema_migrations table of the migrate CLI, so databases migrated with it
+// carry on where they are.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+func NewMigrator(conn *sql.DB) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: conn, Migrations: migrations}, nil
+}
+
+// Latest is the version of the newest migration, the schema the queries of
+// this binary are written for.
+func (m *Migrator) Latest() uint {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Version is the version the database is migrated to, 0 for an empty one.
+func (m *Migrator) Version(ctx context.Context) (version uint, dirty bool, err error) {
+	err = m.withConn(ctx, false, func(conn *sql.Conn) error {
+		version, dirty, err = currentVersion(ctx, conn)
+		return err
+	})
+	return version, dirty, err
+}
+
+// Check refuses a database that the queries of this binary can't work with:
+// one with a newer or unknown schema, or one a migration failed on. It
+// returns the number of migrations that are still to be applied.
+func (m *Migrator) Check(ctx context.Context) (pending int, err error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return m.pending(version, dirty)
+}
+
+func (m *Migrator) pending(version uint, dirty bool) (int, error) {
+	if dirty {
+		return 0, fmt.Errorf("version %d: %w", version, ErrSchemaDirty)
+	}
+	if version > m.Latest() {
+		return 0, fmt.Errorf("version %d, this binary knows up to %d: %w", version, m.Latest(), ErrSchemaNewer)
+	}
+	idx := m.index(version)
+	if version != 0 && idx == -1 {
+		return 0, fmt.Errorf("version %d: %w", version, ErrSchemaUnknown)
+	}
+	return len(m.Migrations) - idx - 1, nil
+}
+
+// Up applies every migration that is still to be applied.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	version, _, err := m.Version(ctx)
+	if err != nil || version == 0 {
+		return err
+	}
+	idx := m.index(version)
+	if idx < 1 {
+		return m.To(ctx, 0)
+	}
+	return m.To(ctx, m.Migrations[idx-1].Version)
+}
+
+// To migrates the database up or down to version, 0 reverts every migration.
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && m.index(version) == -1 {
+		return fmt.Errorf("version %d: %w", version, ErrNoMigration)
+	}
+
+	return m.withConn(ctx, true, func(conn *sql.Conn) error {
+		current, dirty, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if _, err := m.pending(current, dirty); err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if migration.Version <= current || migration.Version > version {
+				continue
+			}
+			if err := apply(ctx, conn, migration.Version, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+		}
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			migration := m.Migrations[i]
+			if migration.Version > current || migration.Version <= version {
+				continue
+			}
+			var previous uint
+			if i > 0 {
+				previous = m.Migrations[i-1].Version
+			}
+			if err := apply(ctx, conn, migration.Version, migration.Down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// index is the position of the migration with version, -1 if there is none.
+func (m *Migrator) index(version uint) int {
+	for i, migration := range m.Migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// withConn runs fn on one connection. With lock set it holds the migration
+// lock and makes sure the version table exists, otherwise it only reads.
+func (m *Migrator) withConn(ctx context.Context, lock bool, fn func(*sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if lock {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLock); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLock)
+
+		_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`)
+		if err != nil {
+			return err
+		}
+	}
+	return fn(conn)
+}
+
+// currentVersion is 0 for a database without the version table, one that was
+// never migrated.
+func currentVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	var pqErr *pq.Error
+	if errors.Is(err, sql.ErrNoRows) || errors.As(err, &pqErr) && pqErr.Code.Name() == "undefined_table" {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// apply runs the statements of one migration. The version is marked dirty
+// while they run, since the files manage their own transactions and a failure
+// can leave them half applied.
+func apply(ctx context.Context, conn *sql.Conn, version uint, statements string, after uint) error {
+	if err := setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	if strings.TrimSpace(statements) != "" {
+		if _, err := conn.ExecContext(ctx, statements); err != nil {
+			return err
+		}
+	}
+	return setVersion(ctx, conn, after, false)
+}
+
+func setVersion(ctx context.Context, conn *sql.Conn, version uint, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+	if version != 0 {
+		_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, int64(version), dirty)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
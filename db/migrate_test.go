@@ -0,0 +1,54 @@
+package db
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() = %v, want nil", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("Migrations() embedded no migrations")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != uint(i+1) {
+			t.Fatalf("migration %d_%s has version %d, want %d", migration.Version, migration.Name, migration.Version, i+1)
+		}
+	}
+}
+
+func TestMigratorPending(t *testing.T) {
+	migrator, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatalf("NewMigrator() = %v, want nil", err)
+	}
+	latest := migrator.Latest()
+	migrator.Migrations = append(migrator.Migrations[:2:2], migrator.Migrations[3:]...)
+
+	tests := []struct {
+		name    string
+		version uint
+		dirty   bool
+		pending int
+		err     error
+	}{
+		{"empty database", 0, false, int(latest) - 1, nil},
+		{"up to date", latest, false, 0, nil},
+		{"behind", latest - 2, false, 2, nil},
+		{"newer than the binary", latest + 1, false, 0, ErrSchemaNewer},
+		{"unknown version", 3, false, 0, ErrSchemaUnknown},
+		{"failed migration", latest, true, 0, ErrSchemaDirty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := migrator.pending(tt.version, tt.dirty)
+			if !errors.Is(err, tt.err) || pending != tt.pending {
+				t.Fatalf("pending(%d, %t) = %d, %v, want %d, %v", tt.version, tt.dirty, pending, err, tt.pending, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+BEGIN;
+  DROP INDEX IF EXISTS "refresh_tokens_user_id_idx";
+  ALTER TABLE "refresh_tokens" DROP COLUMN last_used_at;
+  ALTER TABLE "refresh_tokens" DROP COLUMN device_label;
+
+  -- keep only the most recently created session per user so the unique constraint can be restored
+  DELETE FROM "refresh_tokens" a
+  USING "refresh_tokens" b
+  WHERE a.user_id = b.user_id AND a.created_at < b.created_at;
+  ALTER TABLE "refresh_tokens" ADD CONSTRAINT "refresh_tokens_user_id_key" UNIQUE (user_id);
+
+  ALTER TABLE "users" ADD COLUMN refresh_token_id uuid;
+  ALTER TABLE "users" ADD CONSTRAINT "FK_Users.refresh_token_id" FOREIGN KEY (refresh_token_id) REFERENCES refresh_tokens(id) ON DELETE SET NULL;
+  UPDATE "users" SET refresh_token_id = (SELECT id FROM "refresh_tokens" WHERE user_id = users.id);
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+  ALTER TABLE "users" DROP CONSTRAINT "FK_Users.refresh_token_id";
+  ALTER TABLE "users" DROP COLUMN refresh_token_id;
+
+  ALTER TABLE "refresh_tokens" DROP CONSTRAINT "refresh_tokens_user_id_key";
+  ALTER TABLE "refresh_tokens" ADD COLUMN device_label text NOT NULL DEFAULT '';
+  ALTER TABLE "refresh_tokens" ADD COLUMN last_used_at timestamptz NOT NULL DEFAULT Now();
+  CREATE INDEX "refresh_tokens_user_id_idx" ON "refresh_tokens" (user_id);
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  DROP TABLE IF EXISTS "rotated_refresh_tokens";
+
+  DELETE FROM "refresh_tokens";
+  ALTER TABLE "refresh_tokens" RENAME COLUMN token_hash TO token;
+COMMIT;
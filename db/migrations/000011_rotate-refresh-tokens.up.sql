@@ -0,0 +1,13 @@
+BEGIN;
+  -- refresh tokens used to be JWTs, clients have to log in again to get an opaque one
+  DELETE FROM "refresh_tokens";
+  ALTER TABLE "refresh_tokens" RENAME COLUMN token TO token_hash;
+
+  CREATE TABLE "rotated_refresh_tokens" (
+    token_hash text NOT NULL,
+    session_id uuid NOT NULL,
+    rotated_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (token_hash),
+    CONSTRAINT "FK_Rotated_refresh_tokens.session_id" FOREIGN KEY (session_id) REFERENCES refresh_tokens(id) ON DELETE CASCADE
+  );
+COMMIT;
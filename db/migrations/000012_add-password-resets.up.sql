@@ -0,0 +1,13 @@
+BEGIN;
+  CREATE TABLE "password_resets" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    expiry_date timestamptz NOT NULL,
+    used_at timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Password_resets.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+COMMIT;
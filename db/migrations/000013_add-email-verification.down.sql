@@ -0,0 +1,5 @@
+BEGIN;
+  DROP TABLE IF EXISTS "email_verifications";
+
+  ALTER TABLE "users" DROP COLUMN IF EXISTS email_verified_at;
+COMMIT;
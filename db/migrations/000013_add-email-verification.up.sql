@@ -0,0 +1,17 @@
+BEGIN;
+  ALTER TABLE "users" ADD COLUMN email_verified_at timestamptz;
+
+  -- accounts that existed before verification was introduced are trusted
+  UPDATE "users" SET email_verified_at = created_at;
+
+  CREATE TABLE "email_verifications" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    expiry_date timestamptz NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Email_verifications.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+COMMIT;
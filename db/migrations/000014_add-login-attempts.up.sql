@@ -0,0 +1,10 @@
+BEGIN;
+  CREATE TABLE "login_attempts" (
+    key text NOT NULL,
+    failures integer NOT NULL DEFAULT 0,
+    last_failure_at timestamptz NOT NULL DEFAULT Now(),
+    blocked_until timestamptz NOT NULL DEFAULT Now(),
+
+    PRIMARY KEY (key)
+  );
+COMMIT;
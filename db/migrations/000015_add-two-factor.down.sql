@@ -0,0 +1,5 @@
+BEGIN;
+  DROP TABLE IF EXISTS "two_factor_challenges";
+  DROP TABLE IF EXISTS "recovery_codes";
+  DROP TABLE IF EXISTS "user_totp";
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+  CREATE TABLE "user_totp" (
+    user_id uuid NOT NULL,
+    secret text NOT NULL,
+    confirmed_at timestamptz,
+    last_used_step bigint NOT NULL DEFAULT 0,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (user_id),
+    CONSTRAINT "FK_User_totp.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+
+  CREATE TABLE "recovery_codes" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    code_hash text NOT NULL,
+    used_at timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Recovery_codes.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+  CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+
+  CREATE TABLE "two_factor_challenges" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    device_label text NOT NULL DEFAULT '',
+    attempts integer NOT NULL DEFAULT 0,
+    expiry_date timestamptz NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Two_factor_challenges.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+COMMIT;
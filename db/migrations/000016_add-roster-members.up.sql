@@ -0,0 +1,16 @@
+BEGIN;
+  CREATE TABLE "roster_members" (
+    owner_id uuid NOT NULL,
+    member_id uuid NOT NULL,
+    role text NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    updated_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (owner_id, member_id),
+    CONSTRAINT "FK_Roster_members.owner_id" FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "FK_Roster_members.member_id" FOREIGN KEY (member_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "CK_Roster_members_Role" CHECK (role IN ('coach', 'viewer')),
+    CONSTRAINT "CK_Roster_members_NotOwner" CHECK (owner_id <> member_id)
+  );
+  CREATE INDEX roster_members_member_id_idx ON roster_members (member_id);
+COMMIT;
@@ -0,0 +1,71 @@
+BEGIN;
+  -- data goes back to the first owner of its club, clubs without an owner
+  -- can't be mapped to a user and are dropped with their data
+  ALTER TABLE "teams" ADD COLUMN user_id uuid;
+  UPDATE teams SET user_id = (
+    SELECT user_id FROM club_members
+    WHERE club_members.club_id = teams.club_id AND role = 'owner'
+    ORDER BY created_at
+    LIMIT 1
+  );
+  DELETE FROM teams WHERE user_id IS NULL;
+  ALTER TABLE "teams" ALTER COLUMN user_id SET NOT NULL;
+  ALTER TABLE "teams" ADD CONSTRAINT "FK_Teams.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+  ALTER TABLE "matches" ADD COLUMN user_id uuid;
+  UPDATE matches SET user_id = (
+    SELECT user_id FROM club_members
+    WHERE club_members.club_id = matches.club_id AND role = 'owner'
+    ORDER BY created_at
+    LIMIT 1
+  );
+  DELETE FROM matches WHERE user_id IS NULL;
+  ALTER TABLE "matches" ALTER COLUMN user_id SET NOT NULL;
+  ALTER TABLE "matches" ADD CONSTRAINT "FK_Matches.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+  CREATE TABLE "roster_members" (
+    owner_id uuid NOT NULL,
+    member_id uuid NOT NULL,
+    role text NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    updated_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (owner_id, member_id),
+    CONSTRAINT "FK_Roster_members.owner_id" FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "FK_Roster_members.member_id" FOREIGN KEY (member_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "CK_Roster_members_Role" CHECK (role IN ('coach', 'viewer')),
+    CONSTRAINT "CK_Roster_members_NotOwner" CHECK (owner_id <> member_id)
+  );
+  CREATE INDEX roster_members_member_id_idx ON roster_members (member_id);
+
+  INSERT INTO roster_members (owner_id, member_id, role, created_at, updated_at)
+  SELECT club_id, user_id, role, created_at, updated_at FROM club_members
+  WHERE role IN ('coach', 'viewer') AND club_id IN (SELECT id FROM users);
+
+  DROP TRIGGER IF EXISTS trigger_delete_club_cascade ON clubs;
+  DROP FUNCTION IF EXISTS delete_club_cascade();
+
+  ALTER TABLE "teams" DROP COLUMN club_id;
+  ALTER TABLE "matches" DROP COLUMN club_id;
+
+  DROP TABLE IF EXISTS "club_invitations";
+  DROP TABLE IF EXISTS "club_members";
+  DROP TABLE IF EXISTS "clubs";
+
+  CREATE OR REPLACE FUNCTION delete_user_cascade()
+  RETURNS TRIGGER AS $$
+  BEGIN
+      DELETE FROM players
+      WHERE id IN (
+          SELECT player_one FROM teams WHERE user_id = OLD.id
+      ) OR id IN (
+          SELECT player_two FROM teams WHERE user_id = OLD.id
+      );
+
+      DELETE FROM teams
+      WHERE user_id = OLD.id;
+
+      RETURN OLD;
+  END;
+  $$ LANGUAGE plpgsql;
+COMMIT;
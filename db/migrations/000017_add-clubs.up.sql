@@ -0,0 +1,109 @@
+BEGIN;
+  CREATE TABLE "clubs" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    updated_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id)
+  );
+
+  CREATE TABLE "club_members" (
+    club_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role text NOT NULL,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    updated_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (club_id, user_id),
+    CONSTRAINT "FK_Club_members.club_id" FOREIGN KEY (club_id) REFERENCES clubs(id) ON DELETE CASCADE,
+    CONSTRAINT "FK_Club_members.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "CK_Club_members_Role" CHECK (role IN ('owner', 'coach', 'viewer'))
+  );
+  CREATE INDEX club_members_user_id_idx ON club_members (user_id);
+
+  CREATE TABLE "club_invitations" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    club_id uuid NOT NULL,
+    email text NOT NULL DEFAULT '',
+    role text NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    invited_by uuid,
+    expiry_date timestamptz NOT NULL,
+    accepted_at timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Club_invitations.club_id" FOREIGN KEY (club_id) REFERENCES clubs(id) ON DELETE CASCADE,
+    CONSTRAINT "FK_Club_invitations.invited_by" FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "CK_Club_invitations_Role" CHECK (role IN ('owner', 'coach', 'viewer'))
+  );
+  CREATE INDEX club_invitations_club_id_idx ON club_invitations (club_id);
+
+  -- every user gets a personal club that shares their id, so the data they
+  -- owned so far moves over by just renaming the column
+  INSERT INTO clubs (id, name, created_at)
+  SELECT id, username, created_at FROM users;
+
+  INSERT INTO club_members (club_id, user_id, role, created_at)
+  SELECT id, id, 'owner', created_at FROM users;
+
+  INSERT INTO club_members (club_id, user_id, role, created_at, updated_at)
+  SELECT owner_id, member_id, role, created_at, updated_at FROM roster_members;
+  DROP TABLE "roster_members";
+
+  ALTER TABLE "teams" ADD COLUMN club_id uuid;
+  UPDATE teams SET club_id = user_id;
+  ALTER TABLE "teams" ALTER COLUMN club_id SET NOT NULL;
+  ALTER TABLE "teams" ADD CONSTRAINT "FK_Teams.club_id" FOREIGN KEY (club_id) REFERENCES clubs(id) ON DELETE CASCADE;
+  CREATE INDEX teams_club_id_idx ON teams (club_id);
+
+  ALTER TABLE "matches" ADD COLUMN club_id uuid;
+  UPDATE matches SET club_id = user_id;
+  ALTER TABLE "matches" ALTER COLUMN club_id SET NOT NULL;
+  ALTER TABLE "matches" ADD CONSTRAINT "FK_Matches.club_id" FOREIGN KEY (club_id) REFERENCES clubs(id) ON DELETE CASCADE;
+  CREATE INDEX matches_club_id_idx ON matches (club_id);
+
+  -- players belong to the club through its teams, so they are cleaned up when
+  -- the club goes away
+  CREATE OR REPLACE FUNCTION delete_club_cascade()
+  RETURNS TRIGGER AS $$
+  BEGIN
+      DELETE FROM players
+      WHERE id IN (
+          SELECT player_one FROM teams WHERE club_id = OLD.id
+      ) OR id IN (
+          SELECT player_two FROM teams WHERE club_id = OLD.id
+      );
+
+      DELETE FROM teams
+      WHERE club_id = OLD.id;
+
+      RETURN OLD;
+  END;
+  $$ LANGUAGE plpgsql;
+
+  CREATE TRIGGER trigger_delete_club_cascade
+  BEFORE DELETE ON clubs
+  FOR EACH ROW EXECUTE FUNCTION delete_club_cascade();
+
+  -- a deleted user takes every club down that nobody else owns
+  CREATE OR REPLACE FUNCTION delete_user_cascade()
+  RETURNS TRIGGER AS $$
+  BEGIN
+      DELETE FROM clubs
+      WHERE id IN (
+          SELECT club_id FROM club_members
+          WHERE user_id = OLD.id AND role = 'owner'
+      ) AND NOT EXISTS (
+          SELECT 1 FROM club_members owners
+          WHERE owners.club_id = clubs.id AND owners.role = 'owner' AND owners.user_id <> OLD.id
+      );
+
+      RETURN OLD;
+  END;
+  $$ LANGUAGE plpgsql;
+
+  ALTER TABLE "teams" DROP COLUMN user_id;
+  ALTER TABLE "matches" DROP COLUMN user_id;
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+  CREATE TABLE "personal_access_tokens" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    scopes text[] NOT NULL,
+    last_used_at timestamptz,
+    expiry_date timestamptz,
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_Personal_access_tokens.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+  );
+  CREATE INDEX personal_access_tokens_user_id_idx ON personal_access_tokens (user_id);
+COMMIT;
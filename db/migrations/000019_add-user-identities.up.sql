@@ -0,0 +1,15 @@
+BEGIN;
+  CREATE TABLE "user_identities" (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    issuer text NOT NULL,
+    subject text NOT NULL,
+    email text NOT NULL DEFAULT '',
+
+    created_at timestamptz NOT NULL DEFAULT Now(),
+    PRIMARY KEY (id),
+    CONSTRAINT "FK_User_identities.user_id" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "user_identities_issuer_subject_unique" UNIQUE (issuer, subject)
+  );
+  CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
+COMMIT;
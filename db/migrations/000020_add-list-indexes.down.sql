@@ -0,0 +1,5 @@
+BEGIN;
+  DROP INDEX IF EXISTS teams_single_player_idx;
+  DROP INDEX IF EXISTS teams_club_id_created_at_idx;
+  DROP INDEX IF EXISTS teams_club_id_name_idx;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  CREATE INDEX teams_club_id_name_idx ON teams (club_id, name, id);
+  CREATE INDEX teams_club_id_created_at_idx ON teams (club_id, created_at, id);
+  CREATE INDEX teams_single_player_idx ON teams (player_one) WHERE player_two IS NULL;
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  DROP INDEX IF EXISTS matches_team_two_idx;
+  DROP INDEX IF EXISTS matches_team_one_idx;
+  DROP INDEX IF EXISTS teams_player_two_idx;
+COMMIT;
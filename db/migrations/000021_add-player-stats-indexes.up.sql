@@ -0,0 +1,5 @@
+BEGIN;
+  CREATE INDEX teams_player_two_idx ON teams (player_two);
+  CREATE INDEX matches_team_one_idx ON matches (team_one);
+  CREATE INDEX matches_team_two_idx ON matches (team_two);
+COMMIT;
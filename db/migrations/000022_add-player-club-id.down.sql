@@ -0,0 +1,7 @@
+BEGIN;
+  DROP VIEW IF EXISTS player_summaries;
+  DROP INDEX IF EXISTS players_club_id_created_at_idx;
+  DROP INDEX IF EXISTS players_club_id_last_name_idx;
+  DROP INDEX IF EXISTS players_club_id_first_name_idx;
+  ALTER TABLE "players" DROP COLUMN IF EXISTS club_id;
+COMMIT;
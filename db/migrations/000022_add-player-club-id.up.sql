@@ -0,0 +1,37 @@
+BEGIN;
+  -- every player is created together with their single player team, which
+  -- names the club they belong to
+  ALTER TABLE "players" ADD COLUMN club_id uuid;
+  UPDATE players SET club_id = teams.club_id
+  FROM teams
+  WHERE teams.player_one = players.id AND teams.player_two IS NULL;
+  ALTER TABLE "players" ALTER COLUMN club_id SET NOT NULL;
+  ALTER TABLE "players" ADD CONSTRAINT "FK_Players.club_id" FOREIGN KEY (club_id) REFERENCES clubs(id) ON DELETE CASCADE;
+
+  CREATE INDEX players_club_id_first_name_idx ON players (club_id, first_name, id);
+  CREATE INDEX players_club_id_last_name_idx ON players (club_id, last_name, id);
+  CREATE INDEX players_club_id_created_at_idx ON players (club_id, created_at, id);
+
+  -- the numbers a player list shows next to each player
+  CREATE VIEW player_summaries AS
+  SELECT
+    players.id,
+    players.first_name,
+    players.last_name,
+    players.created_at,
+    players.updated_at,
+    players.club_id,
+    stats.team_count,
+    stats.match_count,
+    stats.last_played_at
+  FROM players
+  CROSS JOIN LATERAL (
+    SELECT
+      COUNT(DISTINCT player_teams.id) FILTER (WHERE player_teams.player_two IS NOT NULL) AS team_count,
+      COUNT(DISTINCT matches.id) AS match_count,
+      MAX(matches.created_at) AS last_played_at
+    FROM teams player_teams
+    LEFT JOIN matches ON matches.team_one = player_teams.id OR matches.team_two = player_teams.id
+    WHERE player_teams.player_one = players.id OR player_teams.player_two = players.id
+  ) stats;
+COMMIT;
@@ -11,6 +11,41 @@ import (
 	"github.com/google/uuid"
 )
 
+type Club struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ClubInvitation struct {
+	ID         uuid.UUID
+	ClubID     uuid.UUID
+	Email      string
+	Role       string
+	TokenHash  string
+	InvitedBy  *uuid.UUID
+	ExpiryDate time.Time
+	AcceptedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type ClubMember struct {
+	ClubID    uuid.UUID
+	UserID    uuid.UUID
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type EmailVerification struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	TokenHash  string
+	ExpiryDate time.Time
+	CreatedAt  time.Time
+}
+
 type Game struct {
 	ID        uuid.UUID
 	ServerID  uuid.UUID
@@ -19,14 +54,41 @@ type Game struct {
 	UpdatedAt time.Time
 }
 
+type LoginAttempt struct {
+	Key           string
+	Failures      int32
+	LastFailureAt time.Time
+	BlockedUntil  time.Time
+}
+
 type Match struct {
 	ID           uuid.UUID
 	NumberOfSets sql.NullInt32
-	UserID       uuid.UUID
 	TeamOne      uuid.UUID
 	TeamTwo      uuid.UUID
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	ClubID       uuid.UUID
+}
+
+type PasswordReset struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	TokenHash  string
+	ExpiryDate time.Time
+	UsedAt     sql.NullTime
+	CreatedAt  time.Time
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	LastUsedAt sql.NullTime
+	ExpiryDate sql.NullTime
+	CreatedAt  time.Time
 }
 
 type Player struct {
@@ -35,6 +97,19 @@ type Player struct {
 	LastName  string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	ClubID    uuid.UUID
+}
+
+type PlayerSummary struct {
+	ID           uuid.UUID
+	FirstName    string
+	LastName     string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ClubID       uuid.UUID
+	TeamCount    int64
+	MatchCount   int64
+	LastPlayedAt sql.NullTime
 }
 
 type Point struct {
@@ -47,13 +122,29 @@ type Point struct {
 	PointsOrder sql.NullInt32
 }
 
+type RecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
 type RefreshToken struct {
-	ID         uuid.UUID
-	UserID     uuid.UUID
-	Token      string
-	ExpiryDate time.Time
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	TokenHash   string
+	ExpiryDate  time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeviceLabel string
+	LastUsedAt  time.Time
+}
+
+type RotatedRefreshToken struct {
+	TokenHash string
+	SessionID uuid.UUID
+	RotatedAt time.Time
 }
 
 type Set struct {
@@ -77,19 +168,46 @@ type Stat struct {
 type Team struct {
 	ID        uuid.UUID
 	Name      string
-	UserID    uuid.UUID
 	PlayerOne uuid.UUID
 	PlayerTwo *uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	ClubID    uuid.UUID
+}
+
+type TwoFactorChallenge struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	TokenHash   string
+	DeviceLabel string
+	Attempts    int32
+	ExpiryDate  time.Time
+	CreatedAt   time.Time
 }
 
 type User struct {
-	ID             uuid.UUID
-	Username       string
-	Email          string
-	PasswordHash   string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	RefreshTokenID *uuid.UUID
+	ID              uuid.UUID
+	Username        string
+	Email           string
+	PasswordHash    string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	EmailVerifiedAt sql.NullTime
+}
+
+type UserIdentity struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Issuer    string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+type UserTotp struct {
+	UserID       uuid.UUID
+	Secret       string
+	ConfirmedAt  sql.NullTime
+	LastUsedStep int64
+	CreatedAt    time.Time
 }
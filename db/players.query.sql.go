@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,7 +16,7 @@ import (
 const deletePlayerById = `-- name: DeletePlayerById :one
 DELETE FROM players
 WHERE id = $1
-RETURNING id, first_name, last_name, created_at, updated_at
+RETURNING id, first_name, last_name, created_at, updated_at, club_id
 `
 
 func (q *Queries) DeletePlayerById(ctx context.Context, id uuid.UUID) (Player, error) {
@@ -26,12 +28,27 @@ func (q *Queries) DeletePlayerById(ctx context.Context, id uuid.UUID) (Player, e
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
 
+const getClubIdByPlayerId = `-- name: GetClubIdByPlayerId :one
+SELECT club_id
+FROM teams
+WHERE player_one = $1 OR player_two = $1
+LIMIT 1
+`
+
+func (q *Queries) GetClubIdByPlayerId(ctx context.Context, playerOne uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getClubIdByPlayerId, playerOne)
+	var club_id uuid.UUID
+	err := row.Scan(&club_id)
+	return club_id, err
+}
+
 const getPlayerById = `-- name: GetPlayerById :one
-SELECT id, first_name, last_name, created_at, updated_at 
+SELECT id, first_name, last_name, created_at, updated_at, club_id 
 FROM players
 WHERE id = $1
 LIMIT 1
@@ -46,10 +63,425 @@ func (q *Queries) GetPlayerById(ctx context.Context, id uuid.UUID) (Player, erro
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
 
+const listPlayersByClubIdOrderByCreatedAt = `-- name: ListPlayersByClubIdOrderByCreatedAt :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (created_at, id) > ($6::timestamptz, $5::uuid))
+  ORDER BY created_at, id
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.created_at, player_summaries.id
+`
+
+type ListPlayersByClubIdOrderByCreatedAtParams struct {
+	ClubID          uuid.UUID
+	NamePrefix      string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	CursorID        *uuid.UUID
+	CursorCreatedAt time.Time
+	PageSize        int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByCreatedAt(ctx context.Context, arg ListPlayersByClubIdOrderByCreatedAtParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByCreatedAt,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersByClubIdOrderByCreatedAtDesc = `-- name: ListPlayersByClubIdOrderByCreatedAtDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (created_at, id) < ($6::timestamptz, $5::uuid))
+  ORDER BY created_at DESC, id DESC
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.created_at DESC, player_summaries.id DESC
+`
+
+type ListPlayersByClubIdOrderByCreatedAtDescParams struct {
+	ClubID          uuid.UUID
+	NamePrefix      string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	CursorID        *uuid.UUID
+	CursorCreatedAt time.Time
+	PageSize        int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByCreatedAtDesc(ctx context.Context, arg ListPlayersByClubIdOrderByCreatedAtDescParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByCreatedAtDesc,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersByClubIdOrderByFirstName = `-- name: ListPlayersByClubIdOrderByFirstName :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (first_name, id) > ($6::text, $5::uuid))
+  ORDER BY first_name, id
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.first_name, player_summaries.id
+`
+
+type ListPlayersByClubIdOrderByFirstNameParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByFirstName(ctx context.Context, arg ListPlayersByClubIdOrderByFirstNameParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByFirstName,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersByClubIdOrderByFirstNameDesc = `-- name: ListPlayersByClubIdOrderByFirstNameDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (first_name, id) < ($6::text, $5::uuid))
+  ORDER BY first_name DESC, id DESC
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.first_name DESC, player_summaries.id DESC
+`
+
+type ListPlayersByClubIdOrderByFirstNameDescParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByFirstNameDesc(ctx context.Context, arg ListPlayersByClubIdOrderByFirstNameDescParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByFirstNameDesc,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersByClubIdOrderByLastName = `-- name: ListPlayersByClubIdOrderByLastName :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (last_name, id) > ($6::text, $5::uuid))
+  ORDER BY last_name, id
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.last_name, player_summaries.id
+`
+
+type ListPlayersByClubIdOrderByLastNameParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByLastName(ctx context.Context, arg ListPlayersByClubIdOrderByLastNameParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByLastName,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersByClubIdOrderByLastNameDesc = `-- name: ListPlayersByClubIdOrderByLastNameDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = $1
+    AND ((first_name || ' ' || last_name) ILIKE ($2::text || '%') OR last_name ILIKE ($2::text || '%'))
+    AND ($3::timestamptz IS NULL OR created_at >= $3::timestamptz)
+    AND ($4::timestamptz IS NULL OR created_at < $4::timestamptz)
+    AND ($5::uuid IS NULL OR (last_name, id) < ($6::text, $5::uuid))
+  ORDER BY last_name DESC, id DESC
+  LIMIT $7
+)
+SELECT player_summaries.id, player_summaries.first_name, player_summaries.last_name, player_summaries.created_at, player_summaries.updated_at, player_summaries.club_id, player_summaries.team_count, player_summaries.match_count, player_summaries.last_played_at
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.last_name DESC, player_summaries.id DESC
+`
+
+type ListPlayersByClubIdOrderByLastNameDescParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListPlayersByClubIdOrderByLastNameDesc(ctx context.Context, arg ListPlayersByClubIdOrderByLastNameDescParams) ([]PlayerSummary, error) {
+	rows, err := q.db.QueryContext(ctx, listPlayersByClubIdOrderByLastNameDesc,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PlayerSummary
+	for rows.Next() {
+		var i PlayerSummary
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+			&i.TeamCount,
+			&i.MatchCount,
+			&i.LastPlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updatePlayerById = `-- name: UpdatePlayerById :one
 UPDATE players
 SET 
@@ -57,7 +489,7 @@ SET
   last_name = $2,
   updated_at = Now()
 WHERE id = $3
-RETURNING id, first_name, last_name, created_at, updated_at
+RETURNING id, first_name, last_name, created_at, updated_at, club_id
 `
 
 type UpdatePlayerByIdParams struct {
@@ -75,6 +507,7 @@ func (q *Queries) UpdatePlayerById(ctx context.Context, arg UpdatePlayerByIdPara
 		&i.LastName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
@@ -11,26 +11,101 @@ import (
 )
 
 type Querier interface {
+	AcceptClubInvitation(ctx context.Context, id uuid.UUID) (ClubInvitation, error)
+	BlockLoginAttempt(ctx context.Context, arg BlockLoginAttemptParams) error
+	ConfirmTotpByUserId(ctx context.Context, arg ConfirmTotpByUserIdParams) (UserTotp, error)
+	CountClubOwners(ctx context.Context, clubID uuid.UUID) (int64, error)
+	CountTwoFactorChallengeAttempt(ctx context.Context, id uuid.UUID) (TwoFactorChallenge, error)
+	CountUserResources(ctx context.Context, userID uuid.UUID) (CountUserResourcesRow, error)
+	CreateClub(ctx context.Context, arg CreateClubParams) (Club, error)
+	CreateClubInvitation(ctx context.Context, arg CreateClubInvitationParams) (ClubInvitation, error)
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
+	CreateGame(ctx context.Context, arg CreateGameParams) (Game, error)
+	CreateMatch(ctx context.Context, arg CreateMatchParams) (Match, error)
 	CreateNewTeamWithOnePlayer(ctx context.Context, arg CreateNewTeamWithOnePlayerParams) (Team, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreatePoint(ctx context.Context, arg CreatePointParams) (Point, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateSet(ctx context.Context, matchID uuid.UUID) (Set, error)
+	CreateStat(ctx context.Context, arg CreateStatParams) (Stat, error)
 	CreateTeamWithTwoPlayers(ctx context.Context, arg CreateTeamWithTwoPlayersParams) (Team, error)
-	CreateToken(ctx context.Context, arg CreateTokenParams) (User, error)
+	CreateToken(ctx context.Context, arg CreateTokenParams) (RefreshToken, error)
+	CreateTwoFactorChallenge(ctx context.Context, arg CreateTwoFactorChallengeParams) (TwoFactorChallenge, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	DeleteClubById(ctx context.Context, id uuid.UUID) (Club, error)
+	DeleteClubInvitation(ctx context.Context, arg DeleteClubInvitationParams) (ClubInvitation, error)
+	DeleteClubMember(ctx context.Context, arg DeleteClubMemberParams) (ClubMember, error)
+	DeleteEmailVerificationsByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteLoginAttempt(ctx context.Context, key string) error
+	DeleteOtherTokensByUserId(ctx context.Context, arg DeleteOtherTokensByUserIdParams) error
+	DeletePasswordResetsByUserId(ctx context.Context, userID uuid.UUID) error
+	DeletePersonalAccessToken(ctx context.Context, arg DeletePersonalAccessTokenParams) (PersonalAccessToken, error)
+	DeletePersonalAccessTokensByUserId(ctx context.Context, userID uuid.UUID) error
 	DeletePlayerById(ctx context.Context, id uuid.UUID) (Player, error)
+	DeleteRecoveryCodesByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteStaleLoginAttempts(ctx context.Context, arg DeleteStaleLoginAttemptsParams) error
 	DeleteTeamById(ctx context.Context, id uuid.UUID) (Team, error)
 	DeleteTokenByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteTotpByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteTwoFactorChallengeById(ctx context.Context, id uuid.UUID) error
 	DeleteUserById(ctx context.Context, id uuid.UUID) (User, error)
-	GetAllTeamsByUserId(ctx context.Context, userID uuid.UUID) ([]Team, error)
+	DeleteUserTokenById(ctx context.Context, arg DeleteUserTokenByIdParams) (RefreshToken, error)
+	GetAllTeamsByClubId(ctx context.Context, clubID uuid.UUID) ([]Team, error)
+	GetAllTokensByUserId(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error)
 	GetAllUsers(ctx context.Context) ([]User, error)
+	GetClubById(ctx context.Context, id uuid.UUID) (Club, error)
+	GetClubIdByPlayerId(ctx context.Context, playerOne uuid.UUID) (uuid.UUID, error)
+	GetClubInvitationByHash(ctx context.Context, tokenHash string) (ClubInvitation, error)
+	GetClubMember(ctx context.Context, arg GetClubMemberParams) (ClubMember, error)
+	GetClubMembersByClubId(ctx context.Context, clubID uuid.UUID) ([]GetClubMembersByClubIdRow, error)
+	GetClubsByUserId(ctx context.Context, userID uuid.UUID) ([]GetClubsByUserIdRow, error)
+	GetEmailVerificationByHash(ctx context.Context, tokenHash string) (EmailVerification, error)
+	GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	GetOpenClubInvitationsByClubId(ctx context.Context, clubID uuid.UUID) ([]ClubInvitation, error)
+	GetPasswordResetByHash(ctx context.Context, tokenHash string) (PasswordReset, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error)
+	GetPersonalAccessTokensByUserId(ctx context.Context, userID uuid.UUID) ([]PersonalAccessToken, error)
 	GetPlayerById(ctx context.Context, id uuid.UUID) (Player, error)
+	GetRotatedTokenByHash(ctx context.Context, tokenHash string) (RotatedRefreshToken, error)
 	GetTeamById(ctx context.Context, id uuid.UUID) (Team, error)
-	GetTokenByUserId(ctx context.Context, userID uuid.UUID) (RefreshToken, error)
+	GetTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetTokenById(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	GetTotpByUserId(ctx context.Context, userID uuid.UUID) (UserTotp, error)
+	GetTwoFactorChallengeByHash(ctx context.Context, tokenHash string) (TwoFactorChallenge, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	ListPlayersByClubIdOrderByCreatedAt(ctx context.Context, arg ListPlayersByClubIdOrderByCreatedAtParams) ([]PlayerSummary, error)
+	ListPlayersByClubIdOrderByCreatedAtDesc(ctx context.Context, arg ListPlayersByClubIdOrderByCreatedAtDescParams) ([]PlayerSummary, error)
+	ListPlayersByClubIdOrderByFirstName(ctx context.Context, arg ListPlayersByClubIdOrderByFirstNameParams) ([]PlayerSummary, error)
+	ListPlayersByClubIdOrderByFirstNameDesc(ctx context.Context, arg ListPlayersByClubIdOrderByFirstNameDescParams) ([]PlayerSummary, error)
+	ListPlayersByClubIdOrderByLastName(ctx context.Context, arg ListPlayersByClubIdOrderByLastNameParams) ([]PlayerSummary, error)
+	ListPlayersByClubIdOrderByLastNameDesc(ctx context.Context, arg ListPlayersByClubIdOrderByLastNameDescParams) ([]PlayerSummary, error)
+	ListTeamsByClubIdOrderByCreatedAt(ctx context.Context, arg ListTeamsByClubIdOrderByCreatedAtParams) ([]Team, error)
+	ListTeamsByClubIdOrderByCreatedAtDesc(ctx context.Context, arg ListTeamsByClubIdOrderByCreatedAtDescParams) ([]Team, error)
+	ListTeamsByClubIdOrderByName(ctx context.Context, arg ListTeamsByClubIdOrderByNameParams) ([]Team, error)
+	ListTeamsByClubIdOrderByNameDesc(ctx context.Context, arg ListTeamsByClubIdOrderByNameDescParams) ([]Team, error)
+	LockClubOwners(ctx context.Context, clubID uuid.UUID) ([]uuid.UUID, error)
+	RecomputeStats(ctx context.Context) (RecomputeStatsRow, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	RotateTokenById(ctx context.Context, arg RotateTokenByIdParams) (RefreshToken, error)
+	TouchPersonalAccessToken(ctx context.Context, id uuid.UUID) error
+	TouchTokenById(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	UpdateClubById(ctx context.Context, arg UpdateClubByIdParams) (Club, error)
 	UpdatePlayerById(ctx context.Context, arg UpdatePlayerByIdParams) (Player, error)
 	UpdateTeamById(ctx context.Context, arg UpdateTeamByIdParams) (Team, error)
-	UpdateTokenByUserId(ctx context.Context, arg UpdateTokenByUserIdParams) (RefreshToken, error)
 	UpdateUserById(ctx context.Context, arg UpdateUserByIdParams) (User, error)
+	UpdateUserPasswordById(ctx context.Context, arg UpdateUserPasswordByIdParams) (User, error)
+	UpdateUserProfileById(ctx context.Context, arg UpdateUserProfileByIdParams) (User, error)
+	UpsertClubMember(ctx context.Context, arg UpsertClubMemberParams) (ClubMember, error)
+	UpsertTotpSecret(ctx context.Context, arg UpsertTotpSecretParams) (UserTotp, error)
+	UsePasswordReset(ctx context.Context, id uuid.UUID) (PasswordReset, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseTotpStep(ctx context.Context, arg UseTotpStepParams) (UserTotp, error)
+	VerifyUserEmailById(ctx context.Context, id uuid.UUID) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,113 @@
+-- name: CreateClub :one
+INSERT INTO clubs (
+  id,
+  name
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetClubById :one
+SELECT *
+FROM clubs
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetClubsByUserId :many
+SELECT clubs.*, club_members.role
+FROM clubs
+JOIN club_members ON club_members.club_id = clubs.id
+WHERE club_members.user_id = $1
+ORDER BY club_members.created_at;
+
+-- name: UpdateClubById :one
+UPDATE clubs
+SET
+  name = $1,
+  updated_at = Now()
+WHERE id = $2
+RETURNING *;
+
+-- name: DeleteClubById :one
+DELETE FROM clubs
+WHERE id = $1
+RETURNING *;
+
+-- name: UpsertClubMember :one
+INSERT INTO club_members (
+  club_id,
+  user_id,
+  role
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (club_id, user_id) DO UPDATE
+SET
+  role = EXCLUDED.role,
+  updated_at = Now()
+RETURNING *;
+
+-- name: GetClubMember :one
+SELECT *
+FROM club_members
+WHERE club_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: GetClubMembersByClubId :many
+SELECT club_members.*, users.username
+FROM club_members
+JOIN users ON users.id = club_members.user_id
+WHERE club_members.club_id = $1
+ORDER BY users.username;
+
+-- name: CountClubOwners :one
+SELECT count(*)
+FROM club_members
+WHERE club_id = $1 AND role = 'owner';
+
+-- name: LockClubOwners :many
+SELECT user_id
+FROM club_members
+WHERE club_id = $1 AND role = 'owner'
+FOR UPDATE;
+
+-- name: DeleteClubMember :one
+DELETE FROM club_members
+WHERE club_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: CreateClubInvitation :one
+INSERT INTO club_invitations (
+  club_id,
+  email,
+  role,
+  token_hash,
+  invited_by,
+  expiry_date
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetClubInvitationByHash :one
+SELECT *
+FROM club_invitations
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: GetOpenClubInvitationsByClubId :many
+SELECT *
+FROM club_invitations
+WHERE club_id = $1 AND accepted_at IS NULL
+ORDER BY created_at;
+
+-- name: AcceptClubInvitation :one
+UPDATE club_invitations
+SET accepted_at = Now()
+WHERE id = $1 AND accepted_at IS NULL
+RETURNING *;
+
+-- name: DeleteClubInvitation :one
+DELETE FROM club_invitations
+WHERE id = $1 AND club_id = $2
+RETURNING *;
@@ -0,0 +1,19 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (
+  user_id,
+  token_hash,
+  expiry_date
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetEmailVerificationByHash :one
+SELECT *
+FROM email_verifications
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: DeleteEmailVerificationsByUserId :exec
+DELETE FROM email_verifications
+WHERE user_id = $1;
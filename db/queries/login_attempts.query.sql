@@ -0,0 +1,36 @@
+-- name: GetLoginAttempt :one
+SELECT *
+FROM login_attempts
+WHERE key = $1
+LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (
+  key,
+  failures,
+  last_failure_at
+) VALUES (
+  sqlc.arg(key), 1, sqlc.arg(failed_at)
+)
+ON CONFLICT (key) DO UPDATE
+SET
+  failures = CASE
+    WHEN login_attempts.last_failure_at < sqlc.arg(window_start) THEN 1
+    ELSE login_attempts.failures + 1
+  END,
+  last_failure_at = EXCLUDED.last_failure_at
+RETURNING *;
+
+-- name: BlockLoginAttempt :exec
+UPDATE login_attempts
+SET
+  blocked_until = $1
+WHERE key = $2;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts
+WHERE key = $1;
+
+-- name: DeleteStaleLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE last_failure_at < sqlc.arg(window_start) AND blocked_until <= sqlc.arg(now);
@@ -0,0 +1,45 @@
+-- name: CreateMatch :one
+INSERT INTO matches (
+  club_id,
+  team_one,
+  team_two,
+  number_of_sets
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+RETURNING *;
+
+-- name: CreateSet :one
+INSERT INTO sets (
+  match_id
+) VALUES (
+  $1
+)
+RETURNING *;
+
+-- name: CreateGame :one
+INSERT INTO games (
+  set_id,
+  server_id
+) VALUES (
+  $1,
+  $2
+)
+RETURNING *;
+
+-- name: CreatePoint :one
+INSERT INTO points (
+  game_id,
+  team_id,
+  value,
+  points_order
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+RETURNING *;
@@ -0,0 +1,26 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+  user_id,
+  token_hash,
+  expiry_date
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetPasswordResetByHash :one
+SELECT *
+FROM password_resets
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET
+  used_at = Now()
+WHERE id = $1 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeletePasswordResetsByUserId :exec
+DELETE FROM password_resets
+WHERE user_id = $1;
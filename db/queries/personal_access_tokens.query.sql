@@ -0,0 +1,38 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+  user_id,
+  name,
+  token_hash,
+  scopes,
+  expiry_date
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT *
+FROM personal_access_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: GetPersonalAccessTokensByUserId :many
+SELECT *
+FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens
+SET
+  last_used_at = Now()
+WHERE id = $1;
+
+-- name: DeletePersonalAccessToken :one
+DELETE FROM personal_access_tokens
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeletePersonalAccessTokensByUserId :exec
+DELETE FROM personal_access_tokens
+WHERE user_id = $1;
@@ -17,3 +17,111 @@ SET
   updated_at = Now()
 WHERE id = $3
 RETURNING *;
+
+-- name: GetClubIdByPlayerId :one
+SELECT club_id
+FROM teams
+WHERE player_one = $1 OR player_two = $1
+LIMIT 1;
+
+-- name: ListPlayersByClubIdOrderByFirstName :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (first_name, id) > (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+  ORDER BY first_name, id
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.first_name, player_summaries.id;
+
+-- name: ListPlayersByClubIdOrderByFirstNameDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (first_name, id) < (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+  ORDER BY first_name DESC, id DESC
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.first_name DESC, player_summaries.id DESC;
+
+-- name: ListPlayersByClubIdOrderByLastName :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (last_name, id) > (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+  ORDER BY last_name, id
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.last_name, player_summaries.id;
+
+-- name: ListPlayersByClubIdOrderByLastNameDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (last_name, id) < (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+  ORDER BY last_name DESC, id DESC
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.last_name DESC, player_summaries.id DESC;
+
+-- name: ListPlayersByClubIdOrderByCreatedAt :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (created_at, id) > (@cursor_created_at::timestamptz, sqlc.narg(cursor_id)::uuid))
+  ORDER BY created_at, id
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.created_at, player_summaries.id;
+
+-- name: ListPlayersByClubIdOrderByCreatedAtDesc :many
+WITH page AS (
+  SELECT id
+  FROM players
+  WHERE club_id = @club_id
+    AND ((first_name || ' ' || last_name) ILIKE (@name_prefix::text || '%') OR last_name ILIKE (@name_prefix::text || '%'))
+    AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+    AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+    AND (sqlc.narg(cursor_id)::uuid IS NULL OR (created_at, id) < (@cursor_created_at::timestamptz, sqlc.narg(cursor_id)::uuid))
+  ORDER BY created_at DESC, id DESC
+  LIMIT @page_size
+)
+SELECT player_summaries.*
+FROM player_summaries
+JOIN page ON page.id = player_summaries.id
+ORDER BY player_summaries.created_at DESC, player_summaries.id DESC;
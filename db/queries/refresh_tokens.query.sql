@@ -1,38 +1,78 @@
 -- name: CreateToken :one
-WITH new_token AS (
-    INSERT INTO refresh_tokens (
-        user_id,
-        token,
-        expiry_date
-    ) VALUES (
-        sqlc.arg(user_id),  
-        $1,  
-        $2
-    )
-    RETURNING id
+INSERT INTO refresh_tokens (
+  user_id,
+  token_hash,
+  expiry_date,
+  device_label
+) VALUES (
+  $1, $2, $3, $4
 )
-UPDATE users
-SET 
-  refresh_token_id = (SELECT id FROM new_token),
-  updated_at = Now()
-WHERE users.id = sqlc.arg(user_id)
 RETURNING *;
 
--- name: GetTokenByUserId :one
-SELECT * 
+-- name: GetTokenById :one
+SELECT *
 FROM refresh_tokens
-WHERE user_id = $1
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetTokenByHash :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = $1
 LIMIT 1;
 
--- name: UpdateTokenByUserId :one
+-- name: GetRotatedTokenByHash :one
+SELECT *
+FROM rotated_refresh_tokens
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: GetAllTokensByUserId :many
+SELECT *
+FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY last_used_at DESC;
+
+-- name: RotateTokenById :one
+WITH current AS (
+  SELECT id, token_hash
+  FROM refresh_tokens
+  WHERE id = sqlc.arg(id) AND token_hash = sqlc.arg(old_token_hash)
+  FOR UPDATE
+), rotated AS (
+  INSERT INTO rotated_refresh_tokens (
+    token_hash,
+    session_id
+  )
+  SELECT token_hash, id
+  FROM current
+)
 UPDATE refresh_tokens
 SET
-  token = $1,
-  expiry_date = $2,
+  token_hash = sqlc.arg(token_hash),
+  expiry_date = sqlc.arg(expiry_date),
+  last_used_at = Now(),
   updated_at = Now()
-WHERE user_id = sqlc.arg(user_id)
+FROM current
+WHERE refresh_tokens.id = current.id
+RETURNING refresh_tokens.*;
+
+-- name: TouchTokenById :one
+UPDATE refresh_tokens
+SET
+  last_used_at = Now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUserTokenById :one
+DELETE FROM refresh_tokens
+WHERE id = $1 AND user_id = $2
 RETURNING *;
 
 -- name: DeleteTokenByUserId :exec
 DELETE FROM refresh_tokens
 WHERE user_id = $1;
+
+-- name: DeleteOtherTokensByUserId :exec
+DELETE FROM refresh_tokens
+WHERE user_id = $1 AND id <> $2;
@@ -0,0 +1,73 @@
+-- name: CreateStat :one
+INSERT INTO stats (
+  game_id,
+  team_id,
+  aces,
+  double_faults,
+  net_points
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+RETURNING *;
+
+-- name: RecomputeStats :one
+WITH running AS (
+  SELECT
+    points.game_id,
+    points.team_id,
+    matches.team_one,
+    matches.team_two,
+    count(*) FILTER (WHERE points.team_id = matches.team_one) OVER game_so_far AS team_one_points,
+    count(*) FILTER (WHERE points.team_id = matches.team_two) OVER game_so_far AS team_two_points
+  FROM points
+  JOIN games ON games.id = points.game_id
+  JOIN sets ON sets.id = games.set_id
+  JOIN matches ON matches.id = sets.match_id
+  WINDOW game_so_far AS (
+    PARTITION BY points.game_id
+    ORDER BY points.points_order NULLS LAST, points.created_at, points.id
+    ROWS UNBOUNDED PRECEDING
+  )
+), computed AS (
+  SELECT
+    games.id AS game_id,
+    game_teams.team_id,
+    count(running.game_id) FILTER (
+      WHERE running.team_one_points = running.team_two_points AND running.team_one_points >= 3
+    )::int AS deuce,
+    count(running.game_id) FILTER (
+      WHERE running.team_id = game_teams.team_id
+        AND running.team_one_points - (running.team_id = running.team_one)::int >= 3
+        AND running.team_two_points - (running.team_id = running.team_two)::int >= 3
+    )::int AS points_won_deuce
+  FROM games
+  JOIN sets ON sets.id = games.set_id
+  JOIN matches ON matches.id = sets.match_id
+  CROSS JOIN LATERAL (VALUES (matches.team_one), (matches.team_two)) AS game_teams (team_id)
+  LEFT JOIN running ON running.game_id = games.id
+  GROUP BY games.id, game_teams.team_id
+), updated AS (
+  UPDATE stats
+  SET
+    deuce = computed.deuce,
+    points_won_deuce = computed.points_won_deuce
+  FROM computed
+  WHERE stats.game_id = computed.game_id AND stats.team_id = computed.team_id
+  RETURNING stats.game_id, stats.team_id
+), inserted AS (
+  INSERT INTO stats (game_id, team_id, deuce, points_won_deuce)
+  SELECT computed.game_id, computed.team_id, computed.deuce, computed.points_won_deuce
+  FROM computed
+  WHERE NOT EXISTS (
+    SELECT 1 FROM updated
+    WHERE updated.game_id = computed.game_id AND updated.team_id = computed.team_id
+  )
+  RETURNING stats.game_id
+)
+SELECT
+  (SELECT count(*) FROM updated) AS updated,
+  (SELECT count(*) FROM inserted) AS inserted;
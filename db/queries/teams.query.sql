@@ -2,16 +2,18 @@
 WITH new_player AS (
   INSERT INTO players (
     first_name,
-    last_name
+    last_name,
+    club_id
   ) VALUES (
     $1,
-    $2
+    $2,
+    $4
   )
   RETURNING id
 )
 INSERT INTO teams (
   name,
-  user_id,
+  club_id,
   player_one
 ) VALUES (
   $3,
@@ -23,7 +25,7 @@ RETURNING *;
 -- name: CreateTeamWithTwoPlayers :one
 INSERT INTO teams (
   name,
-  user_id,
+  club_id,
   player_one,
   player_two
 ) VALUES (
@@ -45,10 +47,10 @@ DELETE FROM teams
 WHERE id = $1
 RETURNING *;
 
--- name: GetAllTeamsByUserId :many
+-- name: GetAllTeamsByClubId :many
 SELECT *
 FROM teams
-WHERE user_id = $1;
+WHERE club_id = $1;
 
 -- name: UpdateTeamById :one
 UPDATE teams
@@ -60,3 +62,50 @@ SET
 WHERE id = $4
 RETURNING *;
 
+-- name: ListTeamsByClubIdOrderByName :many
+SELECT *
+FROM teams
+WHERE club_id = @club_id
+  AND name ILIKE (@name_prefix::text || '%')
+  AND (sqlc.narg(has_player_two)::boolean IS NULL OR (player_two IS NOT NULL) = sqlc.narg(has_player_two)::boolean)
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL OR (name, id) > (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+ORDER BY name, id
+LIMIT @page_size;
+
+-- name: ListTeamsByClubIdOrderByNameDesc :many
+SELECT *
+FROM teams
+WHERE club_id = @club_id
+  AND name ILIKE (@name_prefix::text || '%')
+  AND (sqlc.narg(has_player_two)::boolean IS NULL OR (player_two IS NOT NULL) = sqlc.narg(has_player_two)::boolean)
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL OR (name, id) < (@cursor_name::text, sqlc.narg(cursor_id)::uuid))
+ORDER BY name DESC, id DESC
+LIMIT @page_size;
+
+-- name: ListTeamsByClubIdOrderByCreatedAt :many
+SELECT *
+FROM teams
+WHERE club_id = @club_id
+  AND name ILIKE (@name_prefix::text || '%')
+  AND (sqlc.narg(has_player_two)::boolean IS NULL OR (player_two IS NOT NULL) = sqlc.narg(has_player_two)::boolean)
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL OR (created_at, id) > (@cursor_created_at::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at, id
+LIMIT @page_size;
+
+-- name: ListTeamsByClubIdOrderByCreatedAtDesc :many
+SELECT *
+FROM teams
+WHERE club_id = @club_id
+  AND name ILIKE (@name_prefix::text || '%')
+  AND (sqlc.narg(has_player_two)::boolean IS NULL OR (player_two IS NOT NULL) = sqlc.narg(has_player_two)::boolean)
+  AND (sqlc.narg(created_after)::timestamptz IS NULL OR created_at >= sqlc.narg(created_after)::timestamptz)
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR created_at < sqlc.narg(created_before)::timestamptz)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL OR (created_at, id) < (@cursor_created_at::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
@@ -0,0 +1,87 @@
+-- name: UpsertTotpSecret :one
+INSERT INTO user_totp (
+  user_id,
+  secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  secret = EXCLUDED.secret,
+  confirmed_at = NULL,
+  last_used_step = 0,
+  created_at = Now()
+RETURNING *;
+
+-- name: GetTotpByUserId :one
+SELECT *
+FROM user_totp
+WHERE user_id = $1
+LIMIT 1;
+
+-- name: ConfirmTotpByUserId :one
+UPDATE user_totp
+SET
+  confirmed_at = Now(),
+  last_used_step = $2
+WHERE user_id = $1
+RETURNING *;
+
+-- name: UseTotpStep :one
+UPDATE user_totp
+SET
+  last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2
+RETURNING *;
+
+-- name: DeleteTotpByUserId :exec
+DELETE FROM user_totp
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+  user_id,
+  code_hash
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET
+  used_at = Now()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeleteRecoveryCodesByUserId :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: CreateTwoFactorChallenge :one
+INSERT INTO two_factor_challenges (
+  user_id,
+  token_hash,
+  device_label,
+  expiry_date
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetTwoFactorChallengeByHash :one
+SELECT *
+FROM two_factor_challenges
+WHERE token_hash = $1
+LIMIT 1;
+
+-- name: CountTwoFactorChallengeAttempt :one
+UPDATE two_factor_challenges
+SET
+  attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTwoFactorChallengeById :exec
+DELETE FROM two_factor_challenges
+WHERE id = $1;
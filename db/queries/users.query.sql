@@ -45,3 +45,49 @@ SET
 WHERE id = $4
 RETURNING *;
 
+
+-- name: UpdateUserPasswordById :one
+UPDATE users
+SET
+  password_hash = $1,
+  updated_at = Now()
+WHERE id = $2
+RETURNING *;
+
+-- name: VerifyUserEmailById :one
+UPDATE users
+SET
+  email_verified_at = Now(),
+  updated_at = Now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserProfileById :one
+UPDATE users
+SET
+  username = $1,
+  email = $2,
+  email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
+  updated_at = Now()
+WHERE id = $3
+RETURNING *;
+
+-- name: CountUserResources :one
+WITH sole_clubs AS (
+  SELECT club_id AS id
+  FROM club_members
+  WHERE club_members.user_id = $1 AND club_members.role = 'owner' AND NOT EXISTS (
+    SELECT 1 FROM club_members owners
+    WHERE owners.club_id = club_members.club_id AND owners.role = 'owner' AND owners.user_id <> $1
+  )
+)
+SELECT
+  (SELECT count(*) FROM sole_clubs) AS clubs,
+  (SELECT count(*) FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)) AS teams,
+  (SELECT count(*) FROM players WHERE players.id IN (
+    SELECT player_one FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)
+    UNION
+    SELECT player_two FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)
+  )) AS players,
+  (SELECT count(*) FROM matches WHERE matches.club_id IN (SELECT id FROM sole_clubs)) AS matches,
+  (SELECT count(*) FROM refresh_tokens WHERE refresh_tokens.user_id = $1) AS sessions;
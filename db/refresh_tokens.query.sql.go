@@ -13,47 +13,60 @@ import (
 )
 
 const createToken = `-- name: CreateToken :one
-WITH new_token AS (
-    INSERT INTO refresh_tokens (
-        user_id,
-        token,
-        expiry_date
-    ) VALUES (
-        $3,  
-        $1,  
-        $2
-    )
-    RETURNING id
+INSERT INTO refresh_tokens (
+  user_id,
+  token_hash,
+  expiry_date,
+  device_label
+) VALUES (
+  $1, $2, $3, $4
 )
-UPDATE users
-SET 
-  refresh_token_id = (SELECT id FROM new_token),
-  updated_at = Now()
-WHERE users.id = $3
-RETURNING id, username, email, password_hash, created_at, updated_at, refresh_token_id
+RETURNING id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
 `
 
 type CreateTokenParams struct {
-	Token      string
-	ExpiryDate time.Time
-	UserID     uuid.UUID
+	UserID      uuid.UUID
+	TokenHash   string
+	ExpiryDate  time.Time
+	DeviceLabel string
 }
 
-func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createToken, arg.Token, arg.ExpiryDate, arg.UserID)
-	var i User
+func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, createToken,
+		arg.UserID,
+		arg.TokenHash,
+		arg.ExpiryDate,
+		arg.DeviceLabel,
+	)
+	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
-		&i.Username,
-		&i.Email,
-		&i.PasswordHash,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiryDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
+const deleteOtherTokensByUserId = `-- name: DeleteOtherTokensByUserId :exec
+DELETE FROM refresh_tokens
+WHERE user_id = $1 AND id <> $2
+`
+
+type DeleteOtherTokensByUserIdParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) DeleteOtherTokensByUserId(ctx context.Context, arg DeleteOtherTokensByUserIdParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherTokensByUserId, arg.UserID, arg.ID)
+	return err
+}
+
 const deleteTokenByUserId = `-- name: DeleteTokenByUserId :exec
 DELETE FROM refresh_tokens
 WHERE user_id = $1
@@ -64,53 +77,205 @@ func (q *Queries) DeleteTokenByUserId(ctx context.Context, userID uuid.UUID) err
 	return err
 }
 
-const getTokenByUserId = `-- name: GetTokenByUserId :one
-SELECT id, user_id, token, expiry_date, created_at, updated_at 
+const deleteUserTokenById = `-- name: DeleteUserTokenById :one
+DELETE FROM refresh_tokens
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
+`
+
+type DeleteUserTokenByIdParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteUserTokenById(ctx context.Context, arg DeleteUserTokenByIdParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, deleteUserTokenById, arg.ID, arg.UserID)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiryDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getAllTokensByUserId = `-- name: GetAllTokensByUserId :many
+SELECT id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
 FROM refresh_tokens
 WHERE user_id = $1
+ORDER BY last_used_at DESC
+`
+
+func (q *Queries) GetAllTokensByUserId(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, getAllTokensByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TokenHash,
+			&i.ExpiryDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeviceLabel,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRotatedTokenByHash = `-- name: GetRotatedTokenByHash :one
+SELECT token_hash, session_id, rotated_at
+FROM rotated_refresh_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetRotatedTokenByHash(ctx context.Context, tokenHash string) (RotatedRefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getRotatedTokenByHash, tokenHash)
+	var i RotatedRefreshToken
+	err := row.Scan(&i.TokenHash, &i.SessionID, &i.RotatedAt)
+	return i, err
+}
+
+const getTokenByHash = `-- name: GetTokenByHash :one
+SELECT id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
+FROM refresh_tokens
+WHERE token_hash = $1
+LIMIT 1
+`
+
+func (q *Queries) GetTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getTokenByHash, tokenHash)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiryDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getTokenById = `-- name: GetTokenById :one
+SELECT id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
+FROM refresh_tokens
+WHERE id = $1
 LIMIT 1
 `
 
-func (q *Queries) GetTokenByUserId(ctx context.Context, userID uuid.UUID) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, getTokenByUserId, userID)
+func (q *Queries) GetTokenById(ctx context.Context, id uuid.UUID) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getTokenById, id)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.Token,
+		&i.TokenHash,
 		&i.ExpiryDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
-const updateTokenByUserId = `-- name: UpdateTokenByUserId :one
+const rotateTokenById = `-- name: RotateTokenById :one
+WITH current AS (
+  SELECT id, token_hash
+  FROM refresh_tokens
+  WHERE id = $1 AND token_hash = $2
+  FOR UPDATE
+), rotated AS (
+  INSERT INTO rotated_refresh_tokens (
+    token_hash,
+    session_id
+  )
+  SELECT token_hash, id
+  FROM current
+)
 UPDATE refresh_tokens
 SET
-  token = $1,
-  expiry_date = $2,
+  token_hash = $3,
+  expiry_date = $4,
+  last_used_at = Now(),
   updated_at = Now()
-WHERE user_id = $3
-RETURNING id, user_id, token, expiry_date, created_at, updated_at
+FROM current
+WHERE refresh_tokens.id = current.id
+RETURNING refresh_tokens.id, refresh_tokens.user_id, refresh_tokens.token_hash, refresh_tokens.expiry_date, refresh_tokens.created_at, refresh_tokens.updated_at, refresh_tokens.device_label, refresh_tokens.last_used_at
 `
 
-type UpdateTokenByUserIdParams struct {
-	Token      string
-	ExpiryDate time.Time
-	UserID     uuid.UUID
+type RotateTokenByIdParams struct {
+	ID           uuid.UUID
+	OldTokenHash string
+	TokenHash    string
+	ExpiryDate   time.Time
+}
+
+func (q *Queries) RotateTokenById(ctx context.Context, arg RotateTokenByIdParams) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, rotateTokenById,
+		arg.ID,
+		arg.OldTokenHash,
+		arg.TokenHash,
+		arg.ExpiryDate,
+	)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiryDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
+	)
+	return i, err
 }
 
-func (q *Queries) UpdateTokenByUserId(ctx context.Context, arg UpdateTokenByUserIdParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, updateTokenByUserId, arg.Token, arg.ExpiryDate, arg.UserID)
+const touchTokenById = `-- name: TouchTokenById :one
+UPDATE refresh_tokens
+SET
+  last_used_at = Now()
+WHERE id = $1
+RETURNING id, user_id, token_hash, expiry_date, created_at, updated_at, device_label, last_used_at
+`
+
+func (q *Queries) TouchTokenById(ctx context.Context, id uuid.UUID) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, touchTokenById, id)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.Token,
+		&i.TokenHash,
 		&i.ExpiryDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeviceLabel,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -15,30 +17,32 @@ const createNewTeamWithOnePlayer = `-- name: CreateNewTeamWithOnePlayer :one
 WITH new_player AS (
   INSERT INTO players (
     first_name,
-    last_name
+    last_name,
+    club_id
   ) VALUES (
     $1,
-    $2
+    $2,
+    $4
   )
   RETURNING id
 )
 INSERT INTO teams (
   name,
-  user_id,
+  club_id,
   player_one
 ) VALUES (
   $3,
   $4,
   (SELECT id FROM new_player)
 )
-RETURNING id, name, user_id, player_one, player_two, created_at, updated_at
+RETURNING id, name, player_one, player_two, created_at, updated_at, club_id
 `
 
 type CreateNewTeamWithOnePlayerParams struct {
 	FirstName string
 	LastName  string
 	Name      string
-	UserID    uuid.UUID
+	ClubID    uuid.UUID
 }
 
 func (q *Queries) CreateNewTeamWithOnePlayer(ctx context.Context, arg CreateNewTeamWithOnePlayerParams) (Team, error) {
@@ -46,17 +50,17 @@ func (q *Queries) CreateNewTeamWithOnePlayer(ctx context.Context, arg CreateNewT
 		arg.FirstName,
 		arg.LastName,
 		arg.Name,
-		arg.UserID,
+		arg.ClubID,
 	)
 	var i Team
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.UserID,
 		&i.PlayerOne,
 		&i.PlayerTwo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
@@ -64,7 +68,7 @@ func (q *Queries) CreateNewTeamWithOnePlayer(ctx context.Context, arg CreateNewT
 const createTeamWithTwoPlayers = `-- name: CreateTeamWithTwoPlayers :one
 INSERT INTO teams (
   name,
-  user_id,
+  club_id,
   player_one,
   player_two
 ) VALUES (
@@ -73,12 +77,12 @@ INSERT INTO teams (
   $3,
   $4
 )
-RETURNING id, name, user_id, player_one, player_two, created_at, updated_at
+RETURNING id, name, player_one, player_two, created_at, updated_at, club_id
 `
 
 type CreateTeamWithTwoPlayersParams struct {
 	Name      string
-	UserID    uuid.UUID
+	ClubID    uuid.UUID
 	PlayerOne uuid.UUID
 	PlayerTwo *uuid.UUID
 }
@@ -86,7 +90,7 @@ type CreateTeamWithTwoPlayersParams struct {
 func (q *Queries) CreateTeamWithTwoPlayers(ctx context.Context, arg CreateTeamWithTwoPlayersParams) (Team, error) {
 	row := q.db.QueryRowContext(ctx, createTeamWithTwoPlayers,
 		arg.Name,
-		arg.UserID,
+		arg.ClubID,
 		arg.PlayerOne,
 		arg.PlayerTwo,
 	)
@@ -94,11 +98,11 @@ func (q *Queries) CreateTeamWithTwoPlayers(ctx context.Context, arg CreateTeamWi
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.UserID,
 		&i.PlayerOne,
 		&i.PlayerTwo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
@@ -106,7 +110,7 @@ func (q *Queries) CreateTeamWithTwoPlayers(ctx context.Context, arg CreateTeamWi
 const deleteTeamById = `-- name: DeleteTeamById :one
 DELETE FROM teams
 WHERE id = $1
-RETURNING id, name, user_id, player_one, player_two, created_at, updated_at
+RETURNING id, name, player_one, player_two, created_at, updated_at, club_id
 `
 
 func (q *Queries) DeleteTeamById(ctx context.Context, id uuid.UUID) (Team, error) {
@@ -115,23 +119,23 @@ func (q *Queries) DeleteTeamById(ctx context.Context, id uuid.UUID) (Team, error
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.UserID,
 		&i.PlayerOne,
 		&i.PlayerTwo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
 
-const getAllTeamsByUserId = `-- name: GetAllTeamsByUserId :many
-SELECT id, name, user_id, player_one, player_two, created_at, updated_at
+const getAllTeamsByClubId = `-- name: GetAllTeamsByClubId :many
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id
 FROM teams
-WHERE user_id = $1
+WHERE club_id = $1
 `
 
-func (q *Queries) GetAllTeamsByUserId(ctx context.Context, userID uuid.UUID) ([]Team, error) {
-	rows, err := q.db.QueryContext(ctx, getAllTeamsByUserId, userID)
+func (q *Queries) GetAllTeamsByClubId(ctx context.Context, clubID uuid.UUID) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, getAllTeamsByClubId, clubID)
 	if err != nil {
 		return nil, err
 	}
@@ -142,11 +146,11 @@ func (q *Queries) GetAllTeamsByUserId(ctx context.Context, userID uuid.UUID) ([]
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
-			&i.UserID,
 			&i.PlayerOne,
 			&i.PlayerTwo,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ClubID,
 		); err != nil {
 			return nil, err
 		}
@@ -162,7 +166,7 @@ func (q *Queries) GetAllTeamsByUserId(ctx context.Context, userID uuid.UUID) ([]
 }
 
 const getTeamById = `-- name: GetTeamById :one
-SELECT id, name, user_id, player_one, player_two, created_at, updated_at 
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id 
 FROM teams
 WHERE id = $1
 LIMIT 1
@@ -174,15 +178,271 @@ func (q *Queries) GetTeamById(ctx context.Context, id uuid.UUID) (Team, error) {
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.UserID,
 		&i.PlayerOne,
 		&i.PlayerTwo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
 
+const listTeamsByClubIdOrderByCreatedAt = `-- name: ListTeamsByClubIdOrderByCreatedAt :many
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id
+FROM teams
+WHERE club_id = $1
+  AND name ILIKE ($2::text || '%')
+  AND ($3::boolean IS NULL OR (player_two IS NOT NULL) = $3::boolean)
+  AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+  AND ($6::uuid IS NULL OR (created_at, id) > ($7::timestamptz, $6::uuid))
+ORDER BY created_at, id
+LIMIT $8
+`
+
+type ListTeamsByClubIdOrderByCreatedAtParams struct {
+	ClubID          uuid.UUID
+	NamePrefix      string
+	HasPlayerTwo    sql.NullBool
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	CursorID        *uuid.UUID
+	CursorCreatedAt time.Time
+	PageSize        int32
+}
+
+func (q *Queries) ListTeamsByClubIdOrderByCreatedAt(ctx context.Context, arg ListTeamsByClubIdOrderByCreatedAtParams) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamsByClubIdOrderByCreatedAt,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.HasPlayerTwo,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PlayerOne,
+			&i.PlayerTwo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamsByClubIdOrderByCreatedAtDesc = `-- name: ListTeamsByClubIdOrderByCreatedAtDesc :many
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id
+FROM teams
+WHERE club_id = $1
+  AND name ILIKE ($2::text || '%')
+  AND ($3::boolean IS NULL OR (player_two IS NOT NULL) = $3::boolean)
+  AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+  AND ($6::uuid IS NULL OR (created_at, id) < ($7::timestamptz, $6::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $8
+`
+
+type ListTeamsByClubIdOrderByCreatedAtDescParams struct {
+	ClubID          uuid.UUID
+	NamePrefix      string
+	HasPlayerTwo    sql.NullBool
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	CursorID        *uuid.UUID
+	CursorCreatedAt time.Time
+	PageSize        int32
+}
+
+func (q *Queries) ListTeamsByClubIdOrderByCreatedAtDesc(ctx context.Context, arg ListTeamsByClubIdOrderByCreatedAtDescParams) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamsByClubIdOrderByCreatedAtDesc,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.HasPlayerTwo,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PlayerOne,
+			&i.PlayerTwo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamsByClubIdOrderByName = `-- name: ListTeamsByClubIdOrderByName :many
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id
+FROM teams
+WHERE club_id = $1
+  AND name ILIKE ($2::text || '%')
+  AND ($3::boolean IS NULL OR (player_two IS NOT NULL) = $3::boolean)
+  AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+  AND ($6::uuid IS NULL OR (name, id) > ($7::text, $6::uuid))
+ORDER BY name, id
+LIMIT $8
+`
+
+type ListTeamsByClubIdOrderByNameParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	HasPlayerTwo  sql.NullBool
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListTeamsByClubIdOrderByName(ctx context.Context, arg ListTeamsByClubIdOrderByNameParams) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamsByClubIdOrderByName,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.HasPlayerTwo,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PlayerOne,
+			&i.PlayerTwo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTeamsByClubIdOrderByNameDesc = `-- name: ListTeamsByClubIdOrderByNameDesc :many
+SELECT id, name, player_one, player_two, created_at, updated_at, club_id
+FROM teams
+WHERE club_id = $1
+  AND name ILIKE ($2::text || '%')
+  AND ($3::boolean IS NULL OR (player_two IS NOT NULL) = $3::boolean)
+  AND ($4::timestamptz IS NULL OR created_at >= $4::timestamptz)
+  AND ($5::timestamptz IS NULL OR created_at < $5::timestamptz)
+  AND ($6::uuid IS NULL OR (name, id) < ($7::text, $6::uuid))
+ORDER BY name DESC, id DESC
+LIMIT $8
+`
+
+type ListTeamsByClubIdOrderByNameDescParams struct {
+	ClubID        uuid.UUID
+	NamePrefix    string
+	HasPlayerTwo  sql.NullBool
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	CursorID      *uuid.UUID
+	CursorName    string
+	PageSize      int32
+}
+
+func (q *Queries) ListTeamsByClubIdOrderByNameDesc(ctx context.Context, arg ListTeamsByClubIdOrderByNameDescParams) ([]Team, error) {
+	rows, err := q.db.QueryContext(ctx, listTeamsByClubIdOrderByNameDesc,
+		arg.ClubID,
+		arg.NamePrefix,
+		arg.HasPlayerTwo,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorID,
+		arg.CursorName,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Team
+	for rows.Next() {
+		var i Team
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PlayerOne,
+			&i.PlayerTwo,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClubID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTeamById = `-- name: UpdateTeamById :one
 UPDATE teams
 SET 
@@ -191,7 +451,7 @@ SET
   name = $3,
   updated_at = Now()
 WHERE id = $4
-RETURNING id, name, user_id, player_one, player_two, created_at, updated_at
+RETURNING id, name, player_one, player_two, created_at, updated_at, club_id
 `
 
 type UpdateTeamByIdParams struct {
@@ -212,11 +472,11 @@ func (q *Queries) UpdateTeamById(ctx context.Context, arg UpdateTeamByIdParams)
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.UserID,
 		&i.PlayerOne,
 		&i.PlayerTwo,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClubID,
 	)
 	return i, err
 }
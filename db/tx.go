@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// InTx runs fn as one unit of work. The querier fn gets is bound to a new
+// transaction, which commits when fn returns nil and rolls back otherwise.
+// Queries that are already bound to a transaction run fn in it, so units of
+// work can call each other and still commit once.
+func (q *Queries) InTx(ctx context.Context, fn func(Querier) error) error {
+	conn, ok := q.db.(*sql.DB)
+	if !ok {
+		return fn(q)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -11,6 +11,48 @@ import (
 	"github.com/google/uuid"
 )
 
+const countUserResources = `-- name: CountUserResources :one
+WITH sole_clubs AS (
+  SELECT club_id AS id
+  FROM club_members
+  WHERE club_members.user_id = $1 AND club_members.role = 'owner' AND NOT EXISTS (
+    SELECT 1 FROM club_members owners
+    WHERE owners.club_id = club_members.club_id AND owners.role = 'owner' AND owners.user_id <> $1
+  )
+)
+SELECT
+  (SELECT count(*) FROM sole_clubs) AS clubs,
+  (SELECT count(*) FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)) AS teams,
+  (SELECT count(*) FROM players WHERE players.id IN (
+    SELECT player_one FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)
+    UNION
+    SELECT player_two FROM teams WHERE teams.club_id IN (SELECT id FROM sole_clubs)
+  )) AS players,
+  (SELECT count(*) FROM matches WHERE matches.club_id IN (SELECT id FROM sole_clubs)) AS matches,
+  (SELECT count(*) FROM refresh_tokens WHERE refresh_tokens.user_id = $1) AS sessions
+`
+
+type CountUserResourcesRow struct {
+	Clubs    int64
+	Teams    int64
+	Players  int64
+	Matches  int64
+	Sessions int64
+}
+
+func (q *Queries) CountUserResources(ctx context.Context, userID uuid.UUID) (CountUserResourcesRow, error) {
+	row := q.db.QueryRowContext(ctx, countUserResources, userID)
+	var i CountUserResourcesRow
+	err := row.Scan(
+		&i.Clubs,
+		&i.Teams,
+		&i.Players,
+		&i.Matches,
+		&i.Sessions,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   username,
@@ -19,7 +61,7 @@ INSERT INTO users (
 ) VALUES (
   $1, $2, $3
 )
-RETURNING id, username, email, password_hash, created_at, updated_at, refresh_token_id
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
 `
 
 type CreateUserParams struct {
@@ -38,7 +80,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -46,7 +88,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 const deleteUserById = `-- name: DeleteUserById :one
 DELETE FROM users
 WHERE id = $1 
-RETURNING id, username, email, password_hash, created_at, updated_at, refresh_token_id
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
 `
 
 func (q *Queries) DeleteUserById(ctx context.Context, id uuid.UUID) (User, error) {
@@ -59,13 +101,13 @@ func (q *Queries) DeleteUserById(ctx context.Context, id uuid.UUID) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, username, email, password_hash, created_at, updated_at, refresh_token_id 
+SELECT id, username, email, password_hash, created_at, updated_at, email_verified_at 
 FROM users
 `
 
@@ -85,7 +127,7 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 			&i.PasswordHash,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.RefreshTokenID,
+			&i.EmailVerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -101,7 +143,7 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at, updated_at, refresh_token_id
+SELECT id, username, email, password_hash, created_at, updated_at, email_verified_at
 FROM users
 WHERE email = $1 
 LIMIT 1
@@ -117,13 +159,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, email, password_hash, created_at, updated_at, refresh_token_id
+SELECT id, username, email, password_hash, created_at, updated_at, email_verified_at
 FROM users
 WHERE id = $1 
 LIMIT 1
@@ -139,13 +181,13 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password_hash, created_at, updated_at, refresh_token_id
+SELECT id, username, email, password_hash, created_at, updated_at, email_verified_at
 FROM users
 WHERE username = $1 
 LIMIT 1
@@ -161,7 +203,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -174,7 +216,7 @@ SET
   password_hash= $3,
   updated_at = Now()
 WHERE id = $4
-RETURNING id, username, email, password_hash, created_at, updated_at, refresh_token_id
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
 `
 
 type UpdateUserByIdParams struct {
@@ -199,7 +241,92 @@ func (q *Queries) UpdateUserById(ctx context.Context, arg UpdateUserByIdParams)
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.RefreshTokenID,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserPasswordById = `-- name: UpdateUserPasswordById :one
+UPDATE users
+SET
+  password_hash = $1,
+  updated_at = Now()
+WHERE id = $2
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
+`
+
+type UpdateUserPasswordByIdParams struct {
+	PasswordHash string
+	ID           uuid.UUID
+}
+
+func (q *Queries) UpdateUserPasswordById(ctx context.Context, arg UpdateUserPasswordByIdParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPasswordById, arg.PasswordHash, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const updateUserProfileById = `-- name: UpdateUserProfileById :one
+UPDATE users
+SET
+  username = $1,
+  email = $2,
+  email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
+  updated_at = Now()
+WHERE id = $3
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
+`
+
+type UpdateUserProfileByIdParams struct {
+	Username string
+	Email    string
+	ID       uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfileById(ctx context.Context, arg UpdateUserProfileByIdParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfileById, arg.Username, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const verifyUserEmailById = `-- name: VerifyUserEmailById :one
+UPDATE users
+SET
+  email_verified_at = Now(),
+  updated_at = Now()
+WHERE id = $1
+RETURNING id, username, email, password_hash, created_at, updated_at, email_verified_at
+`
+
+func (q *Queries) VerifyUserEmailById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmailById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
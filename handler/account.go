@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	CurrentPasswordWrong = errors.New("Current password is wrong")
+	DeletionNotConfirmed = errors.New("Type your username to confirm the deletion")
+)
+
+type (
+	UpdateAccountInput struct {
+		Username string `json:"username" validate:"required,min=3,max=30"`
+		Email    string `json:"email" validate:"required,email,max=254"`
+	}
+
+	ChangePasswordInput struct {
+		CurrentPassword string `json:"currentPassword" validate:"required"`
+		Password        string `json:"password" validate:"required,password,maxbytes=72"`
+		Confirm         string `json:"confirm" validate:"required,eqfield=Password"`
+	}
+
+	// DeleteAccountInput needs the password and the username typed out again,
+	// so an account isn't deleted by a stray click or a stolen access token alone.
+	DeleteAccountInput struct {
+		Password string `json:"password" validate:"required"`
+		Confirm  string `json:"confirm"`
+	}
+
+	AccountDeletion struct {
+		User    db.User
+		Removed db.CountUserResourcesRow
+	}
+)
+
+type AccountHandler struct {
+	DB                  db.Querier
+	UserHandler         UserHandler
+	TokenHandler        RefreshTokenHandler
+	VerificationHandler EmailVerificationHandler
+}
+
+func NewAccountHandler(
+	DBTX db.Querier,
+	userHandler UserHandler,
+	tokenHandler RefreshTokenHandler,
+	verificationHandler EmailVerificationHandler,
+) *AccountHandler {
+	return &AccountHandler{
+		DB:                  DBTX,
+		UserHandler:         userHandler,
+		TokenHandler:        tokenHandler,
+		VerificationHandler: verificationHandler,
+	}
+}
+
+// UpdateAccount changes username and email of the user. A new email has to be
+// verified again.
+func (h *AccountHandler) UpdateAccount(ctx context.Context, user db.User, input UpdateAccountInput) (db.User, error) {
+	if err := Validate(input); err != nil {
+		return db.User{}, err
+	}
+
+	var updated db.User
+	var mail *utils.Message
+	err := inTx(ctx, h.DB, func(q db.Querier) error {
+		var err error
+		updated, err = q.UpdateUserProfileById(ctx, db.UpdateUserProfileByIdParams{
+			Username: input.Username,
+			Email:    input.Email,
+			ID:       user.ID,
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		if updated.Email != user.Email {
+			verification, err := h.VerificationHandler.withDB(q).CreateVerification(ctx, updated)
+			mail = &verification
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	// the link goes out once the new email is committed
+	if mail != nil {
+		h.VerificationHandler.mailCommittedVerification(ctx, *mail)
+	}
+
+	return updated, nil
+}
+
+// ChangePassword sets a new password after checking the current one. Every
+// other session and every personal access token of the user is ended, the
+// session making the change stays.
+func (h *AccountHandler) ChangePassword(ctx context.Context, user db.User, sessionId uuid.UUID, input ChangePasswordInput) (db.User, error) {
+	if err := Validate(input); err != nil {
+		return db.User{}, err
+	}
+
+	err := checkPassword(user, input.CurrentPassword)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	var updated db.User
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		updated, err = h.UserHandler.UpdatePasswordById(ctx, user.ID, input.Password)
+		if err != nil {
+			return err
+		}
+		err = h.TokenHandler.DeleteOtherTokensByUserId(ctx, user.ID, sessionId)
+		if err != nil {
+			return err
+		}
+		return revokeTokensByUserId(ctx, q, user.ID)
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	return updated, nil
+}
+
+// PreviewDeletion returns what would be removed together with the account.
+func (h *AccountHandler) PreviewDeletion(ctx context.Context, user db.User) (AccountDeletion, error) {
+	removed, err := h.DB.CountUserResources(ctx, user.ID)
+	if err != nil {
+		return AccountDeletion{}, DatabaseError(err)
+	}
+
+	return AccountDeletion{User: user, Removed: removed}, nil
+}
+
+// DeleteAccount removes the user with their sessions and every club nobody
+// else owns, including its teams, players and matches, and reports how much of
+// each was removed.
+func (h *AccountHandler) DeleteAccount(ctx context.Context, user db.User, input DeleteAccountInput) (AccountDeletion, error) {
+	if input.Confirm != user.Username {
+		return AccountDeletion{}, ValidationError(DeletionNotConfirmed)
+	}
+
+	err := checkPassword(user, input.Password)
+	if err != nil {
+		return AccountDeletion{}, err
+	}
+
+	// counted in the same transaction, so the report matches what was deleted
+	var deletion AccountDeletion
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		deletion, err = h.PreviewDeletion(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		deletion.User, err = h.UserHandler.DeleteUserById(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return AccountDeletion{}, err
+	}
+
+	return deletion, nil
+}
+
+func checkPassword(user db.User, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return UnauthorizedError(CurrentPasswordWrong)
+	} else if err != nil {
+		return InternalError(err)
+	}
+	return nil
+}
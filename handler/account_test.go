@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	env := config.Config{
+		SESSION: config.SessionConfig{RefreshTokenLifetime: time.Hour},
+		AUTH:    config.AuthConfig{EmailVerificationLifetime: time.Hour},
+	}
+	mailer := &utils.LogMailer{Out: io.Discard}
+	userHandler := UserHandler{DB: dbMock, Env: env}
+	tokenHandler := RefreshTokenHandler{DB: dbMock, Env: env}
+	accountHandler := AccountHandler{
+		DB:           dbMock,
+		UserHandler:  userHandler,
+		TokenHandler: tokenHandler,
+		VerificationHandler: EmailVerificationHandler{
+			DB:          dbMock,
+			UserHandler: userHandler,
+			Mailer:      mailer,
+			Env:         env,
+		},
+	}
+
+	user, err := userHandler.CreateUser(context.Background(), CreateUserInput{
+		Username: "laurin",
+		Email:    "laurin@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+	user, _ = dbMock.VerifyUserEmailById(context.Background(), user.ID)
+
+	t.Run("UpdateAccount", func(t *testing.T) {
+		updated, err := accountHandler.UpdateAccount(context.Background(), user, UpdateAccountInput{Username: "max", Email: "max@test.de"})
+		if err != nil {
+			t.Fatalf("accountHandler.UpdateAccount() = %v, want nil", err)
+		}
+		if updated.Username != "max" || updated.Email != "max@test.de" {
+			t.Fatalf("accountHandler.UpdateAccount() = %+v, want username max and email max@test.de", updated)
+		}
+		if updated.EmailVerifiedAt.Valid {
+			t.Fatalf("accountHandler.UpdateAccount() kept the verification of the old email")
+		}
+		if _, ok := mailer.LastTo("max@test.de"); !ok {
+			t.Fatalf("accountHandler.UpdateAccount() didn't send a verification mail to the new email")
+		}
+		user = updated
+	})
+
+	t.Run("UpdateAccount with the mailer down", func(t *testing.T) {
+		down := accountHandler
+		down.VerificationHandler.Mailer = failingMailer{}
+
+		updated, err := down.UpdateAccount(context.Background(), user, UpdateAccountInput{Username: "max", Email: "down@test.de"})
+		if err != nil {
+			t.Fatalf("accountHandler.UpdateAccount() = %v, want nil for a committed change", err)
+		}
+		if updated.Email != "down@test.de" {
+			t.Fatalf("accountHandler.UpdateAccount() = %+v, want email down@test.de", updated)
+		}
+		user = updated
+	})
+
+	t.Run("ChangePassword", func(t *testing.T) {
+		current, _, _ := tokenHandler.CreateToken(context.Background(), TokenHandlerInput{UserId: user.ID, DeviceLabel: "laptop"})
+		tokenHandler.CreateToken(context.Background(), TokenHandlerInput{UserId: user.ID, DeviceLabel: "phone"})
+		dbMock.CreatePersonalAccessToken(context.Background(), db.CreatePersonalAccessTokenParams{UserID: user.ID, Name: "script", TokenHash: "script"})
+
+		_, err := accountHandler.ChangePassword(context.Background(), user, current.ID, ChangePasswordInput{CurrentPassword: "Wrong", Password: "NewPass1", Confirm: "NewPass1"})
+		want := UnauthorizedError(CurrentPasswordWrong)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("accountHandler.ChangePassword(wrong password) = %v, want %v", err, want)
+		}
+
+		updated, err := accountHandler.ChangePassword(context.Background(), user, current.ID, ChangePasswordInput{CurrentPassword: "Test", Password: "NewPass1", Confirm: "NewPass1"})
+		if err != nil {
+			t.Fatalf("accountHandler.ChangePassword() = %v, want nil", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("NewPass1")) != nil {
+			t.Fatalf("accountHandler.ChangePassword() didn't update the password")
+		}
+
+		sessions, _ := tokenHandler.GetAllTokensByUserId(context.Background(), user.ID)
+		if len(sessions) != 1 || sessions[0].ID != current.ID {
+			t.Fatalf("accountHandler.ChangePassword() left sessions %+v, want only the current one", sessions)
+		}
+		if tokens, _ := dbMock.GetPersonalAccessTokensByUserId(context.Background(), user.ID); len(tokens) != 0 {
+			t.Fatalf("accountHandler.ChangePassword() kept %d personal access tokens", len(tokens))
+		}
+		user = updated
+	})
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		_, err := accountHandler.DeleteAccount(context.Background(), user, DeleteAccountInput{Password: "NewPass1", Confirm: "laurin"})
+		want := ValidationError(DeletionNotConfirmed)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("accountHandler.DeleteAccount(wrong confirmation) = %v, want %v", err, want)
+		}
+
+		deletion, err := accountHandler.DeleteAccount(context.Background(), user, DeleteAccountInput{Password: "NewPass1", Confirm: "max"})
+		if err != nil {
+			t.Fatalf("accountHandler.DeleteAccount() = %v, want nil", err)
+		}
+		wantRemoved := db.CountUserResourcesRow{Clubs: 1, Sessions: 1}
+		if deletion.User.ID != user.ID || deletion.Removed != wantRemoved {
+			t.Fatalf("accountHandler.DeleteAccount() = %+v, want user %s with %+v removed", deletion, user.ID, wantRemoved)
+		}
+
+		if _, err := userHandler.GetUserById(context.Background(), user.ID); err == nil {
+			t.Fatalf("accountHandler.DeleteAccount() didn't delete the user")
+		}
+	})
+}
+
+// failingMailer can't deliver any mail, like an smtp server that is down.
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg utils.Message) error {
+	return errors.New("smtp server is down")
+}
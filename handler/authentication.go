@@ -1,69 +1,78 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"errors"
-	"net/http"
 	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/utils"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-var AccessTokenInvalid = errors.New("Access token is invalid")
+var (
+	AccessTokenInvalid  = errors.New("Access token is invalid")
+	SessionRevoked      = errors.New("Session has been revoked")
+	RefreshTokenInvalid = errors.New("Refresh token is invalid")
+	RefreshTokenExpired = errors.New("Refresh token is expired")
+	RefreshTokenReused  = errors.New("Refresh token was already used, session has been revoked")
+	AccessTokenMissing  = errors.New("Access Token is empty")
+	RefreshTokenMissing = errors.New("Refresh Token is empty")
+	CredentialsInvalid  = errors.New("Wrong username or password")
+)
 
 type (
 	RefreshReq struct {
-		AccessToken string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
 	}
 	RegisterInput struct {
-		Username string `json:"username"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-		Confirm  string `json:"confirm"`
+		Username    string `json:"username" validate:"required,min=3,max=30"`
+		Email       string `json:"email" validate:"required,email,max=254"`
+		Password    string `json:"password" validate:"required,password,maxbytes=72"`
+		Confirm     string `json:"confirm" validate:"required,eqfield=Password"`
+		DeviceLabel string `json:"deviceLabel" validate:"max=100"`
 	}
 
 	LoginInput struct {
-		UsernameOrEmail string `json:"usernameOrEmail"`
-		Password        string `json:"password"`
+		UsernameOrEmail string `json:"usernameOrEmail" validate:"required,max=254"`
+		Password        string `json:"password" validate:"required,maxbytes=72"`
+		DeviceLabel     string `json:"deviceLabel" validate:"max=100"`
 	}
 
+	// ResponsePayload is a new session of User. The api never sends it as
+	// is, the user carries its password hash.
 	ResponsePayload struct {
-		AccessToken string  `json:"accessToken"`
-		User        db.User `json:"user"`
+		AccessToken  string
+		RefreshToken string
+		User         db.User
 	}
 )
 
 type AuthenticationHandler struct {
-	DB  db.Querier
-	UserHandler  UserHandler
-	TokenHandler RefreshTokenHandler
-	TokenGen     utils.TokenGenerator
+	DB                  db.Querier
+	UserHandler         UserHandler
+	TokenHandler        RefreshTokenHandler
+	TokenGen            utils.TokenGenerator
+	VerificationHandler EmailVerificationHandler
 }
 
 func NewAuthenticationHandler(
-	DBTX *db.Queries,
-  userHandler UserHandler,
-  tokenHandler RefreshTokenHandler,
+	DBTX db.Querier,
+	userHandler UserHandler,
+	tokenHandler RefreshTokenHandler,
 	tokenGen utils.TokenGenerator,
+	verificationHandler EmailVerificationHandler,
 ) *AuthenticationHandler {
 	return &AuthenticationHandler{
-		DB:       DBTX,
-    UserHandler: userHandler,
-    TokenHandler: tokenHandler,
-		TokenGen: tokenGen,
-	}
-}
-
-func (r *AuthenticationHandler) ValidateUserInputAndGetJwt(input RegisterInput) error {
-	if input.Username == "" || input.Email == "" || input.Confirm == "" || input.Password == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "Missing inputs.")
+		DB:                  DBTX,
+		UserHandler:         userHandler,
+		TokenHandler:        tokenHandler,
+		TokenGen:            tokenGen,
+		VerificationHandler: verificationHandler,
 	}
-	if input.Password != input.Confirm {
-		return echo.NewHTTPError(http.StatusBadRequest, "Password and confirmation do not match.")
-	}
-	return nil
 }
 
 func (r *AuthenticationHandler) ValidateAccessToken(accessToken string, valid *jwt.Token, user db.User) (ResponsePayload, error) {
@@ -79,77 +88,205 @@ func (r *AuthenticationHandler) ValidateAccessToken(accessToken string, valid *j
 
 func (r *AuthenticationHandler) ParseTokenGetUser(accessToken string, ctx echo.Context) (db.User, *jwt.Token, error) {
 	if accessToken == "" {
-		return db.User{}, nil, echo.NewHTTPError(http.StatusUnauthorized, "Access Token is empty")
+		return db.User{}, nil, UnauthorizedError(AccessTokenMissing)
+	}
+	validAccessToken, err := jwt.ParseWithClaims(accessToken, &utils.CustomTokenClaim{}, r.TokenGen.Keyfunc)
+	if err != nil || validAccessToken == nil {
+		return db.User{}, nil, UnauthorizedError(AccessTokenInvalid)
 	}
-	validAccessToken, err := jwt.ParseWithClaims(accessToken, &utils.CustomTokenClaim{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(r.UserHandler.Env.JWT.AccessToken), nil
-	})
 
 	accessTokenClaim, okAcc := validAccessToken.Claims.(*utils.CustomTokenClaim)
 	if !okAcc {
-		return db.User{}, validAccessToken, echo.NewHTTPError(http.StatusInternalServerError, "Couldn't parse claim")
+		return db.User{}, validAccessToken, UnauthorizedError(AccessTokenInvalid)
 	}
 
 	user, err := r.UserHandler.GetUserById(ctx.Request().Context(), accessTokenClaim.UserID)
 	if err != nil {
-		return db.User{}, validAccessToken, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.User{}, validAccessToken, DatabaseError(err)
 	}
 
 	return user, validAccessToken, err
 }
 
-func (r *AuthenticationHandler) ValidateRefreshToken(ctx echo.Context, user db.User) error {
-	refreshTokenObj, err := r.TokenHandler.GetTokenByUserId(ctx.Request().Context(), user.ID)
+// ValidateSession makes sure the session the access token was issued for still
+// exists and belongs to the user, and records that it was used.
+func (r *AuthenticationHandler) ValidateSession(ctx context.Context, user db.User, sessionId uuid.UUID) (db.RefreshToken, error) {
+	session, err := r.TokenHandler.TouchTokenById(ctx, sessionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.RefreshToken{}, UnauthorizedError(SessionRevoked)
+	} else if err != nil {
+		return db.RefreshToken{}, DatabaseError(err)
+	}
+
+	if session.UserID != user.ID {
+		return db.RefreshToken{}, UnauthorizedError(SessionRevoked)
+	}
+
+	return session, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new access and refresh
+// token. A refresh token that was already rotated must never come back, if it
+// does it leaked and the whole session it belongs to is revoked. Only within
+// the reuse grace after the rotation it is taken for a concurrent refresh of
+// the same client, which gets an access token but no refresh token: it keeps
+// the one the other refresh returned.
+func (r *AuthenticationHandler) RotateRefreshToken(ctx echo.Context, refreshToken string) (ResponsePayload, error) {
+	if refreshToken == "" {
+		return ResponsePayload{}, UnauthorizedError(RefreshTokenMissing)
+	}
+	reqCtx := ctx.Request().Context()
+	tokenHash := utils.HashToken(refreshToken)
+
+	session, err := r.TokenHandler.GetTokenByHash(reqCtx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.refreshRotatedToken(ctx, tokenHash)
+	} else if err != nil {
+		return ResponsePayload{}, DatabaseError(err)
+	}
+
+	if session.ExpiryDate.Before(time.Now()) {
+		return ResponsePayload{}, UnauthorizedError(RefreshTokenExpired)
+	}
+
+	user, err := r.UserHandler.GetUserById(reqCtx, session.UserID)
 	if err != nil {
-		return err
+		return ResponsePayload{}, DatabaseError(err)
 	}
 
-	refreshToken := refreshTokenObj.Token
-	validRefreshToken, err := jwt.ParseWithClaims(refreshToken, &utils.CustomTokenClaim{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(r.UserHandler.Env.JWT.RefreshToken), nil
-	})
-	_, ok := validRefreshToken.Claims.(*utils.CustomTokenClaim)
-	if !ok {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Couldn't parse claim.")
+	// a concurrent refresh with the same token may have rotated it since
+	session, newRefreshToken, err := r.TokenHandler.RotateTokenById(reqCtx, session.ID, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.refreshRotatedToken(ctx, tokenHash)
+	} else if err != nil {
+		return ResponsePayload{}, DatabaseError(err)
+	}
+
+	accessToken, err := r.GenAccessToken(&user, session.ID)
+	if err != nil {
+		return ResponsePayload{}, err
+	}
+
+	return ResponsePayload{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		User:         user,
+	}, nil
+}
+
+// refreshRotatedToken answers a refresh with a token that was already rotated.
+func (r *AuthenticationHandler) refreshRotatedToken(ctx echo.Context, tokenHash string) (ResponsePayload, error) {
+	reqCtx := ctx.Request().Context()
+	rotated, err := r.TokenHandler.GetRotatedTokenByHash(reqCtx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ResponsePayload{}, UnauthorizedError(RefreshTokenInvalid)
+	} else if err != nil {
+		return ResponsePayload{}, DatabaseError(err)
+	}
+
+	session, err := r.TokenHandler.GetTokenById(reqCtx, rotated.SessionID)
+	if err != nil {
+		return ResponsePayload{}, DatabaseError(err)
+	}
+
+	if time.Since(rotated.RotatedAt) < r.TokenHandler.Env.SESSION.RefreshReuseGrace {
+		if session.ExpiryDate.Before(time.Now()) {
+			return ResponsePayload{}, UnauthorizedError(RefreshTokenExpired)
+		}
+
+		user, err := r.UserHandler.GetUserById(reqCtx, session.UserID)
+		if err != nil {
+			return ResponsePayload{}, DatabaseError(err)
+		}
+
+		accessToken, err := r.GenAccessToken(&user, session.ID)
+		if err != nil {
+			return ResponsePayload{}, err
+		}
+		return ResponsePayload{AccessToken: accessToken, User: user}, nil
 	}
 
-	if validRefreshToken.Valid {
-		return nil
-	} else if errors.Is(err, jwt.ErrTokenExpired) {
-		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
-	} else {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	_, err = r.TokenHandler.DeleteUserTokenById(reqCtx, session.ID, session.UserID)
+	if err != nil {
+		return ResponsePayload{}, DatabaseError(err)
 	}
+
+	return ResponsePayload{}, UnauthorizedError(RefreshTokenReused)
 }
 
-func (r *AuthenticationHandler) CreateUserAndToken(ctx echo.Context, registerInput RegisterInput) (db.User, error) {
+func (r *AuthenticationHandler) CreateUserAndToken(ctx echo.Context, registerInput RegisterInput) (ResponsePayload, error) {
+	if err := Validate(registerInput); err != nil {
+		return ResponsePayload{}, err
+	}
+
 	userInput := CreateUserInput{
 		Username: registerInput.Username,
 		Email:    registerInput.Email,
 		Password: registerInput.Password,
 	}
-	newUser, err := r.UserHandler.CreateUser(ctx.Request().Context(), userInput)
+	reqCtx := ctx.Request().Context()
+
+	// the account, its verification link and its first session come together,
+	// the link is only mailed once they are committed
+	var payload ResponsePayload
+	var mail utils.Message
+	err := inTx(reqCtx, r.DB, func(q db.Querier) error {
+		r := r.withDB(q)
+		newUser, err := r.UserHandler.CreateUser(reqCtx, userInput)
+		if err != nil {
+			return err
+		}
+
+		mail, err = r.VerificationHandler.CreateVerification(reqCtx, newUser)
+		if err != nil {
+			return err
+		}
+
+		// without a verified email there is no session to hand out yet
+		if r.VerificationHandler.AllowsLogin(newUser) != nil {
+			payload = ResponsePayload{User: newUser}
+			return nil
+		}
+
+		payload, err = r.IssueTokens(ctx, &newUser, registerInput.DeviceLabel)
+		return err
+	})
+	if err != nil {
+		return ResponsePayload{}, err
+	}
+
+	r.VerificationHandler.mailCommittedVerification(reqCtx, mail)
+	return payload, nil
+}
+
+// IssueTokens starts a new session for the user and returns an access token and
+// the refresh token for that session.
+func (r *AuthenticationHandler) IssueTokens(ctx echo.Context, user *db.User, deviceLabel string) (ResponsePayload, error) {
+	session, refreshToken, err := r.GenRefreshToken(ctx, user, deviceLabel)
 	if err != nil {
-		return db.User{}, err
+		return ResponsePayload{}, err
 	}
 
-	user, err := r.GenRefreshToken(ctx, &newUser)
+	accessToken, err := r.GenAccessToken(user, session.ID)
 	if err != nil {
-		return db.User{}, err
+		return ResponsePayload{}, err
 	}
 
-	return user, nil
+	return ResponsePayload{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         *user,
+	}, nil
 }
 
-func (r *AuthenticationHandler) GenAccessToken(user *db.User) (string, error) {
+func (r *AuthenticationHandler) GenAccessToken(user *db.User, sessionId uuid.UUID) (string, error) {
 	expiryDate := time.Now().Add(utils.OneDay)
 	tokeGenInput := utils.TokenGenInput{
-		UserId:        user.ID,
-		Username:      user.Username,
-		Email:         user.Email,
-		ExpiryDate:    expiryDate,
-		SigningKey:    r.UserHandler.Env.JWT.AccessToken,
-		IsAccessToken: true,
+		UserId:     user.ID,
+		SessionId:  sessionId,
+		Username:   user.Username,
+		Email:      user.Email,
+		ExpiryDate: expiryDate,
 	}
 	signedAccessToken, err := r.TokenGen.GenerateNewJwtToken(tokeGenInput)
 	if err != nil {
@@ -159,27 +296,22 @@ func (r *AuthenticationHandler) GenAccessToken(user *db.User) (string, error) {
 	return signedAccessToken, nil
 }
 
-func (r *AuthenticationHandler) GenRefreshToken(ctx echo.Context, user *db.User) (db.User, error) {
-	updatedUser, err := r.TokenHandler.CreateTokenAndReturnUser(
+// GenRefreshToken opens a new session for the user. If the client didn't send a
+// device label the user agent is used so the session list stays readable.
+func (r *AuthenticationHandler) GenRefreshToken(ctx echo.Context, user *db.User, deviceLabel string) (db.RefreshToken, string, error) {
+	if deviceLabel == "" {
+		deviceLabel = ctx.Request().UserAgent()
+	}
+
+	session, refreshToken, err := r.TokenHandler.CreateToken(
 		ctx.Request().Context(),
 		TokenHandlerInput{
-			UserId:   user.ID,
-			Username: user.Username,
-			Email:    user.Email,
+			UserId:      user.ID,
+			DeviceLabel: deviceLabel,
 		},
 	)
 	if err != nil {
-		return db.User{}, err
+		return db.RefreshToken{}, "", err
 	}
-	return updatedUser, nil
-}
-
-func (r *AuthenticationHandler) LoginRefreshToken(ctx echo.Context, user *db.User) (db.User, error) {
-	err := r.TokenHandler.DeleteTokenByUserId(ctx.Request().Context(), user.ID)
-	if err != nil {
-		return db.User{}, err
-	}
-
-	refUser, err := r.GenRefreshToken(ctx, user)
-	return refUser, err
+	return session, refreshToken, nil
 }
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	PermissionDenied = errors.New("You don't have permission to do this in this club")
+	RoleInvalid      = errors.New("Role has to be owner, coach or viewer")
+	ResourceNotFound = errors.New("Resource not found")
+	PlayerNotInClub  = errors.New("Player is not part of this club")
+	NoActiveClub     = errors.New("You are not a member of any club")
+)
+
+// Roles a user can have in a club. Every account starts out as owner of its
+// own personal club and can be invited into others.
+const (
+	RoleOwner  = "owner"
+	RoleCoach  = "coach"
+	RoleViewer = "viewer"
+)
+
+type Permission string
+
+const (
+	PermissionViewRoster    Permission = "roster:view"
+	PermissionEditRoster    Permission = "roster:edit"
+	PermissionDeleteRoster  Permission = "roster:delete"
+	PermissionRecordMatches Permission = "matches:record"
+	PermissionManageMembers Permission = "members:manage"
+	PermissionManageClub    Permission = "club:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermissionViewRoster,
+		PermissionEditRoster,
+		PermissionDeleteRoster,
+		PermissionRecordMatches,
+		PermissionManageMembers,
+		PermissionManageClub,
+	},
+	RoleCoach: {
+		PermissionViewRoster,
+		PermissionEditRoster,
+		PermissionRecordMatches,
+	},
+	RoleViewer: {
+		PermissionViewRoster,
+	},
+}
+
+// RoleAllows reports whether role grants permission.
+func RoleAllows(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+func validRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+type AuthorizationHandler struct {
+	DB          db.Querier
+	UserHandler UserHandler
+}
+
+func NewAuthorizationHandler(DBTX db.Querier, userHandler UserHandler) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		DB:          DBTX,
+		UserHandler: userHandler,
+	}
+}
+
+// RoleIn returns the role user has in the club, or "" if they aren't a member.
+func (h *AuthorizationHandler) RoleIn(ctx context.Context, user db.User, clubId uuid.UUID) (string, error) {
+	member, err := h.DB.GetClubMember(ctx, db.GetClubMemberParams{
+		ClubID: clubId,
+		UserID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		return "", DatabaseError(err)
+	}
+	return member.Role, nil
+}
+
+// Authorize fails with 403 unless user may do permission in the club.
+func (h *AuthorizationHandler) Authorize(ctx context.Context, user db.User, clubId uuid.UUID, permission Permission) error {
+	role, err := h.RoleIn(ctx, user, clubId)
+	if err != nil {
+		return err
+	}
+	if !RoleAllows(role, permission) {
+		return ForbiddenError(PermissionDenied)
+	}
+	return nil
+}
+
+// DefaultClubId is the club requests act on when they don't pick one: the
+// personal club of the user, or the club they joined first if that is gone.
+func (h *AuthorizationHandler) DefaultClubId(ctx context.Context, user db.User) (uuid.UUID, error) {
+	clubs, err := h.DB.GetClubsByUserId(ctx, user.ID)
+	if err != nil {
+		return uuid.Nil, DatabaseError(err)
+	}
+	if len(clubs) == 0 {
+		return uuid.Nil, NotFoundError(NoActiveClub)
+	}
+
+	idx := slices.IndexFunc(clubs, func(c db.GetClubsByUserIdRow) bool { return c.ID == user.ID })
+	if idx == -1 {
+		idx = 0
+	}
+	return clubs[idx].ID, nil
+}
+
+// ClubOfPlayer returns the club the player belongs to.
+func (h *AuthorizationHandler) ClubOfPlayer(ctx context.Context, playerId uuid.UUID) (uuid.UUID, error) {
+	clubId, err := h.DB.GetClubIdByPlayerId(ctx, playerId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, NotFoundError(ResourceNotFound)
+	} else if err != nil {
+		return uuid.Nil, DatabaseError(err)
+	}
+	return clubId, nil
+}
+
+// ClubOfTeam returns the club the team belongs to.
+func (h *AuthorizationHandler) ClubOfTeam(ctx context.Context, teamId uuid.UUID) (uuid.UUID, error) {
+	team, err := h.DB.GetTeamById(ctx, teamId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, NotFoundError(ResourceNotFound)
+	} else if err != nil {
+		return uuid.Nil, DatabaseError(err)
+	}
+	return team.ClubID, nil
+}
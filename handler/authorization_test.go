@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestRoleAllows(t *testing.T) {
+	allowed := map[string][]Permission{
+		RoleOwner:  {PermissionViewRoster, PermissionEditRoster, PermissionDeleteRoster, PermissionRecordMatches, PermissionManageMembers, PermissionManageClub},
+		RoleCoach:  {PermissionViewRoster, PermissionEditRoster, PermissionRecordMatches},
+		RoleViewer: {PermissionViewRoster},
+		"":         {},
+	}
+	all := allowed[RoleOwner]
+
+	for role, permissions := range allowed {
+		for _, permission := range all {
+			want := false
+			for _, p := range permissions {
+				want = want || p == permission
+			}
+			if got := RoleAllows(role, permission); got != want {
+				t.Errorf("RoleAllows(%q, %q) = %v, want %v", role, permission, got, want)
+			}
+		}
+	}
+}
+
+func TestAuthorizationHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	userHandler := UserHandler{DB: dbMock}
+	authz := AuthorizationHandler{DB: dbMock, UserHandler: userHandler}
+	ctx := context.Background()
+
+	newUser := func(name string) db.User {
+		user, err := userHandler.CreateUser(ctx, CreateUserInput{
+			Username: name,
+			Email:    name + "@test.de",
+			Password: "Test",
+		})
+		if err != nil {
+			t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+		}
+		return user
+	}
+	owner := newUser("owner")
+	coach := newUser("coach")
+	stranger := newUser("stranger")
+
+	wantHTTPError := func(t *testing.T, err error, code int, msg error) {
+		t.Helper()
+		if KindOf(err).Status() != code || !errors.Is(err, msg) {
+			t.Fatalf("got error %v, want %d %v", err, code, msg)
+		}
+	}
+
+	t.Run("users own their personal club", func(t *testing.T) {
+		role, err := authz.RoleIn(ctx, owner, owner.ID)
+		if err != nil || role != RoleOwner {
+			t.Fatalf("authz.RoleIn(owner) = %q, %v, want %q", role, err, RoleOwner)
+		}
+		if err := authz.Authorize(ctx, owner, owner.ID, PermissionManageClub); err != nil {
+			t.Fatalf("authz.Authorize(owner) = %v, want nil", err)
+		}
+	})
+
+	t.Run("strangers have no permission", func(t *testing.T) {
+		err := authz.Authorize(ctx, stranger, owner.ID, PermissionViewRoster)
+		wantHTTPError(t, err, http.StatusForbidden, PermissionDenied)
+	})
+
+	t.Run("coach can edit but not delete", func(t *testing.T) {
+		dbMock.UpsertClubMember(ctx, db.UpsertClubMemberParams{ClubID: owner.ID, UserID: coach.ID, Role: RoleCoach})
+
+		if err := authz.Authorize(ctx, coach, owner.ID, PermissionEditRoster); err != nil {
+			t.Fatalf("authz.Authorize(coach, edit) = %v, want nil", err)
+		}
+		err := authz.Authorize(ctx, coach, owner.ID, PermissionDeleteRoster)
+		wantHTTPError(t, err, http.StatusForbidden, PermissionDenied)
+
+		// the role only applies to the club it was given for
+		err = authz.Authorize(ctx, owner, coach.ID, PermissionViewRoster)
+		wantHTTPError(t, err, http.StatusForbidden, PermissionDenied)
+	})
+
+	t.Run("DefaultClubId prefers the personal club", func(t *testing.T) {
+		clubId, err := authz.DefaultClubId(ctx, coach)
+		if err != nil || clubId != coach.ID {
+			t.Fatalf("authz.DefaultClubId(coach) = %s, %v, want %s", clubId, err, coach.ID)
+		}
+
+		dbMock.DeleteClubById(ctx, coach.ID)
+		clubId, err = authz.DefaultClubId(ctx, coach)
+		if err != nil || clubId != owner.ID {
+			t.Fatalf("authz.DefaultClubId(coach without own club) = %s, %v, want %s", clubId, err, owner.ID)
+		}
+
+		dbMock.DeleteClubById(ctx, stranger.ID)
+		_, err = authz.DefaultClubId(ctx, stranger)
+		wantHTTPError(t, err, http.StatusNotFound, NoActiveClub)
+	})
+}
@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ClubNameMissing         = errors.New("Club name is missing")
+	ClubNotFound            = errors.New("Club not found")
+	ClubMemberAbsent        = errors.New("User is not a member of this club")
+	LastClubOwner           = errors.New("A club needs at least one owner")
+	AlreadyClubMember       = errors.New("You are already a member of this club")
+	InvitationInvalid       = errors.New("Invitation is invalid")
+	InvitationExpired       = errors.New("Invitation is expired")
+	InvitationForOtherEmail = errors.New("Invitation was sent to another email address")
+	InvitationNotFound      = errors.New("Invitation not found")
+)
+
+type CreateClubInput struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type ChangeRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=owner coach viewer"`
+}
+
+// InviteInput creates an invitation. Without an email address the invitation
+// is only handed out as link and anyone who opens it can join.
+type InviteInput struct {
+	Email string `json:"email" validate:"email,max=254"`
+	Role  string `json:"role" validate:"required,oneof=owner coach viewer"`
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ClubInvitationLink is a new invitation together with the link to join. The
+// token in it is never stored, so this is the only time it can be shown.
+type ClubInvitationLink struct {
+	Invitation db.ClubInvitation
+	Link       string
+}
+
+type ClubHandler struct {
+	DB     db.Querier
+	Mailer utils.Mailer
+	Env    config.Config
+}
+
+func NewClubHandler(DBTX db.Querier, env config.Config, mailer utils.Mailer) *ClubHandler {
+	return &ClubHandler{
+		DB:     DBTX,
+		Mailer: mailer,
+		Env:    env,
+	}
+}
+
+// CreateClub starts a new club with user as its only owner.
+func (h *ClubHandler) CreateClub(ctx context.Context, user db.User, input CreateClubInput) (db.Club, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return db.Club{}, ValidationError(ClubNameMissing)
+	}
+
+	var club db.Club
+	err := inTx(ctx, h.DB, func(q db.Querier) error {
+		var err error
+		club, err = q.CreateClub(ctx, db.CreateClubParams{ID: uuid.New(), Name: name})
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		_, err = q.UpsertClubMember(ctx, db.UpsertClubMemberParams{
+			ClubID: club.ID,
+			UserID: user.ID,
+			Role:   RoleOwner,
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.Club{}, err
+	}
+
+	return club, nil
+}
+
+// GetClubs lists the clubs user is a member of together with their role.
+func (h *ClubHandler) GetClubs(ctx context.Context, user db.User) ([]db.GetClubsByUserIdRow, error) {
+	clubs, err := h.DB.GetClubsByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, DatabaseError(err)
+	}
+	return clubs, nil
+}
+
+func (h *ClubHandler) RenameClub(ctx context.Context, clubId uuid.UUID, input CreateClubInput) (db.Club, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return db.Club{}, ValidationError(ClubNameMissing)
+	}
+
+	club, err := h.DB.UpdateClubById(ctx, db.UpdateClubByIdParams{Name: name, ID: clubId})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Club{}, NotFoundError(ClubNotFound)
+	} else if err != nil {
+		return db.Club{}, DatabaseError(err)
+	}
+	return club, nil
+}
+
+// DeleteClub removes the club with all its teams, players and matches.
+func (h *ClubHandler) DeleteClub(ctx context.Context, clubId uuid.UUID) (db.Club, error) {
+	club, err := h.DB.DeleteClubById(ctx, clubId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Club{}, NotFoundError(ClubNotFound)
+	} else if err != nil {
+		return db.Club{}, DatabaseError(err)
+	}
+	return club, nil
+}
+
+func (h *ClubHandler) GetMembers(ctx context.Context, clubId uuid.UUID) ([]db.GetClubMembersByClubIdRow, error) {
+	members, err := h.DB.GetClubMembersByClubId(ctx, clubId)
+	if err != nil {
+		return nil, DatabaseError(err)
+	}
+	return members, nil
+}
+
+// ChangeRole gives an existing member another role. The last owner can't
+// give up ownership, the club would be left without anyone to manage it.
+func (h *ClubHandler) ChangeRole(ctx context.Context, clubId uuid.UUID, userId uuid.UUID, input ChangeRoleInput) (db.ClubMember, error) {
+	if !validRole(input.Role) {
+		return db.ClubMember{}, ValidationError(RoleInvalid)
+	}
+
+	var member db.ClubMember
+	err := inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		err := h.keepAnOwner(ctx, clubId, userId, input.Role == RoleOwner)
+		if err != nil {
+			return err
+		}
+
+		member, err = h.DB.UpsertClubMember(ctx, db.UpsertClubMemberParams{
+			ClubID: clubId,
+			UserID: userId,
+			Role:   input.Role,
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.ClubMember{}, err
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of the club, which is also how members leave.
+func (h *ClubHandler) RemoveMember(ctx context.Context, clubId uuid.UUID, userId uuid.UUID) (db.ClubMember, error) {
+	var member db.ClubMember
+	err := inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		err := h.keepAnOwner(ctx, clubId, userId, false)
+		if err != nil {
+			return err
+		}
+
+		member, err = h.DB.DeleteClubMember(ctx, db.DeleteClubMemberParams{ClubID: clubId, UserID: userId})
+		if errors.Is(err, sql.ErrNoRows) {
+			return NotFoundError(ClubMemberAbsent)
+		} else if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.ClubMember{}, err
+	}
+	return member, nil
+}
+
+func (h *ClubHandler) getMember(ctx context.Context, clubId uuid.UUID, userId uuid.UUID) (db.ClubMember, error) {
+	member, err := h.DB.GetClubMember(ctx, db.GetClubMemberParams{ClubID: clubId, UserID: userId})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ClubMember{}, NotFoundError(ClubMemberAbsent)
+	} else if err != nil {
+		return db.ClubMember{}, DatabaseError(err)
+	}
+	return member, nil
+}
+
+// keepAnOwner fails if userId is a member that is about to stop being an
+// owner and no other owner is left. It locks the owners of the club first, so
+// it has to run in the transaction of the change: of two owners leaving at the
+// same time the second one waits and sees the first one gone.
+func (h *ClubHandler) keepAnOwner(ctx context.Context, clubId uuid.UUID, userId uuid.UUID, staysOwner bool) error {
+	owners, err := h.DB.LockClubOwners(ctx, clubId)
+	if err != nil {
+		return DatabaseError(err)
+	}
+
+	member, err := h.getMember(ctx, clubId, userId)
+	if err != nil {
+		return err
+	}
+	if member.Role == RoleOwner && !staysOwner && len(owners) <= 1 {
+		return ValidationError(LastClubOwner)
+	}
+	return nil
+}
+
+// Invite creates an invitation into the club that expires after the configured
+// lifetime. Invitations with an email address are mailed and can only be
+// accepted by the account with that (verified) address.
+func (h *ClubHandler) Invite(ctx context.Context, inviter db.User, clubId uuid.UUID, input InviteInput) (ClubInvitationLink, error) {
+	if !validRole(input.Role) {
+		return ClubInvitationLink{}, ValidationError(RoleInvalid)
+	}
+
+	club, err := h.DB.GetClubById(ctx, clubId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ClubInvitationLink{}, NotFoundError(ClubNotFound)
+	} else if err != nil {
+		return ClubInvitationLink{}, DatabaseError(err)
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return ClubInvitationLink{}, InternalError(err)
+	}
+
+	email := strings.TrimSpace(input.Email)
+	invitation, err := h.DB.CreateClubInvitation(ctx, db.CreateClubInvitationParams{
+		ClubID:     club.ID,
+		Email:      email,
+		Role:       input.Role,
+		TokenHash:  utils.HashToken(token),
+		InvitedBy:  &inviter.ID,
+		ExpiryDate: time.Now().Add(h.Env.AUTH.ClubInvitationLifetime),
+	})
+	if err != nil {
+		return ClubInvitationLink{}, DatabaseError(err)
+	}
+
+	link := h.Env.ECHO.PublicUrl + "/join-club?token=" + token
+	if email != "" {
+		err = h.Mailer.Send(ctx, utils.Message{
+			To:      email,
+			Subject: "Join " + club.Name + " on Tennis Analysis",
+			Body: fmt.Sprintf(
+				"Hi,\n\n%s invited you to join %s as %s. Open the link below to accept:\n\n%s\n\nThe link expires in %s.\n",
+				inviter.Username,
+				club.Name,
+				input.Role,
+				link,
+				h.Env.AUTH.ClubInvitationLifetime,
+			),
+		})
+		if err != nil {
+			return ClubInvitationLink{}, InternalError(err)
+		}
+	}
+
+	return ClubInvitationLink{Invitation: invitation, Link: link}, nil
+}
+
+// GetInvitations lists the invitations of the club nobody accepted yet.
+func (h *ClubHandler) GetInvitations(ctx context.Context, clubId uuid.UUID) ([]db.ClubInvitation, error) {
+	invitations, err := h.DB.GetOpenClubInvitationsByClubId(ctx, clubId)
+	if err != nil {
+		return nil, DatabaseError(err)
+	}
+	return invitations, nil
+}
+
+func (h *ClubHandler) RevokeInvitation(ctx context.Context, clubId uuid.UUID, invitationId uuid.UUID) (db.ClubInvitation, error) {
+	invitation, err := h.DB.DeleteClubInvitation(ctx, db.DeleteClubInvitationParams{ID: invitationId, ClubID: clubId})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ClubInvitation{}, NotFoundError(InvitationNotFound)
+	} else if err != nil {
+		return db.ClubInvitation{}, DatabaseError(err)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation makes user a member of the club the token invites to. Every
+// invitation can only be used once.
+func (h *ClubHandler) AcceptInvitation(ctx context.Context, user db.User, input AcceptInvitationInput) (db.ClubMember, error) {
+	if input.Token == "" {
+		return db.ClubMember{}, ValidationError(InvitationInvalid)
+	}
+
+	invitation, err := h.DB.GetClubInvitationByHash(ctx, utils.HashToken(input.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ClubMember{}, ValidationError(InvitationInvalid)
+	} else if err != nil {
+		return db.ClubMember{}, DatabaseError(err)
+	}
+
+	if invitation.AcceptedAt.Valid {
+		return db.ClubMember{}, ValidationError(InvitationInvalid)
+	}
+	if invitation.ExpiryDate.Before(time.Now()) {
+		return db.ClubMember{}, ValidationError(InvitationExpired)
+	}
+	if invitation.Email != "" {
+		if !strings.EqualFold(invitation.Email, user.Email) {
+			return db.ClubMember{}, ForbiddenError(InvitationForOtherEmail)
+		}
+		if !user.EmailVerifiedAt.Valid {
+			return db.ClubMember{}, ForbiddenError(EmailNotVerified)
+		}
+	}
+
+	_, err = h.DB.GetClubMember(ctx, db.GetClubMemberParams{ClubID: invitation.ClubID, UserID: user.ID})
+	if err == nil {
+		return db.ClubMember{}, ConflictError(AlreadyClubMember)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return db.ClubMember{}, DatabaseError(err)
+	}
+
+	var member db.ClubMember
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		// marking it accepted first keeps two requests from both using it
+		_, err := q.AcceptClubInvitation(ctx, invitation.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ValidationError(InvitationInvalid)
+		} else if err != nil {
+			return DatabaseError(err)
+		}
+
+		member, err = q.UpsertClubMember(ctx, db.UpsertClubMemberParams{
+			ClubID: invitation.ClubID,
+			UserID: user.ID,
+			Role:   invitation.Role,
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.ClubMember{}, err
+	}
+	return member, nil
+}
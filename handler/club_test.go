@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestClubHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	env := config.Config{
+		AUTH: config.AuthConfig{ClubInvitationLifetime: time.Hour},
+		ECHO: config.EchoConfig{PublicUrl: "http://localhost:3000"},
+	}
+	mailer := &utils.LogMailer{Out: io.Discard}
+	userHandler := UserHandler{DB: dbMock}
+	clubHandler := ClubHandler{DB: dbMock, Mailer: mailer, Env: env}
+	authz := AuthorizationHandler{DB: dbMock, UserHandler: userHandler}
+	ctx := context.Background()
+
+	newUser := func(name string) db.User {
+		user, err := userHandler.CreateUser(ctx, CreateUserInput{
+			Username: name,
+			Email:    name + "@test.de",
+			Password: "Test",
+		})
+		if err != nil {
+			t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+		}
+		return user
+	}
+	owner := newUser("owner")
+	coach := newUser("coach")
+	viewer := newUser("viewer")
+
+	wantHTTPError := func(t *testing.T, err error, code int, msg error) {
+		t.Helper()
+		if KindOf(err).Status() != code || !errors.Is(err, msg) {
+			t.Fatalf("got error %v, want %d %v", err, code, msg)
+		}
+	}
+
+	tokenOf := func(t *testing.T, link string) string {
+		t.Helper()
+		_, token, found := strings.Cut(link, "/join-club?token=")
+		if !found {
+			t.Fatalf("invitation link %q doesn't contain a token", link)
+		}
+		return token
+	}
+
+	club, err := clubHandler.CreateClub(ctx, owner, CreateClubInput{Name: " TC Blau-Weiss "})
+	if err != nil {
+		t.Fatalf("clubHandler.CreateClub() = %v, want nil", err)
+	}
+
+	t.Run("CreateClub", func(t *testing.T) {
+		if club.Name != "TC Blau-Weiss" {
+			t.Fatalf("clubHandler.CreateClub() name = %q, want it trimmed", club.Name)
+		}
+		_, err := clubHandler.CreateClub(ctx, owner, CreateClubInput{Name: " "})
+		wantHTTPError(t, err, http.StatusBadRequest, ClubNameMissing)
+
+		clubs, _ := clubHandler.GetClubs(ctx, owner)
+		if len(clubs) != 2 || clubs[1].ID != club.ID || clubs[1].Role != RoleOwner {
+			t.Fatalf("clubHandler.GetClubs() = %+v, want the personal club and %s as owner", clubs, club.ID)
+		}
+	})
+
+	t.Run("email invitation", func(t *testing.T) {
+		_, err := clubHandler.Invite(ctx, owner, club.ID, InviteInput{Email: "coach@test.de", Role: "admin"})
+		wantHTTPError(t, err, http.StatusBadRequest, RoleInvalid)
+
+		invite, err := clubHandler.Invite(ctx, owner, club.ID, InviteInput{Email: "coach@test.de", Role: RoleCoach})
+		if err != nil {
+			t.Fatalf("clubHandler.Invite() = %v, want nil", err)
+		}
+		msg, ok := mailer.LastTo("coach@test.de")
+		if !ok || !strings.Contains(msg.Body, invite.Link) {
+			t.Fatalf("clubHandler.Invite() mail = %+v, want one with %q", msg, invite.Link)
+		}
+		token := tokenOf(t, invite.Link)
+
+		_, err = clubHandler.AcceptInvitation(ctx, viewer, AcceptInvitationInput{Token: token})
+		wantHTTPError(t, err, http.StatusForbidden, InvitationForOtherEmail)
+
+		_, err = clubHandler.AcceptInvitation(ctx, coach, AcceptInvitationInput{Token: token})
+		wantHTTPError(t, err, http.StatusForbidden, EmailNotVerified)
+
+		coach, _ = dbMock.VerifyUserEmailById(ctx, coach.ID)
+		member, err := clubHandler.AcceptInvitation(ctx, coach, AcceptInvitationInput{Token: token})
+		if err != nil || member.Role != RoleCoach {
+			t.Fatalf("clubHandler.AcceptInvitation() = %+v, %v, want coach", member, err)
+		}
+		if err := authz.Authorize(ctx, coach, club.ID, PermissionEditRoster); err != nil {
+			t.Fatalf("authz.Authorize(coach) = %v, want nil", err)
+		}
+
+		_, err = clubHandler.AcceptInvitation(ctx, coach, AcceptInvitationInput{Token: token})
+		wantHTTPError(t, err, http.StatusBadRequest, InvitationInvalid)
+	})
+
+	t.Run("link invitation", func(t *testing.T) {
+		clubHandler.Env.AUTH.ClubInvitationLifetime = -time.Minute
+		expired, _ := clubHandler.Invite(ctx, owner, club.ID, InviteInput{Role: RoleViewer})
+		clubHandler.Env.AUTH.ClubInvitationLifetime = time.Hour
+
+		_, err := clubHandler.AcceptInvitation(ctx, viewer, AcceptInvitationInput{Token: tokenOf(t, expired.Link)})
+		wantHTTPError(t, err, http.StatusBadRequest, InvitationExpired)
+
+		invite, _ := clubHandler.Invite(ctx, owner, club.ID, InviteInput{Role: RoleViewer})
+		if _, err := clubHandler.AcceptInvitation(ctx, viewer, AcceptInvitationInput{Token: tokenOf(t, invite.Link)}); err != nil {
+			t.Fatalf("clubHandler.AcceptInvitation(link) = %v, want nil", err)
+		}
+
+		again, _ := clubHandler.Invite(ctx, owner, club.ID, InviteInput{Role: RoleOwner})
+		_, err = clubHandler.AcceptInvitation(ctx, viewer, AcceptInvitationInput{Token: tokenOf(t, again.Link)})
+		wantHTTPError(t, err, http.StatusConflict, AlreadyClubMember)
+
+		open, _ := clubHandler.GetInvitations(ctx, club.ID)
+		if len(open) != 2 {
+			t.Fatalf("clubHandler.GetInvitations() = %d invitations, want the expired and the unused one", len(open))
+		}
+		if _, err := clubHandler.RevokeInvitation(ctx, club.ID, again.Invitation.ID); err != nil {
+			t.Fatalf("clubHandler.RevokeInvitation() = %v, want nil", err)
+		}
+		_, err = clubHandler.AcceptInvitation(ctx, coach, AcceptInvitationInput{Token: tokenOf(t, again.Link)})
+		wantHTTPError(t, err, http.StatusBadRequest, InvitationInvalid)
+	})
+
+	t.Run("the last owner stays", func(t *testing.T) {
+		_, err := clubHandler.ChangeRole(ctx, club.ID, owner.ID, ChangeRoleInput{Role: RoleCoach})
+		wantHTTPError(t, err, http.StatusBadRequest, LastClubOwner)
+		_, err = clubHandler.RemoveMember(ctx, club.ID, owner.ID)
+		wantHTTPError(t, err, http.StatusBadRequest, LastClubOwner)
+
+		if _, err := clubHandler.ChangeRole(ctx, club.ID, coach.ID, ChangeRoleInput{Role: RoleOwner}); err != nil {
+			t.Fatalf("clubHandler.ChangeRole(coach, owner) = %v, want nil", err)
+		}
+		if _, err := clubHandler.RemoveMember(ctx, club.ID, owner.ID); err != nil {
+			t.Fatalf("clubHandler.RemoveMember(owner) = %v, want nil", err)
+		}
+		_, err = clubHandler.RemoveMember(ctx, club.ID, owner.ID)
+		wantHTTPError(t, err, http.StatusNotFound, ClubMemberAbsent)
+
+		members, _ := clubHandler.GetMembers(ctx, club.ID)
+		if len(members) != 2 || members[0].Username != "coach" || members[1].Username != "viewer" {
+			t.Fatalf("clubHandler.GetMembers() = %+v, want coach and viewer", members)
+		}
+	})
+
+	t.Run("DeleteClub", func(t *testing.T) {
+		if _, err := clubHandler.DeleteClub(ctx, club.ID); err != nil {
+			t.Fatalf("clubHandler.DeleteClub() = %v, want nil", err)
+		}
+		err := authz.Authorize(ctx, coach, club.ID, PermissionViewRoster)
+		wantHTTPError(t, err, http.StatusForbidden, PermissionDenied)
+
+		_, err = clubHandler.DeleteClub(ctx, club.ID)
+		wantHTTPError(t, err, http.StatusNotFound, ClubNotFound)
+	})
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ErrorKind says what went wrong without saying how to answer it, the api maps
+// every kind to a status. It is also the code in the JSON error body, so the
+// values must not change.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation_failed"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindNotFound     ErrorKind = "not_found"
+	KindConflict     ErrorKind = "conflict"
+	KindInternal     ErrorKind = "internal_error"
+)
+
+var (
+	RecordNotFound   = errors.New("Not found")
+	RecordExists     = errors.New("This already exists")
+	RecordMissing    = errors.New("Something this refers to doesn't exist")
+	RecordReferenced = errors.New("This is still in use")
+	RecordInvalid    = errors.New("These values are not allowed")
+	UsernameTaken    = errors.New("Username already exists")
+	EmailTaken       = errors.New("Email already exists")
+	PlayerExists     = errors.New("Player already exists")
+	TeamExists       = errors.New("Team already exists")
+	SessionNotFound  = errors.New("Session not found")
+	UserNotFound     = errors.New("User not found")
+)
+
+// uniqueViolations names the unique constraints a user can run into.
+var uniqueViolations = map[string]error{
+	"users_username_unique": UsernameTaken,
+	"users_email_unique":    EmailTaken,
+	"unique_firstlast_name": PlayerExists,
+	"unique_players":        TeamExists,
+}
+
+// DomainError is an error of a known kind. Err is shown to the client, unless
+// the kind is internal, then it is only logged.
+type DomainError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *DomainError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DomainError) Unwrap() error {
+	return e.Err
+}
+
+func ValidationError(err error) error {
+	return &DomainError{Kind: KindValidation, Err: err}
+}
+
+func UnauthorizedError(err error) error {
+	return &DomainError{Kind: KindUnauthorized, Err: err}
+}
+
+func ForbiddenError(err error) error {
+	return &DomainError{Kind: KindForbidden, Err: err}
+}
+
+func NotFoundError(err error) error {
+	return &DomainError{Kind: KindNotFound, Err: err}
+}
+
+func ConflictError(err error) error {
+	return &DomainError{Kind: KindConflict, Err: err}
+}
+
+func InternalError(err error) error {
+	return &DomainError{Kind: KindInternal, Err: err}
+}
+
+// KindOf returns the kind of err. Errors that were never given one are
+// internal.
+func KindOf(err error) ErrorKind {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// Status is the http status answering an error of kind k.
+func (k ErrorKind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// DatabaseError gives an error from a query its kind. Missing rows are not
+// found and constraint violations are conflicts, with a message that doesn't
+// leak the SQL. Errors that already have a kind keep it.
+func DatabaseError(err error) error {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return NotFoundError(RecordNotFound)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return InternalError(err)
+	}
+
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		if known, ok := uniqueViolations[pqErr.Constraint]; ok {
+			return ConflictError(known)
+		}
+		return ConflictError(RecordExists)
+	case "foreign_key_violation":
+		// inserts point at a row that is gone, deletes at a row still pointed at
+		if strings.HasPrefix(pqErr.Message, "insert or update") {
+			return ConflictError(RecordMissing)
+		}
+		return ConflictError(RecordReferenced)
+	case "check_violation", "not_null_violation", "invalid_text_representation":
+		return ValidationError(RecordInvalid)
+	default:
+		return InternalError(err)
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestDatabaseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		kind   ErrorKind
+		target error
+	}{
+		{"missing rows", sql.ErrNoRows, KindNotFound, RecordNotFound},
+		{"wrapped missing rows", fmt.Errorf("get user: %w", sql.ErrNoRows), KindNotFound, RecordNotFound},
+		{"known unique constraint", &pq.Error{Code: "23505", Constraint: "users_email_unique"}, KindConflict, EmailTaken},
+		{"other unique constraint", &pq.Error{Code: "23505", Constraint: "club_members_pkey"}, KindConflict, RecordExists},
+		{"insert with a missing reference", &pq.Error{Code: "23503", Message: "insert or update on table \"teams\" violates foreign key constraint"}, KindConflict, RecordMissing},
+		{"delete of a referenced row", &pq.Error{Code: "23503", Message: "update or delete on table \"players\" violates foreign key constraint"}, KindConflict, RecordReferenced},
+		{"check constraint", &pq.Error{Code: "23514"}, KindValidation, RecordInvalid},
+		{"kinds are kept", ForbiddenError(ScopeNotGranted), KindForbidden, ScopeNotGranted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DatabaseError(tt.err)
+			if KindOf(err) != tt.kind || !errors.Is(err, tt.target) {
+				t.Fatalf("DatabaseError(%v) = %v (%s), want %v (%s)", tt.err, err, KindOf(err), tt.target, tt.kind)
+			}
+		})
+	}
+
+	t.Run("everything else is internal", func(t *testing.T) {
+		cause := &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}
+		err := DatabaseError(cause)
+		if KindOf(err) != KindInternal || !errors.Is(err, cause) {
+			t.Fatalf("DatabaseError() = %v (%s), want the cause as internal error", err, KindOf(err))
+		}
+	})
+}
+
+func TestErrorKindStatus(t *testing.T) {
+	tests := map[ErrorKind]int{
+		KindValidation:   http.StatusBadRequest,
+		KindUnauthorized: http.StatusUnauthorized,
+		KindForbidden:    http.StatusForbidden,
+		KindNotFound:     http.StatusNotFound,
+		KindConflict:     http.StatusConflict,
+		KindInternal:     http.StatusInternalServerError,
+	}
+	for kind, want := range tests {
+		if got := kind.Status(); got != want {
+			t.Errorf("%s.Status() = %d, want %d", kind, got, want)
+		}
+	}
+	if got := KindOf(errors.New("plain")); got != KindInternal {
+		t.Errorf("KindOf(plain error) = %s, want %s", got, KindInternal)
+	}
+}
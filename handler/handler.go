@@ -1,9 +1,19 @@
 package handler
 
 type ResourceHandlers struct {
-  UserHandler UserHandler
-  TokenHandler RefreshTokenHandler
-  AuthHandler AuthenticationHandler
-  PlayerHandler PlayerHandler
-  TeamHandler TeamHandler
+	UserHandler          UserHandler
+	TokenHandler         RefreshTokenHandler
+	AuthHandler          AuthenticationHandler
+	PlayerHandler        PlayerHandler
+	TeamHandler          TeamHandler
+	PasswordHandler      PasswordHandler
+	VerificationHandler  EmailVerificationHandler
+	AccountHandler       AccountHandler
+	LoginThrottleHandler LoginThrottleHandler
+	TwoFactorHandler     TwoFactorHandler
+	AuthorizationHandler AuthorizationHandler
+	ClubHandler          ClubHandler
+	PersonalTokenHandler PersonalTokenHandler
+	OIDCHandler          OIDCHandler
+	HealthHandler        HealthHandler
 }
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Pinger is a database connection that can be checked, like *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// SchemaChecker reports how many migrations the database is behind, like
+// *db.Migrator. It fails for a schema the binary can't work with.
+type SchemaChecker interface {
+	Check(ctx context.Context) (int, error)
+}
+
+const readinessTimeout = 2 * time.Second
+
+// Values of Readiness.Status and of its checks.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+	HealthDraining    = "draining"
+)
+
+// Readiness says whether the instance should get traffic, with the result of
+// every check.
+type Readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (r Readiness) Ready() bool {
+	return r.Status == HealthOK
+}
+
+// HealthHandler answers the probes of the container deployment. Copies share
+// the draining state, so the handler the routes got sees Drain.
+type HealthHandler struct {
+	DB       Pinger
+	Schema   SchemaChecker
+	draining *atomic.Bool
+}
+
+func NewHealthHandler(db Pinger, schema SchemaChecker) *HealthHandler {
+	return &HealthHandler{
+		DB:       db,
+		Schema:   schema,
+		draining: &atomic.Bool{},
+	}
+}
+
+// Drain marks the instance as shutting down. It stays alive to finish the
+// requests it has, but isn't ready for new ones anymore.
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready checks that the database answers and has the schema this binary was
+// built for. The probe is public, so why a check failed is only logged.
+func (h *HealthHandler) Ready(ctx context.Context) Readiness {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	readiness := Readiness{Status: HealthOK, Checks: map[string]string{}}
+	fail := func(check string, reason any) {
+		log.Printf("readiness check %s failed: %v\n", check, reason)
+		readiness.Status = HealthUnavailable
+		readiness.Checks[check] = HealthUnavailable
+	}
+
+	if h.draining != nil && h.draining.Load() {
+		readiness.Status = HealthDraining
+		readiness.Checks["server"] = HealthDraining
+	} else {
+		readiness.Checks["server"] = HealthOK
+	}
+
+	if err := h.DB.PingContext(ctx); err != nil {
+		fail("database", err)
+		return readiness
+	}
+	readiness.Checks["database"] = HealthOK
+
+	pending, err := h.Schema.Check(ctx)
+	if err != nil {
+		fail("migrations", err)
+	} else if pending > 0 {
+		fail("migrations", fmt.Sprintf("%d migrations pending", pending))
+	} else {
+		readiness.Checks["migrations"] = HealthOK
+	}
+	return readiness
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error { return f(ctx) }
+
+type schemaFunc func(ctx context.Context) (int, error)
+
+func (f schemaFunc) Check(ctx context.Context) (int, error) { return f(ctx) }
+
+func TestHealthHandlerReady(t *testing.T) {
+	up := pingerFunc(func(context.Context) error { return nil })
+	current := schemaFunc(func(context.Context) (int, error) { return 0, nil })
+
+	tests := []struct {
+		name   string
+		db     Pinger
+		schema SchemaChecker
+		drain  bool
+		status string
+		check  string
+		want   string
+	}{
+		{"ready", up, current, false, HealthOK, "migrations", HealthOK},
+		{"database down", pingerFunc(func(context.Context) error { return errors.New("connection refused") }), current, false, HealthUnavailable, "database", HealthUnavailable},
+		{"pending migrations", up, schemaFunc(func(context.Context) (int, error) { return 2, nil }), false, HealthUnavailable, "migrations", HealthUnavailable},
+		{"newer schema", up, schemaFunc(func(context.Context) (int, error) { return 0, errors.New("newer") }), false, HealthUnavailable, "migrations", HealthUnavailable},
+		{"draining", up, current, true, HealthDraining, "server", HealthDraining},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(tt.db, tt.schema)
+			if tt.drain {
+				h.Drain()
+			}
+
+			readiness := h.Ready(context.Background())
+			if readiness.Status != tt.status || readiness.Checks[tt.check] != tt.want {
+				t.Fatalf("h.Ready() = %+v, want %s with %s %s", readiness, tt.status, tt.check, tt.want)
+			}
+			if readiness.Ready() != (tt.status == HealthOK) {
+				t.Fatalf("readiness.Ready() = %t for %s", readiness.Ready(), tt.status)
+			}
+		})
+	}
+
+	t.Run("copies share the draining state", func(t *testing.T) {
+		h := NewHealthHandler(up, current)
+		routed := *h
+		h.Drain()
+		if routed.Ready(context.Background()).Ready() {
+			t.Fatalf("a copy of the handler is still ready after Drain()")
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+const maxUsernameTries = 5
+
+var (
+	OIDCDisabled           = errors.New("Single sign-on is not configured")
+	OIDCStateInvalid       = errors.New("Single sign-on was started in another browser or has expired")
+	OIDCLoginFailed        = errors.New("Single sign-on failed")
+	OIDCEmailNotVerified   = errors.New("The provider didn't confirm your email address")
+	OIDCAccountUnverified  = errors.New("An account with this email exists but isn't verified, log in with your password and verify it first")
+	OIDCUsernameExhausted  = errors.New("Couldn't find a free username, register with a password instead")
+	usernameForbiddenChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// OIDCLogin is a started login. State and Nonce have to come back with the
+// callback, the router keeps them in a cookie meanwhile.
+type OIDCLogin struct {
+	Url   string
+	State string
+	Nonce string
+}
+
+// OIDCHandler logs users in through an external OpenID Connect provider and
+// links the provider identities to accounts. Without a provider it is off.
+type OIDCHandler struct {
+	DB          db.Querier
+	UserHandler UserHandler
+	Provider    utils.OIDCProvider
+	Env         config.Config
+}
+
+func NewOIDCHandler(DBTX db.Querier, env config.Config, u UserHandler, provider utils.OIDCProvider) *OIDCHandler {
+	return &OIDCHandler{
+		DB:          DBTX,
+		UserHandler: u,
+		Provider:    provider,
+		Env:         env,
+	}
+}
+
+func (h *OIDCHandler) Enabled() bool {
+	return h.Provider != nil
+}
+
+func (h *OIDCHandler) RedirectUrl() string {
+	return h.Env.ECHO.PublicUrl + "/api/oidc/callback"
+}
+
+// Start creates the state and nonce of a new login and the url of the provider
+// login page.
+func (h *OIDCHandler) Start() (OIDCLogin, error) {
+	if !h.Enabled() {
+		return OIDCLogin{}, NotFoundError(OIDCDisabled)
+	}
+
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return OIDCLogin{}, InternalError(err)
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return OIDCLogin{}, InternalError(err)
+	}
+
+	return OIDCLogin{
+		Url:   h.Provider.AuthCodeURL(state, nonce, h.RedirectUrl()),
+		State: state,
+		Nonce: nonce,
+	}, nil
+}
+
+// Finish redeems the code the provider sent back and returns the account the
+// identity belongs to.
+func (h *OIDCHandler) Finish(ctx context.Context, code string, nonce string) (db.User, error) {
+	if !h.Enabled() {
+		return db.User{}, NotFoundError(OIDCDisabled)
+	}
+
+	identity, err := h.Provider.Exchange(ctx, code, nonce, h.RedirectUrl())
+	if err != nil {
+		return db.User{}, UnauthorizedError(fmt.Errorf("%w: %s", OIDCLoginFailed, err))
+	}
+
+	return h.ResolveUser(ctx, identity)
+}
+
+// ResolveUser finds the account of identity. Identities seen before are
+// linked already. A new identity is linked to the account with the same
+// email, but only if both the provider and the account verified that email,
+// otherwise whoever registered the address first could take over the login.
+// Without such an account a new one is created.
+func (h *OIDCHandler) ResolveUser(ctx context.Context, identity utils.OIDCIdentity) (db.User, error) {
+	linked, err := h.DB.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	})
+	if err == nil {
+		user, err := h.UserHandler.GetUserById(ctx, linked.UserID)
+		if err != nil {
+			return db.User{}, DatabaseError(err)
+		}
+		return user, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, DatabaseError(err)
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" || !identity.EmailVerified {
+		return db.User{}, ForbiddenError(OIDCEmailNotVerified)
+	}
+
+	// a new account is only kept together with the link to its identity
+	var user db.User
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		user, err = h.UserHandler.GetUserByEmail(ctx, email)
+		if errors.Is(err, sql.ErrNoRows) {
+			user, err = h.createUser(ctx, identity, email)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return DatabaseError(err)
+		} else if !user.EmailVerifiedAt.Valid {
+			return ConflictError(OIDCAccountUnverified)
+		}
+
+		_, err = h.DB.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  identity.Issuer,
+			Subject: identity.Subject,
+			Email:   email,
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	return user, nil
+}
+
+// createUser registers an account for a new identity. It gets a random
+// password, so it can only log in through the provider until the user resets
+// the password. The provider already verified the email.
+func (h *OIDCHandler) createUser(ctx context.Context, identity utils.OIDCIdentity, email string) (db.User, error) {
+	username, err := h.freeUsername(ctx, identity, email)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	password, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return db.User{}, InternalError(err)
+	}
+
+	user, err := h.UserHandler.CreateUser(ctx, CreateUserInput{
+		Username: username,
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user, err = h.DB.VerifyUserEmailById(ctx, user.ID)
+	if err != nil {
+		return db.User{}, DatabaseError(err)
+	}
+	return user, nil
+}
+
+// freeUsername derives a username from the identity and appends a number
+// while it is taken.
+func (h *OIDCHandler) freeUsername(ctx context.Context, identity utils.OIDCIdentity, email string) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(email, "@")
+	}
+	base = strings.Trim(usernameForbiddenChars.ReplaceAllString(base, ""), ".-")
+	if base == "" {
+		base = "player"
+	}
+
+	for try := 0; try < maxUsernameTries; try++ {
+		username := base
+		if try > 0 {
+			username = fmt.Sprintf("%s%d", base, try+1)
+		}
+
+		_, err := h.UserHandler.GetUserByUsername(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		} else if err != nil {
+			return "", DatabaseError(err)
+		}
+	}
+	return "", ConflictError(OIDCUsernameExhausted)
+}
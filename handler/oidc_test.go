@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+// authorize follows the login url to the stub and returns the code it sends
+// back with.
+func authorize(t *testing.T, loginUrl string, state string) string {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(loginUrl)
+	if err != nil {
+		t.Fatalf("GET %s = %v, want nil", loginUrl, err)
+	}
+	res.Body.Close()
+
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("url.Parse() = %v, want nil", err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("callback state = %q, want %q", got, state)
+	}
+	return callback.Query().Get("code")
+}
+
+func TestOIDCHandler(t *testing.T) {
+	ctx := context.Background()
+	stub, err := utils.NewStubOIDCServer("tennis", "secret")
+	if err != nil {
+		t.Fatalf("NewStubOIDCServer() = %v, want nil", err)
+	}
+	defer stub.Close()
+
+	provider, err := utils.NewOIDCClient(ctx, stub.Config(), nil)
+	if err != nil {
+		t.Fatalf("NewOIDCClient() = %v, want nil", err)
+	}
+
+	dbMock := utils.NewDBQueriesMock()
+	userHandler := UserHandler{DB: dbMock}
+	env := config.Config{ECHO: config.EchoConfig{PublicUrl: "http://localhost:3000"}}
+	oidcHandler := OIDCHandler{DB: dbMock, UserHandler: userHandler, Provider: provider, Env: env}
+
+	login := func(t *testing.T, identity utils.OIDCIdentity) (userId string, err error) {
+		stub.LoginAs(identity)
+		started, err := oidcHandler.Start()
+		if err != nil {
+			t.Fatalf("Start() = %v, want nil", err)
+		}
+		code := authorize(t, started.Url, started.State)
+		user, err := oidcHandler.Finish(ctx, code, started.Nonce)
+		return user.ID.String(), err
+	}
+
+	t.Run("disabled without a provider", func(t *testing.T) {
+		disabled := OIDCHandler{DB: dbMock, UserHandler: userHandler, Env: env}
+		_, err := disabled.Start()
+		want := NotFoundError(OIDCDisabled)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("Start() = %v, want %v", err, want)
+		}
+	})
+
+	verified, err := userHandler.CreateUser(ctx, CreateUserInput{Username: "verified", Email: "verified@test.de", Password: "Test"})
+	if err != nil {
+		t.Fatalf("CreateUser() = %v, want nil", err)
+	}
+	verified, _ = dbMock.VerifyUserEmailById(ctx, verified.ID)
+	_, err = userHandler.CreateUser(ctx, CreateUserInput{Username: "unverified", Email: "unverified@test.de", Password: "Test"})
+	if err != nil {
+		t.Fatalf("CreateUser() = %v, want nil", err)
+	}
+
+	t.Run("unverified provider emails are rejected", func(t *testing.T) {
+		_, err := login(t, utils.OIDCIdentity{Subject: "1", Email: "verified@test.de"})
+		want := ForbiddenError(OIDCEmailNotVerified)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("Finish() = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("unverified accounts are not linked", func(t *testing.T) {
+		_, err := login(t, utils.OIDCIdentity{Subject: "2", Email: "unverified@test.de", EmailVerified: true})
+		want := ConflictError(OIDCAccountUnverified)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("Finish() = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("verified accounts are linked by email", func(t *testing.T) {
+		got, err := login(t, utils.OIDCIdentity{Subject: "3", Email: "verified@test.de", EmailVerified: true})
+		if err != nil {
+			t.Fatalf("Finish() = %v, want nil", err)
+		}
+		if got != verified.ID.String() {
+			t.Fatalf("Finish() user = %s, want %s", got, verified.ID)
+		}
+
+		// the link holds even after the email changed at the provider
+		got, err = login(t, utils.OIDCIdentity{Subject: "3", Email: "changed@test.de"})
+		if err != nil || got != verified.ID.String() {
+			t.Fatalf("Finish() = %s, %v, want %s, nil", got, err, verified.ID)
+		}
+	})
+
+	t.Run("new identities get a verified account", func(t *testing.T) {
+		got, err := login(t, utils.OIDCIdentity{Subject: "4", Email: "new@test.de", EmailVerified: true, PreferredUsername: "verified"})
+		if err != nil {
+			t.Fatalf("Finish() = %v, want nil", err)
+		}
+
+		user, err := userHandler.GetUserByEmail(ctx, "new@test.de")
+		if err != nil {
+			t.Fatalf("GetUserByEmail() = %v, want nil", err)
+		}
+		if user.ID.String() != got || user.Username != "verified2" || !user.EmailVerifiedAt.Valid {
+			t.Fatalf("created user = %+v, want verified user verified2", user)
+		}
+	})
+
+	t.Run("codes are bound to the nonce", func(t *testing.T) {
+		stub.LoginAs(utils.OIDCIdentity{Subject: "3"})
+		started, _ := oidcHandler.Start()
+		code := authorize(t, started.Url, started.State)
+		_, err := oidcHandler.Finish(ctx, code, "other")
+		if KindOf(err) != KindUnauthorized {
+			t.Fatalf("Finish() = %v, want 401", err)
+		}
+	})
+}
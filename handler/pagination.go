@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultPageSize = 50
+
+var CursorInvalid = errors.New("Cursor is not valid for this list")
+
+// ListInput are the query parameters every list takes. Lists are sorted by one
+// field and cut into pages with a cursor, Cursor is the NextCursor of the page
+// before or empty for the first page.
+type ListInput struct {
+	Cursor        string     `query:"cursor"`
+	Limit         int32      `query:"limit" validate:"min=1,max=100"`
+	Name          string     `query:"name" validate:"max=100"`
+	CreatedAfter  *time.Time `query:"createdAfter"`
+	CreatedBefore *time.Time `query:"createdBefore"`
+}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// cursor is the sort value and id of the last row of a page, the next page
+// starts right after that row. Sort is part of it, so a cursor can't be used
+// with another order.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func (c cursor) encode() string {
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(raw string, sort string) (*cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ValidationError(CursorInvalid)
+	}
+	c := new(cursor)
+	if err := json.Unmarshal(decoded, c); err != nil || c.Sort != sort || c.ID == uuid.Nil {
+		return nil, ValidationError(CursorInvalid)
+	}
+	return c, nil
+}
+
+// keyset is what the list queries need to find a page: the order to sort by,
+// which picks the query, and, after the first page, the row the page starts
+// after.
+type keyset struct {
+	sort            string
+	Column          string
+	CursorID        *uuid.UUID
+	CursorName      string
+	CursorCreatedAt time.Time
+	PageSize        int32
+}
+
+// keyset reads the sort, cursor and limit of the input. sort is the requested
+// order, a field from columns with a leading "-" to sort descending. Columns
+// map the fields to the query's column names, only created_at holds a time.
+func (input ListInput) keyset(sort string, columns map[string]string) (keyset, error) {
+	field := strings.TrimPrefix(sort, "-")
+	k := keyset{sort: sort, Column: columns[field], PageSize: defaultPageSize}
+	if input.Limit > 0 {
+		k.PageSize = input.Limit
+	}
+
+	c, err := decodeCursor(input.Cursor, sort)
+	if err != nil || c == nil {
+		return k, err
+	}
+	k.CursorID = &c.ID
+	if k.Column != "created_at" {
+		k.CursorName = c.Value
+		return k, nil
+	}
+	k.CursorCreatedAt, err = time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return k, ValidationError(CursorInvalid)
+	}
+	return k, nil
+}
+
+// after is the cursor of a row with the given id, name and creation time.
+func (k keyset) after(id uuid.UUID, name string, createdAt time.Time) cursor {
+	if k.Column == "created_at" {
+		name = createdAt.Format(time.RFC3339Nano)
+	}
+	return cursor{Sort: k.sort, Value: name, ID: id}
+}
+
+// newPage cuts rows to the page size. The queries fetch one row more than the
+// page holds, if it is there another page follows.
+func newPage[T any](rows []T, k keyset, last func(T) cursor) Page[T] {
+	if len(rows) <= int(k.PageSize) {
+		return Page[T]{Items: rows}
+	}
+	rows = rows[:k.PageSize]
+	return Page[T]{Items: rows, NextCursor: last(rows[len(rows)-1]).encode()}
+}
+
+// likePrefix escapes the wildcards of a LIKE pattern, so names are matched by
+// their literal beginning.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.TrimSpace(prefix))
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
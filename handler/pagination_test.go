@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestKeyset(t *testing.T) {
+	id := uuid.New()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	byName := keyset{sort: "-name", Column: "name"}
+	byCreation := keyset{sort: "createdAt", Column: "created_at"}
+
+	tests := []struct {
+		name  string
+		input ListInput
+		sort  string
+		want  keyset
+		err   error
+	}{
+		{"first page", ListInput{}, "name", keyset{sort: "name", Column: "name", PageSize: defaultPageSize}, nil},
+		{"limit and direction", ListInput{Limit: 10}, "-name", keyset{sort: "-name", Column: "name", PageSize: 10}, nil},
+		{"cursor of a name", ListInput{Cursor: byName.after(id, "Laurin", createdAt).encode()}, "-name",
+			keyset{sort: "-name", Column: "name", CursorID: &id, CursorName: "Laurin", PageSize: defaultPageSize}, nil},
+		{"cursor of a time", ListInput{Cursor: byCreation.after(id, "Laurin", createdAt).encode()}, "createdAt",
+			keyset{sort: "createdAt", Column: "created_at", CursorID: &id, CursorCreatedAt: createdAt, PageSize: defaultPageSize}, nil},
+		{"cursor of another sort", ListInput{Cursor: byName.after(id, "Laurin", createdAt).encode()}, "name", keyset{}, ValidationError(CursorInvalid)},
+		{"broken cursor", ListInput{Cursor: "not a cursor"}, "name", keyset{}, ValidationError(CursorInvalid)},
+	}
+	columns := map[string]string{"name": "name", "createdAt": "created_at"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.input.keyset(tt.sort, columns)
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Fatalf("keyset() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("keyset() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	k := keyset{sort: "name", Column: "name", PageSize: 2}
+	last := func(name string) cursor { return k.after(uuid.Nil, name, time.Time{}) }
+
+	page := newPage([]string{"a", "b", "c"}, k, last)
+	if !reflect.DeepEqual(page.Items, []string{"a", "b"}) || page.NextCursor != last("b").encode() {
+		t.Fatalf("newPage() = %+v, want the first two rows and a cursor after b", page)
+	}
+
+	page = newPage([]string{"a", "b"}, k, last)
+	if !reflect.DeepEqual(page.Items, []string{"a", "b"}) || page.NextCursor != "" {
+		t.Fatalf("newPage() = %+v, want both rows without a cursor", page)
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	if got := likePrefix(` 100%_\ `); got != `100\%\_\\` {
+		t.Fatalf("likePrefix() = %q", got)
+	}
+}
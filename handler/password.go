@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	ResetTokenInvalid = errors.New("Reset token is invalid")
+	ResetTokenExpired = errors.New("Reset token is expired")
+)
+
+type (
+	PasswordResetRequestInput struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	PasswordResetConfirmInput struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,password,maxbytes=72"`
+		Confirm  string `json:"confirm" validate:"required,eqfield=Password"`
+	}
+
+	PasswordSetInput struct {
+		Password string `json:"password" validate:"required,password,maxbytes=72"`
+	}
+)
+
+type PasswordHandler struct {
+	DB           db.Querier
+	UserHandler  UserHandler
+	TokenHandler RefreshTokenHandler
+	Mailer       utils.Mailer
+	Env          config.Config
+}
+
+func NewPasswordHandler(
+	DBTX db.Querier,
+	env config.Config,
+	userHandler UserHandler,
+	tokenHandler RefreshTokenHandler,
+	mailer utils.Mailer,
+) *PasswordHandler {
+	return &PasswordHandler{
+		DB:           DBTX,
+		UserHandler:  userHandler,
+		TokenHandler: tokenHandler,
+		Mailer:       mailer,
+		Env:          env,
+	}
+}
+
+// RequestReset mails a single use reset link to the user with that email. The
+// work runs in the background and its errors are only logged, so known and
+// unknown addresses get the same answer in the same time and the endpoint
+// can't be used to find accounts.
+func (h *PasswordHandler) RequestReset(email string) {
+	go func() {
+		err := h.sendResetLink(context.Background(), email)
+		if err != nil {
+			log.Printf("could not send a password reset link: %v\n", err)
+		}
+	}()
+}
+
+// sendResetLink replaces the reset link of the user with that email and mails
+// the new one. Unknown addresses are skipped.
+func (h *PasswordHandler) sendResetLink(ctx context.Context, email string) error {
+	user, err := h.UserHandler.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return DatabaseError(err)
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		// only the latest link should work
+		err := q.DeletePasswordResetsByUserId(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		_, err = q.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+			UserID:     user.ID,
+			TokenHash:  utils.HashToken(token),
+			ExpiryDate: time.Now().Add(h.Env.AUTH.PasswordResetLifetime),
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	link := h.Env.ECHO.PublicUrl + "/reset-password?token=" + token
+	err = h.Mailer.Send(ctx, utils.Message{
+		To:      user.Email,
+		Subject: "Reset your Tennis Analysis password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nsomeone asked to reset the password of your account. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you didn't ask for this you can ignore this mail.\n",
+			user.Username,
+			link,
+			h.Env.AUTH.PasswordResetLifetime,
+		),
+	})
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return nil
+}
+
+// ConfirmReset sets the new password if the reset token is valid. All sessions
+// and personal access tokens of the user are ended afterwards.
+func (h *PasswordHandler) ConfirmReset(ctx context.Context, input PasswordResetConfirmInput) error {
+	if err := Validate(input); err != nil {
+		return err
+	}
+
+	reset, err := h.DB.GetPasswordResetByHash(ctx, utils.HashToken(input.Token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ValidationError(ResetTokenInvalid)
+	} else if err != nil {
+		return DatabaseError(err)
+	}
+
+	if reset.ExpiryDate.Before(time.Now()) {
+		return ValidationError(ResetTokenExpired)
+	}
+
+	return inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		_, err := h.DB.UsePasswordReset(ctx, reset.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ValidationError(ResetTokenInvalid)
+		} else if err != nil {
+			return DatabaseError(err)
+		}
+
+		_, err = h.UserHandler.UpdatePasswordById(ctx, reset.UserID, input.Password)
+		if err != nil {
+			return err
+		}
+
+		err = h.TokenHandler.DeleteTokenByUserId(ctx, reset.UserID)
+		if err != nil {
+			return err
+		}
+		return revokeTokensByUserId(ctx, q, reset.UserID)
+	})
+}
+
+// SetPassword sets a new password for the user without a reset token, for
+// operators of the instance. All sessions and personal access tokens of the
+// user are ended like after a reset.
+func (h *PasswordHandler) SetPassword(ctx context.Context, userID uuid.UUID, input PasswordSetInput) error {
+	if err := Validate(input); err != nil {
+		return err
+	}
+
+	return inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		_, err := h.UserHandler.UpdatePasswordById(ctx, userID, input.Password)
+		if err != nil {
+			return err
+		}
+
+		err = h.TokenHandler.DeleteTokenByUserId(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return revokeTokensByUserId(ctx, q, userID)
+	})
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// channelMailer hands every mail to a channel, so a test can wait for mails
+// sent in the background.
+type channelMailer chan utils.Message
+
+func (m channelMailer) Send(ctx context.Context, msg utils.Message) error {
+	m <- msg
+	return nil
+}
+
+func TestPasswordHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	env := config.Config{
+		AUTH: config.AuthConfig{PasswordResetLifetime: time.Hour},
+		ECHO: config.EchoConfig{PublicUrl: "http://localhost:3000"},
+	}
+	mailer := &utils.LogMailer{Out: io.Discard}
+	userHandler := UserHandler{DB: dbMock}
+	passwordHandler := PasswordHandler{
+		DB:           dbMock,
+		UserHandler:  userHandler,
+		TokenHandler: RefreshTokenHandler{DB: dbMock, Env: env},
+		Mailer:       mailer,
+		Env:          env,
+	}
+
+	user, err := userHandler.CreateUser(context.Background(), CreateUserInput{
+		Username: "laurin",
+		Email:    "laurin@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+
+	requestToken := func(t *testing.T) string {
+		err := passwordHandler.sendResetLink(context.Background(), user.Email)
+		if err != nil {
+			t.Fatalf("passwordHandler.sendResetLink(%s) = %v, want nil", user.Email, err)
+		}
+		msg, ok := mailer.LastTo(user.Email)
+		if !ok {
+			t.Fatalf("passwordHandler.sendResetLink(%s) didn't send a mail", user.Email)
+		}
+		_, token, found := strings.Cut(msg.Body, "/reset-password?token=")
+		if !found {
+			t.Fatalf("reset mail doesn't contain a link: %q", msg.Body)
+		}
+		return strings.Fields(token)[0]
+	}
+
+	t.Run("RequestReset mails in the background", func(t *testing.T) {
+		sent := make(chan utils.Message, 1)
+		background := passwordHandler
+		background.Mailer = channelMailer(sent)
+		background.RequestReset(user.Email)
+
+		select {
+		case msg := <-sent:
+			if msg.To != user.Email || !strings.Contains(msg.Body, "/reset-password?token=") {
+				t.Fatalf("passwordHandler.RequestReset(%s) sent %+v, want a reset link", user.Email, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("passwordHandler.RequestReset(%s) didn't send a mail", user.Email)
+		}
+	})
+
+	t.Run("sendResetLink unknown email", func(t *testing.T) {
+		err := passwordHandler.sendResetLink(context.Background(), "nobody@test.de")
+		if err != nil {
+			t.Fatalf("passwordHandler.sendResetLink(unknown) = %v, want nil", err)
+		}
+		if _, ok := mailer.LastTo("nobody@test.de"); ok {
+			t.Fatalf("passwordHandler.sendResetLink(unknown) sent a mail")
+		}
+	})
+
+	t.Run("ConfirmReset", func(t *testing.T) {
+		token := requestToken(t)
+		input := PasswordResetConfirmInput{Token: token, Password: "NewPass1", Confirm: "NewPass1"}
+
+		err := passwordHandler.ConfirmReset(context.Background(), input)
+		if err != nil {
+			t.Fatalf("passwordHandler.ConfirmReset() = %v, want nil", err)
+		}
+
+		updated, err := userHandler.GetUserById(context.Background(), user.ID)
+		if err != nil {
+			t.Fatalf("userHandler.GetUserById() = %v, want nil", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("NewPass1")) != nil {
+			t.Fatalf("passwordHandler.ConfirmReset() didn't update the password")
+		}
+
+		err = passwordHandler.ConfirmReset(context.Background(), input)
+		want := ValidationError(ResetTokenInvalid)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("passwordHandler.ConfirmReset() with used token = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("ConfirmReset old token", func(t *testing.T) {
+		oldToken := requestToken(t)
+		requestToken(t)
+
+		err := passwordHandler.ConfirmReset(context.Background(), PasswordResetConfirmInput{Token: oldToken, Password: "NewPass1", Confirm: "NewPass1"})
+		want := ValidationError(ResetTokenInvalid)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("passwordHandler.ConfirmReset() with replaced token = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("ConfirmReset expired", func(t *testing.T) {
+		passwordHandler.Env.AUTH.PasswordResetLifetime = -time.Minute
+		token := requestToken(t)
+		passwordHandler.Env.AUTH.PasswordResetLifetime = time.Hour
+
+		err := passwordHandler.ConfirmReset(context.Background(), PasswordResetConfirmInput{Token: token, Password: "NewPass1", Confirm: "NewPass1"})
+		want := ValidationError(ResetTokenExpired)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("passwordHandler.ConfirmReset() with expired token = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("ConfirmReset mismatch", func(t *testing.T) {
+		token := requestToken(t)
+		err := passwordHandler.ConfirmReset(context.Background(), PasswordResetConfirmInput{Token: token, Password: "NewPass1", Confirm: "Other"})
+		if err == nil {
+			t.Fatalf("passwordHandler.ConfirmReset() with mismatching confirmation = nil, want error")
+		}
+	})
+
+	t.Run("SetPassword", func(t *testing.T) {
+		_, err := dbMock.CreateToken(context.Background(), db.CreateTokenParams{UserID: user.ID, TokenHash: "session"})
+		if err != nil {
+			t.Fatalf("dbMock.CreateToken() = %v, want nil", err)
+		}
+		_, err = dbMock.CreatePersonalAccessToken(context.Background(), db.CreatePersonalAccessTokenParams{UserID: user.ID, Name: "script", TokenHash: "script"})
+		if err != nil {
+			t.Fatalf("dbMock.CreatePersonalAccessToken() = %v, want nil", err)
+		}
+
+		err = passwordHandler.SetPassword(context.Background(), user.ID, PasswordSetInput{Password: "SetPass1"})
+		if err != nil {
+			t.Fatalf("passwordHandler.SetPassword() = %v, want nil", err)
+		}
+		updated, _ := userHandler.GetUserById(context.Background(), user.ID)
+		if bcrypt.CompareHashAndPassword([]byte(updated.PasswordHash), []byte("SetPass1")) != nil {
+			t.Fatalf("passwordHandler.SetPassword() didn't update the password")
+		}
+		if sessions, _ := dbMock.GetAllTokensByUserId(context.Background(), user.ID); len(sessions) != 0 {
+			t.Fatalf("passwordHandler.SetPassword() kept %d sessions", len(sessions))
+		}
+		if tokens, _ := dbMock.GetPersonalAccessTokensByUserId(context.Background(), user.ID); len(tokens) != 0 {
+			t.Fatalf("passwordHandler.SetPassword() kept %d personal access tokens", len(tokens))
+		}
+
+		err = passwordHandler.SetPassword(context.Background(), user.ID, PasswordSetInput{Password: "weak"})
+		if kind := KindOf(err); kind != KindValidation {
+			t.Fatalf("passwordHandler.SetPassword(weak) = %v, want a validation error", err)
+		}
+		err = passwordHandler.SetPassword(context.Background(), uuid.New(), PasswordSetInput{Password: "SetPass1"})
+		if kind := KindOf(err); kind != KindNotFound {
+			t.Fatalf("passwordHandler.SetPassword(unknown user) = %v, want a not found error", err)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+// PersonalTokenPrefix marks personal access tokens so they can be told apart
+// from JWTs in the Authorization header.
+const PersonalTokenPrefix = "tat_"
+
+var (
+	PersonalTokenNameMissing = errors.New("Token name is missing")
+	PersonalTokenInvalid     = errors.New("Personal access token is invalid")
+	PersonalTokenExpired     = errors.New("Personal access token is expired")
+	PersonalTokenNotFound    = errors.New("Personal access token not found")
+	ScopeMissing             = errors.New("A token needs at least one scope")
+	ScopeInvalid             = errors.New("Unknown scope")
+	ScopeNotGranted          = errors.New("This token doesn't have the scope for this request")
+	ExpiryInvalid            = errors.New("Expiry has to be zero (never) or a positive number of days")
+)
+
+// Scope limits what a personal access token can be used for. Sessions from a
+// login are not limited by scopes. Every scope guards routes that accept
+// tokens through AllowToken, there are none for matches and stats yet.
+type Scope string
+
+const (
+	ScopePlayersRead  Scope = "players:read"
+	ScopePlayersWrite Scope = "players:write"
+	ScopeTeamsRead    Scope = "teams:read"
+	ScopeTeamsWrite   Scope = "teams:write"
+	ScopeClubsRead    Scope = "clubs:read"
+)
+
+var validScopes = []Scope{
+	ScopePlayersRead,
+	ScopePlayersWrite,
+	ScopeTeamsRead,
+	ScopeTeamsWrite,
+	ScopeClubsRead,
+}
+
+type CreatePersonalTokenInput struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"min=0,max=3650"`
+}
+
+// NewPersonalToken is a freshly created token together with its secret. The
+// secret is only stored hashed, so this is the only time it can be shown.
+type NewPersonalToken struct {
+	Token  db.PersonalAccessToken
+	Secret string
+}
+
+type PersonalTokenHandler struct {
+	DB          db.Querier
+	UserHandler UserHandler
+}
+
+func NewPersonalTokenHandler(DBTX db.Querier, u UserHandler) *PersonalTokenHandler {
+	return &PersonalTokenHandler{
+		DB:          DBTX,
+		UserHandler: u,
+	}
+}
+
+// IsPersonalToken reports whether a bearer token is a personal access token
+// rather than a JWT.
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// HasScope reports whether token was granted scope.
+func HasScope(token db.PersonalAccessToken, scope Scope) bool {
+	return slices.Contains(token.Scopes, string(scope))
+}
+
+// normalizeScopes checks every scope and returns them sorted without
+// duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ValidationError(ScopeMissing)
+	}
+
+	normalized := []string{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(validScopes, Scope(scope)) {
+			return nil, ValidationError(fmt.Errorf("%w: %s", ScopeInvalid, scope))
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// CreateToken issues a new personal access token for user. Tokens without an
+// expiry stay valid until they are revoked or the password of user changes.
+func (h *PersonalTokenHandler) CreateToken(ctx context.Context, user db.User, input CreatePersonalTokenInput) (NewPersonalToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return NewPersonalToken{}, ValidationError(PersonalTokenNameMissing)
+	}
+	if input.ExpiresInDays < 0 {
+		return NewPersonalToken{}, ValidationError(ExpiryInvalid)
+	}
+
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return NewPersonalToken{}, err
+	}
+
+	opaque, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return NewPersonalToken{}, InternalError(err)
+	}
+	secret := PersonalTokenPrefix + opaque
+
+	expiry := sql.NullTime{}
+	if input.ExpiresInDays > 0 {
+		expiry = sql.NullTime{Time: time.Now().AddDate(0, 0, input.ExpiresInDays), Valid: true}
+	}
+
+	token, err := h.DB.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+		UserID:     user.ID,
+		Name:       name,
+		TokenHash:  utils.HashToken(secret),
+		Scopes:     scopes,
+		ExpiryDate: expiry,
+	})
+	if err != nil {
+		return NewPersonalToken{}, DatabaseError(err)
+	}
+
+	return NewPersonalToken{Token: token, Secret: secret}, nil
+}
+
+func (h *PersonalTokenHandler) GetTokens(ctx context.Context, user db.User) ([]db.PersonalAccessToken, error) {
+	tokens, err := h.DB.GetPersonalAccessTokensByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, DatabaseError(err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken deletes one of the tokens of user, it stops working right away.
+func (h *PersonalTokenHandler) RevokeToken(ctx context.Context, user db.User, id uuid.UUID) (db.PersonalAccessToken, error) {
+	token, err := h.DB.DeletePersonalAccessToken(ctx, db.DeletePersonalAccessTokenParams{ID: id, UserID: user.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.PersonalAccessToken{}, NotFoundError(PersonalTokenNotFound)
+	} else if err != nil {
+		return db.PersonalAccessToken{}, DatabaseError(err)
+	}
+	return token, nil
+}
+
+// revokeTokensByUserId deletes every personal access token of the user. A
+// changed password ends all sessions, and a token is as good as a session.
+func revokeTokensByUserId(ctx context.Context, q db.Querier, userId uuid.UUID) error {
+	err := q.DeletePersonalAccessTokensByUserId(ctx, userId)
+	if err != nil {
+		return DatabaseError(err)
+	}
+
+	return nil
+}
+
+// Authenticate looks up the token behind secret and the user it belongs to
+// and records that it was used.
+func (h *PersonalTokenHandler) Authenticate(ctx context.Context, secret string) (db.User, db.PersonalAccessToken, error) {
+	token, err := h.DB.GetPersonalAccessTokenByHash(ctx, utils.HashToken(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, db.PersonalAccessToken{}, UnauthorizedError(PersonalTokenInvalid)
+	} else if err != nil {
+		return db.User{}, db.PersonalAccessToken{}, DatabaseError(err)
+	}
+
+	if token.ExpiryDate.Valid && token.ExpiryDate.Time.Before(time.Now()) {
+		return db.User{}, db.PersonalAccessToken{}, UnauthorizedError(PersonalTokenExpired)
+	}
+
+	user, err := h.UserHandler.GetUserById(ctx, token.UserID)
+	if err != nil {
+		return db.User{}, db.PersonalAccessToken{}, UnauthorizedError(PersonalTokenInvalid)
+	}
+
+	err = h.DB.TouchPersonalAccessToken(ctx, token.ID)
+	if err != nil {
+		return db.User{}, db.PersonalAccessToken{}, DatabaseError(err)
+	}
+
+	return user, token, nil
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestPersonalTokenHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	userHandler := UserHandler{DB: dbMock}
+	tokenHandler := PersonalTokenHandler{DB: dbMock, UserHandler: userHandler}
+	ctx := context.Background()
+
+	user, err := userHandler.CreateUser(ctx, CreateUserInput{
+		Username: "scripter",
+		Email:    "scripter@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+
+	t.Run("invalid input is rejected", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input CreatePersonalTokenInput
+			want  error
+		}{
+			{"no name", CreatePersonalTokenInput{Scopes: []string{"players:read"}}, PersonalTokenNameMissing},
+			{"no scopes", CreatePersonalTokenInput{Name: "script"}, ScopeMissing},
+			{"negative expiry", CreatePersonalTokenInput{Name: "script", Scopes: []string{"players:read"}, ExpiresInDays: -1}, ExpiryInvalid},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := tokenHandler.CreateToken(ctx, user, tt.input)
+				want := ValidationError(tt.want)
+				if !reflect.DeepEqual(err, want) {
+					t.Fatalf("CreateToken() = %v, want %v", err, want)
+				}
+			})
+		}
+
+		_, err := tokenHandler.CreateToken(ctx, user, CreatePersonalTokenInput{Name: "script", Scopes: []string{"matches:write"}})
+		if KindOf(err) != KindValidation {
+			t.Fatalf("CreateToken() with unknown scope = %v, want 400", err)
+		}
+	})
+
+	created, err := tokenHandler.CreateToken(ctx, user, CreatePersonalTokenInput{
+		Name:   " roster sync ",
+		Scopes: []string{"teams:write", "players:read", "teams:write"},
+	})
+	if err != nil {
+		t.Fatalf("CreateToken() = %v, want nil", err)
+	}
+
+	t.Run("token is created hashed with sorted scopes", func(t *testing.T) {
+		if !IsPersonalToken(created.Secret) {
+			t.Fatalf("secret %q doesn't start with %q", created.Secret, PersonalTokenPrefix)
+		}
+		if created.Token.TokenHash == created.Secret || strings.Contains(created.Token.TokenHash, created.Secret) {
+			t.Fatal("token is stored in plain text")
+		}
+		if created.Token.Name != "roster sync" {
+			t.Fatalf("Name = %q, want %q", created.Token.Name, "roster sync")
+		}
+		if want := []string{"players:read", "teams:write"}; !reflect.DeepEqual(created.Token.Scopes, want) {
+			t.Fatalf("Scopes = %v, want %v", created.Token.Scopes, want)
+		}
+		if created.Token.ExpiryDate.Valid {
+			t.Fatal("token without expiresInDays expires")
+		}
+	})
+
+	t.Run("token authenticates its user", func(t *testing.T) {
+		got, token, err := tokenHandler.Authenticate(ctx, created.Secret)
+		if err != nil {
+			t.Fatalf("Authenticate() = %v, want nil", err)
+		}
+		if got.ID != user.ID {
+			t.Fatalf("Authenticate() user = %v, want %v", got.ID, user.ID)
+		}
+		if !HasScope(token, ScopeTeamsWrite) || HasScope(token, ScopePlayersWrite) {
+			t.Fatalf("HasScope() doesn't match scopes %v", token.Scopes)
+		}
+
+		tokens, _ := tokenHandler.GetTokens(ctx, user)
+		if len(tokens) != 1 || !tokens[0].LastUsedAt.Valid {
+			t.Fatalf("GetTokens() = %v, want one used token", tokens)
+		}
+	})
+
+	t.Run("unknown and expired tokens are rejected", func(t *testing.T) {
+		_, _, err := tokenHandler.Authenticate(ctx, PersonalTokenPrefix+"unknown")
+		want := UnauthorizedError(PersonalTokenInvalid)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("Authenticate() = %v, want %v", err, want)
+		}
+
+		expired, err := dbMock.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+			UserID:     user.ID,
+			Name:       "old",
+			TokenHash:  utils.HashToken(PersonalTokenPrefix + "old"),
+			Scopes:     []string{"clubs:read"},
+			ExpiryDate: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("CreatePersonalAccessToken() = %v, want nil", err)
+		}
+		_, _, err = tokenHandler.Authenticate(ctx, PersonalTokenPrefix+"old")
+		want = UnauthorizedError(PersonalTokenExpired)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("Authenticate() = %v, want %v", err, want)
+		}
+		tokenHandler.RevokeToken(ctx, user, expired.ID)
+	})
+
+	t.Run("revoked tokens stop working", func(t *testing.T) {
+		_, err := tokenHandler.RevokeToken(ctx, user, created.Token.ID)
+		if err != nil {
+			t.Fatalf("RevokeToken() = %v, want nil", err)
+		}
+		_, _, err = tokenHandler.Authenticate(ctx, created.Secret)
+		if err == nil {
+			t.Fatal("Authenticate() with revoked token = nil, want error")
+		}
+		_, err = tokenHandler.RevokeToken(ctx, user, created.Token.ID)
+		want := NotFoundError(PersonalTokenNotFound)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("RevokeToken() again = %v, want %v", err, want)
+		}
+	})
+}
@@ -7,16 +7,51 @@ import (
 	"github.com/google/uuid"
 )
 
+// PlayerListInput filters and sorts the players of a club. Name matches the
+// beginning of the full name or of the last name.
+type PlayerListInput struct {
+	ListInput
+	Sort string `query:"sort" validate:"oneof=firstName -firstName lastName -lastName createdAt -createdAt"`
+}
+
+var playerSortColumns = map[string]string{
+	"firstName": "first_name",
+	"lastName":  "last_name",
+	"createdAt": "created_at",
+}
+
 type PlayerHandler struct {
 	DB db.Querier
 }
 
-func NewPlayerHandler(DB *db.Queries) *PlayerHandler {
+func NewPlayerHandler(DB db.Querier) *PlayerHandler {
 	return &PlayerHandler{
 		DB: DB,
 	}
 }
 
+// CreatePlayer adds a player to a club. Players belong to a club through a
+// team of just them, so both are created together.
+func (h *PlayerHandler) CreatePlayer(ctx context.Context, args db.CreateNewTeamWithOnePlayerParams) (db.Player, error) {
+	var player db.Player
+	err := inTx(ctx, h.DB, func(q db.Querier) error {
+		team, err := q.CreateNewTeamWithOnePlayer(ctx, args)
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		player, err = q.GetPlayerById(ctx, team.PlayerOne)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.Player{}, err
+	}
+	return player, nil
+}
+
 func (h *PlayerHandler) GetPlayerById(ctx context.Context, id uuid.UUID) (db.Player, error) {
 	player, err := h.DB.GetPlayerById(ctx, id)
 	if err != nil {
@@ -40,3 +75,68 @@ func (h *PlayerHandler) UpdatePlayerById(ctx context.Context, arg db.UpdatePlaye
 	}
 	return player, nil
 }
+
+// ListPlayers returns one page of the players of a club with how many teams
+// and matches they play in, sorted by last name unless the input asks for
+// another order.
+func (h *PlayerHandler) ListPlayers(ctx context.Context, clubId uuid.UUID, input PlayerListInput) (Page[db.PlayerSummary], error) {
+	sort := input.Sort
+	if sort == "" {
+		sort = "lastName"
+	}
+	k, err := input.keyset(sort, playerSortColumns)
+	if err != nil {
+		return Page[db.PlayerSummary]{}, err
+	}
+
+	players, err := h.listPlayers(ctx, clubId, input, k)
+	if err != nil {
+		return Page[db.PlayerSummary]{}, DatabaseError(err)
+	}
+	return newPage(players, k, func(player db.PlayerSummary) cursor {
+		name := player.LastName
+		if k.Column == "first_name" {
+			name = player.FirstName
+		}
+		return k.after(player.ID, name, player.CreatedAt)
+	}), nil
+}
+
+// listPlayers runs the query for the order of k. Every order has a query of
+// its own with a plain ORDER BY, so it can walk the club's index on its sort
+// column.
+func (h *PlayerHandler) listPlayers(ctx context.Context, clubId uuid.UUID, input PlayerListInput, k keyset) ([]db.PlayerSummary, error) {
+	byName := db.ListPlayersByClubIdOrderByLastNameParams{
+		ClubID:        clubId,
+		NamePrefix:    likePrefix(input.Name),
+		CreatedAfter:  nullTime(input.CreatedAfter),
+		CreatedBefore: nullTime(input.CreatedBefore),
+		CursorID:      k.CursorID,
+		CursorName:    k.CursorName,
+		PageSize:      k.PageSize + 1,
+	}
+	byCreatedAt := db.ListPlayersByClubIdOrderByCreatedAtParams{
+		ClubID:          clubId,
+		NamePrefix:      byName.NamePrefix,
+		CreatedAfter:    byName.CreatedAfter,
+		CreatedBefore:   byName.CreatedBefore,
+		CursorID:        k.CursorID,
+		CursorCreatedAt: k.CursorCreatedAt,
+		PageSize:        byName.PageSize,
+	}
+
+	switch k.sort {
+	case "firstName":
+		return h.DB.ListPlayersByClubIdOrderByFirstName(ctx, db.ListPlayersByClubIdOrderByFirstNameParams(byName))
+	case "-firstName":
+		return h.DB.ListPlayersByClubIdOrderByFirstNameDesc(ctx, db.ListPlayersByClubIdOrderByFirstNameDescParams(byName))
+	case "-lastName":
+		return h.DB.ListPlayersByClubIdOrderByLastNameDesc(ctx, db.ListPlayersByClubIdOrderByLastNameDescParams(byName))
+	case "createdAt":
+		return h.DB.ListPlayersByClubIdOrderByCreatedAt(ctx, byCreatedAt)
+	case "-createdAt":
+		return h.DB.ListPlayersByClubIdOrderByCreatedAtDesc(ctx, db.ListPlayersByClubIdOrderByCreatedAtDescParams(byCreatedAt))
+	default:
+		return h.DB.ListPlayersByClubIdOrderByLastName(ctx, byName)
+	}
+}
@@ -2,16 +2,30 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
 )
 
+// TeamListInput filters and sorts the teams of a club. HasSecondPlayer keeps
+// only teams of two players when true and only single players when false.
+type TeamListInput struct {
+	ListInput
+	Sort            string `query:"sort" validate:"oneof=name -name createdAt -createdAt"`
+	HasSecondPlayer string `query:"hasSecondPlayer" validate:"oneof=true false"`
+}
+
+var teamSortColumns = map[string]string{
+	"name":      "name",
+	"createdAt": "created_at",
+}
+
 type TeamHandler struct {
 	DB db.Querier
 }
 
-func NewTeamHandler(DB *db.Queries) *TeamHandler {
+func NewTeamHandler(DB db.Querier) *TeamHandler {
 	return &TeamHandler{
 		DB: DB,
 	}
@@ -33,12 +47,62 @@ func (h *TeamHandler) CreateTeamWithTwoPlayers(ctx context.Context, args db.Crea
 	return team, nil
 }
 
-func (h *TeamHandler) GetAllTeamsByUserId(ctx context.Context, id uuid.UUID) ([]db.Team, error) {
-	teams, err := h.DB.GetAllTeamsByUserId(ctx, id)
+// ListTeams returns one page of the teams of a club, sorted by name unless the
+// input asks for another order.
+func (h *TeamHandler) ListTeams(ctx context.Context, clubId uuid.UUID, input TeamListInput) (Page[db.Team], error) {
+	sort := input.Sort
+	if sort == "" {
+		sort = "name"
+	}
+	k, err := input.keyset(sort, teamSortColumns)
+	if err != nil {
+		return Page[db.Team]{}, err
+	}
+
+	teams, err := h.listTeams(ctx, clubId, input, k)
 	if err != nil {
-		return []db.Team{}, err
+		return Page[db.Team]{}, DatabaseError(err)
+	}
+	return newPage(teams, k, func(team db.Team) cursor {
+		return k.after(team.ID, team.Name, team.CreatedAt)
+	}), nil
+}
+
+// listTeams runs the query for the order of k. Every order has a query of its
+// own with a plain ORDER BY, so it can walk the index of its sort column.
+func (h *TeamHandler) listTeams(ctx context.Context, clubId uuid.UUID, input TeamListInput, k keyset) ([]db.Team, error) {
+	hasPlayerTwo := sql.NullBool{Bool: input.HasSecondPlayer == "true", Valid: input.HasSecondPlayer != ""}
+	byName := db.ListTeamsByClubIdOrderByNameParams{
+		ClubID:        clubId,
+		NamePrefix:    likePrefix(input.Name),
+		HasPlayerTwo:  hasPlayerTwo,
+		CreatedAfter:  nullTime(input.CreatedAfter),
+		CreatedBefore: nullTime(input.CreatedBefore),
+		CursorID:      k.CursorID,
+		CursorName:    k.CursorName,
+		PageSize:      k.PageSize + 1,
+	}
+	byCreatedAt := db.ListTeamsByClubIdOrderByCreatedAtParams{
+		ClubID:          clubId,
+		NamePrefix:      byName.NamePrefix,
+		HasPlayerTwo:    hasPlayerTwo,
+		CreatedAfter:    byName.CreatedAfter,
+		CreatedBefore:   byName.CreatedBefore,
+		CursorID:        k.CursorID,
+		CursorCreatedAt: k.CursorCreatedAt,
+		PageSize:        byName.PageSize,
+	}
+
+	switch k.sort {
+	case "-name":
+		return h.DB.ListTeamsByClubIdOrderByNameDesc(ctx, db.ListTeamsByClubIdOrderByNameDescParams(byName))
+	case "createdAt":
+		return h.DB.ListTeamsByClubIdOrderByCreatedAt(ctx, byCreatedAt)
+	case "-createdAt":
+		return h.DB.ListTeamsByClubIdOrderByCreatedAtDesc(ctx, db.ListTeamsByClubIdOrderByCreatedAtDescParams(byCreatedAt))
+	default:
+		return h.DB.ListTeamsByClubIdOrderByName(ctx, byName)
 	}
-	return teams, nil
 }
 
 func (h *TeamHandler) GetTeamById(ctx context.Context, id uuid.UUID) (db.Team, error) {
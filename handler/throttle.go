@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+var TooManyLoginAttempts = errors.New("Too many failed login attempts")
+
+// LoginBlockedError is returned while an account or IP has to wait before the
+// next login attempt.
+type LoginBlockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", TooManyLoginAttempts.Error(), e.RetryAfter.Round(time.Second))
+}
+
+// HTTPError turns the error into a 429 response.
+func (e *LoginBlockedError) HTTPError() *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusTooManyRequests, e.Error())
+}
+
+type LoginThrottleHandler struct {
+	Store utils.LoginAttemptStore
+	Env   config.Config
+	Now   func() time.Time
+}
+
+func NewLoginThrottleHandler(store utils.LoginAttemptStore, env config.Config) *LoginThrottleHandler {
+	return &LoginThrottleHandler{
+		Store: store,
+		Env:   env,
+		Now:   time.Now,
+	}
+}
+
+func accountKey(userId uuid.UUID) string {
+	return "account:" + userId.String()
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (h *LoginThrottleHandler) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+func loginKeys(ip string, userId uuid.UUID) []string {
+	keys := []string{ipKey(ip)}
+	if userId != uuid.Nil {
+		keys = append(keys, accountKey(userId))
+	}
+	return keys
+}
+
+// Check returns a LoginBlockedError if the IP or the account isn't allowed to
+// try another login yet. Pass uuid.Nil while the account isn't known.
+func (h *LoginThrottleHandler) Check(ctx context.Context, ip string, userId uuid.UUID) error {
+	now := h.now()
+	for _, key := range loginKeys(ip, userId) {
+		attempt, err := h.Store.Get(ctx, key)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		if attempt.BlockedUntil.After(now) {
+			return &LoginBlockedError{RetryAfter: attempt.BlockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// Fail records a failed login for the IP and, if it is known, the account and
+// blocks them for as long as their failure counts ask for.
+func (h *LoginThrottleHandler) Fail(ctx context.Context, ip string, userId uuid.UUID) error {
+	cfg := h.Env.LOGIN
+	err := h.fail(ctx, ipKey(ip), cfg.IpFreeAttempts, cfg.IpLockoutThreshold)
+	if err != nil || userId == uuid.Nil {
+		return err
+	}
+	return h.fail(ctx, accountKey(userId), cfg.FreeAttempts, cfg.LockoutThreshold)
+}
+
+func (h *LoginThrottleHandler) fail(ctx context.Context, key string, freeAttempts int32, lockoutThreshold int32) error {
+	now := h.now()
+	attempt, err := h.Store.RecordFailure(ctx, key, now, now.Add(-h.Env.LOGIN.AttemptWindow))
+	if err != nil {
+		return DatabaseError(err)
+	}
+
+	wait := h.backoff(attempt, freeAttempts, lockoutThreshold)
+	if wait <= 0 {
+		return nil
+	}
+
+	err = h.Store.Block(ctx, key, now.Add(wait))
+	if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
+
+func (h *LoginThrottleHandler) backoff(attempt db.LoginAttempt, freeAttempts int32, lockoutThreshold int32) time.Duration {
+	cfg := h.Env.LOGIN
+	if lockoutThreshold > 0 && attempt.Failures >= lockoutThreshold {
+		return cfg.LockoutDuration
+	}
+	if attempt.Failures <= freeAttempts || cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	exponent := float64(attempt.Failures - freeAttempts - 1)
+	wait := time.Duration(float64(cfg.BaseDelay) * math.Pow(2, exponent))
+	if cfg.MaxDelay > 0 && (wait > cfg.MaxDelay || wait <= 0) {
+		return cfg.MaxDelay
+	}
+	return wait
+}
+
+// Succeed clears the failures of the account. Failures of the IP are kept, one
+// valid account must not reset the counter for guessing others.
+func (h *LoginThrottleHandler) Succeed(ctx context.Context, userId uuid.UUID) error {
+	err := h.Store.Reset(ctx, accountKey(userId))
+	if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+)
+
+func TestLoginThrottleHandler(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	env := config.Config{
+		LOGIN: config.LoginConfig{
+			FreeAttempts:       2,
+			IpFreeAttempts:     100,
+			BaseDelay:          time.Second,
+			MaxDelay:           10 * time.Second,
+			LockoutThreshold:   6,
+			IpLockoutThreshold: 0,
+			LockoutDuration:    15 * time.Minute,
+			AttemptWindow:      time.Hour,
+		},
+	}
+	throttle := LoginThrottleHandler{
+		Store: utils.NewMemoryLoginAttemptStore(),
+		Env:   env,
+		Now:   func() time.Time { return now },
+	}
+	ctx := context.Background()
+	ip := "127.0.0.1"
+	userId := uuid.New()
+
+	// retryAfter is the wait Check asks for, 0 if the login may go ahead.
+	retryAfter := func(t *testing.T) time.Duration {
+		err := throttle.Check(ctx, ip, userId)
+		var blocked *LoginBlockedError
+		if errors.As(err, &blocked) {
+			return blocked.RetryAfter
+		}
+		if err != nil {
+			t.Fatalf("throttle.Check() = %v, want nil or LoginBlockedError", err)
+		}
+		return 0
+	}
+	fail := func(t *testing.T) {
+		if err := throttle.Fail(ctx, ip, userId); err != nil {
+			t.Fatalf("throttle.Fail() = %v, want nil", err)
+		}
+	}
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+		for i, wait := range want {
+			fail(t)
+			if got := retryAfter(t); got != wait {
+				t.Fatalf("after %d failures throttle.Check() wants a wait of %s, want %s", i+1, got, wait)
+			}
+		}
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		fail(t)
+		if got := retryAfter(t); got != 15*time.Minute {
+			t.Fatalf("after reaching the threshold throttle.Check() wants a wait of %s, want 15m0s", got)
+		}
+
+		now = now.Add(16 * time.Minute)
+		if got := retryAfter(t); got != 0 {
+			t.Fatalf("after the lockout throttle.Check() wants a wait of %s, want 0", got)
+		}
+	})
+
+	t.Run("failures outside the window are forgotten", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		fail(t)
+		if got := retryAfter(t); got != 0 {
+			t.Fatalf("first failure in a new window wants a wait of %s, want 0", got)
+		}
+	})
+
+	t.Run("success resets the account", func(t *testing.T) {
+		fail(t)
+		fail(t)
+		if got := retryAfter(t); got == 0 {
+			t.Fatalf("throttle.Check() after three failures wants no wait")
+		}
+
+		if err := throttle.Succeed(ctx, userId); err != nil {
+			t.Fatalf("throttle.Succeed() = %v, want nil", err)
+		}
+		if got := retryAfter(t); got != 0 {
+			t.Fatalf("throttle.Check() after success wants a wait of %s, want 0", got)
+		}
+	})
+
+	t.Run("ip is throttled across accounts", func(t *testing.T) {
+		throttle.Env.LOGIN.IpFreeAttempts = 1
+		defer func() { throttle.Env.LOGIN.IpFreeAttempts = 100 }()
+
+		now = now.Add(2 * time.Hour)
+		if err := throttle.Fail(ctx, ip, uuid.Nil); err != nil {
+			t.Fatalf("throttle.Fail() = %v, want nil", err)
+		}
+		if err := throttle.Fail(ctx, ip, uuid.New()); err != nil {
+			t.Fatalf("throttle.Fail() = %v, want nil", err)
+		}
+		if got := retryAfter(t); got != time.Second {
+			t.Fatalf("throttle.Check() for a new account from a failing ip wants a wait of %s, want 1s", got)
+		}
+	})
+}
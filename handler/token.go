@@ -2,101 +2,141 @@ package handler
 
 import (
 	"context"
-	"net/http"
 	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/config"
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/Laurin-Notemann/tennis-analysis/utils"
 	"github.com/google/uuid"
-	"github.com/labstack/echo/v4"
 )
 
 type RefreshTokenHandler struct {
-	DB       db.Querier
-	TokenGen utils.TokenGenerator
-	Env      config.Config
+	DB  db.Querier
+	Env config.Config
 }
 
 func NewRefreshTokenHandler(
-	DBTX *db.Queries,
+	DBTX db.Querier,
 	env config.Config,
-	tokenGen utils.TokenGenerator,
 ) *RefreshTokenHandler {
 	return &RefreshTokenHandler{
-		DB:       DBTX,
-		Env:      env,
-		TokenGen: tokenGen,
+		DB:  DBTX,
+		Env: env,
 	}
 }
 
 type TokenHandlerInput struct {
-	UserId   uuid.UUID
-	Username string
-	Email    string
+	UserId      uuid.UUID
+	DeviceLabel string
 }
 
-func (h *RefreshTokenHandler) CreateTokenAndReturnUser(ctx context.Context, input TokenHandlerInput) (db.User, error) {
-	duration := time.Now().Add(utils.OneMonth)
-	tokeGenInput := utils.TokenGenInput{
-		UserId:        input.UserId,
-		Username:      input.Username,
-		Email:         input.Email,
-		ExpiryDate:    duration,
-		SigningKey:    h.Env.JWT.RefreshToken,
-		IsAccessToken: false,
-	}
-	signedRefreshToken, err := h.TokenGen.GenerateNewJwtToken(tokeGenInput)
+// newOpaqueToken returns a fresh refresh token and the hash it is stored under.
+// The plain token is only ever handed to the client.
+func newOpaqueToken() (string, string, error) {
+	token, err := utils.GenerateOpaqueToken()
 	if err != nil {
-		return db.User{}, err
+		return "", "", InternalError(err)
+	}
+	return token, utils.HashToken(token), nil
+}
+
+// CreateToken starts a new session for the user. Every device the user logs in
+// from gets its own refresh token, so sessions can be listed and revoked one by one.
+func (h *RefreshTokenHandler) CreateToken(ctx context.Context, input TokenHandlerInput) (db.RefreshToken, string, error) {
+	refreshToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return db.RefreshToken{}, "", err
 	}
 
 	createRefreshToken := db.CreateTokenParams{
-		Token:      signedRefreshToken,
-		ExpiryDate: duration,
-		UserID:     input.UserId,
+		UserID:      input.UserId,
+		TokenHash:   tokenHash,
+		ExpiryDate:  time.Now().Add(h.Env.SESSION.RefreshTokenLifetime),
+		DeviceLabel: input.DeviceLabel,
 	}
 
-	user, err := h.DB.CreateToken(ctx, createRefreshToken)
+	token, err := h.DB.CreateToken(ctx, createRefreshToken)
 	if err != nil {
-		return db.User{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.RefreshToken{}, "", DatabaseError(err)
 	}
-	return user, nil
+	return token, refreshToken, nil
 }
 
-func (h *RefreshTokenHandler) GetTokenByUserId(ctx context.Context, userId uuid.UUID) (db.RefreshToken, error) {
-	token, err := h.DB.GetTokenByUserId(ctx, userId)
+func (h *RefreshTokenHandler) GetTokenById(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	token, err := h.DB.GetTokenById(ctx, id)
 	if err != nil {
-		return db.RefreshToken{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.RefreshToken{}, err
 	}
 
 	return token, nil
 }
 
-func (h *RefreshTokenHandler) UpdateTokenByUserId(ctx context.Context, input TokenHandlerInput) (db.RefreshToken, error) {
-	duration := time.Now().Add(utils.OneMonth)
-	tokeGenInput := utils.TokenGenInput{
-		UserId:        input.UserId,
-		Username:      input.Username,
-		Email:         input.Email,
-		ExpiryDate:    duration,
-		SigningKey:    h.Env.JWT.RefreshToken,
-		IsAccessToken: false,
-	}
-	signedRefreshToken, err := h.TokenGen.GenerateNewJwtToken(tokeGenInput)
+func (h *RefreshTokenHandler) GetAllTokensByUserId(ctx context.Context, userId uuid.UUID) ([]db.RefreshToken, error) {
+	tokens, err := h.DB.GetAllTokensByUserId(ctx, userId)
+	if err != nil {
+		return []db.RefreshToken{}, DatabaseError(err)
+	}
+
+	return tokens, nil
+}
+
+func (h *RefreshTokenHandler) GetTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	token, err := h.DB.GetTokenByHash(ctx, tokenHash)
 	if err != nil {
 		return db.RefreshToken{}, err
 	}
 
-	updatedRefreshToken := db.UpdateTokenByUserIdParams{
-		Token:      signedRefreshToken,
-		ExpiryDate: duration,
-		UserID:     input.UserId,
+	return token, nil
+}
+
+func (h *RefreshTokenHandler) GetRotatedTokenByHash(ctx context.Context, tokenHash string) (db.RotatedRefreshToken, error) {
+	token, err := h.DB.GetRotatedTokenByHash(ctx, tokenHash)
+	if err != nil {
+		return db.RotatedRefreshToken{}, err
 	}
 
-	token, err := h.DB.UpdateTokenByUserId(ctx, updatedRefreshToken)
+	return token, nil
+}
+
+// RotateTokenById replaces the refresh token of a session with a new one. The old
+// token is kept as rotated so that a second use of it can be detected. The
+// session is only rotated while oldTokenHash is still its token, otherwise the
+// token was used twice and sql.ErrNoRows is returned.
+func (h *RefreshTokenHandler) RotateTokenById(ctx context.Context, id uuid.UUID, oldTokenHash string) (db.RefreshToken, string, error) {
+	refreshToken, tokenHash, err := newOpaqueToken()
 	if err != nil {
-		return db.RefreshToken{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.RefreshToken{}, "", err
+	}
+
+	rotateRefreshToken := db.RotateTokenByIdParams{
+		ID:           id,
+		OldTokenHash: oldTokenHash,
+		TokenHash:    tokenHash,
+		ExpiryDate:   time.Now().Add(h.Env.SESSION.RefreshTokenLifetime),
+	}
+
+	token, err := h.DB.RotateTokenById(ctx, rotateRefreshToken)
+	if err != nil {
+		return db.RefreshToken{}, "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+// TouchTokenById records that the session was just used and returns it.
+func (h *RefreshTokenHandler) TouchTokenById(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	token, err := h.DB.TouchTokenById(ctx, id)
+	if err != nil {
+		return db.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+func (h *RefreshTokenHandler) DeleteUserTokenById(ctx context.Context, id uuid.UUID, userId uuid.UUID) (db.RefreshToken, error) {
+	token, err := h.DB.DeleteUserTokenById(ctx, db.DeleteUserTokenByIdParams{ID: id, UserID: userId})
+	if err != nil {
+		return db.RefreshToken{}, err
 	}
 
 	return token, nil
@@ -105,7 +145,17 @@ func (h *RefreshTokenHandler) UpdateTokenByUserId(ctx context.Context, input Tok
 func (h *RefreshTokenHandler) DeleteTokenByUserId(ctx context.Context, userId uuid.UUID) error {
 	err := h.DB.DeleteTokenByUserId(ctx, userId)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return DatabaseError(err)
+	}
+
+	return nil
+}
+
+// DeleteOtherTokensByUserId ends every session of the user except the one given.
+func (h *RefreshTokenHandler) DeleteOtherTokensByUserId(ctx context.Context, userId uuid.UUID, keepId uuid.UUID) error {
+	err := h.DB.DeleteOtherTokensByUserId(ctx, db.DeleteOtherTokensByUserIdParams{UserID: userId, ID: keepId})
+	if err != nil {
+		return DatabaseError(err)
 	}
 
 	return nil
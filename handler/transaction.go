@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+// transactor is a querier that can run a unit of work in a transaction, like
+// db.Queries does.
+type transactor interface {
+	InTx(ctx context.Context, fn func(db.Querier) error) error
+}
+
+// inTx runs fn as one unit of work on q: either every write of fn is kept or,
+// when fn fails, none. Handlers take the querier fn gets with their withDB, so
+// the handlers they call write in the same transaction. Queriers that can't
+// start transactions run fn directly.
+func inTx(ctx context.Context, q db.Querier, fn func(db.Querier) error) error {
+	tx, ok := q.(transactor)
+	if !ok {
+		return fn(q)
+	}
+	err := tx.InTx(ctx, fn)
+	if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
+
+// The withDB methods return a copy of a handler, and of the handlers it calls,
+// that queries q.
+
+func (u UserHandler) withDB(q db.Querier) *UserHandler {
+	u.DB = q
+	return &u
+}
+
+func (h RefreshTokenHandler) withDB(q db.Querier) *RefreshTokenHandler {
+	h.DB = q
+	return &h
+}
+
+func (h EmailVerificationHandler) withDB(q db.Querier) *EmailVerificationHandler {
+	h.DB = q
+	h.UserHandler = *h.UserHandler.withDB(q)
+	return &h
+}
+
+func (r AuthenticationHandler) withDB(q db.Querier) *AuthenticationHandler {
+	r.DB = q
+	r.UserHandler = *r.UserHandler.withDB(q)
+	r.TokenHandler = *r.TokenHandler.withDB(q)
+	r.VerificationHandler = *r.VerificationHandler.withDB(q)
+	return &r
+}
+
+func (h AccountHandler) withDB(q db.Querier) *AccountHandler {
+	h.DB = q
+	h.UserHandler = *h.UserHandler.withDB(q)
+	h.TokenHandler = *h.TokenHandler.withDB(q)
+	h.VerificationHandler = *h.VerificationHandler.withDB(q)
+	return &h
+}
+
+func (h PasswordHandler) withDB(q db.Querier) *PasswordHandler {
+	h.DB = q
+	h.UserHandler = *h.UserHandler.withDB(q)
+	h.TokenHandler = *h.TokenHandler.withDB(q)
+	return &h
+}
+
+func (h OIDCHandler) withDB(q db.Querier) *OIDCHandler {
+	h.DB = q
+	h.UserHandler = *h.UserHandler.withDB(q)
+	return &h
+}
+
+func (h ClubHandler) withDB(q db.Querier) *ClubHandler {
+	h.DB = q
+	return &h
+}
+
+func (h TwoFactorHandler) withDB(q db.Querier) *TwoFactorHandler {
+	h.DB = q
+	return &h
+}
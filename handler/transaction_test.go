@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+)
+
+// txRecorder counts the units of work started on the mock. Units inside of
+// them run in the one already started and aren't counted. err fails the
+// commit.
+type txRecorder struct {
+	*utils.DBQueriesMock
+	units int
+	err   error
+}
+
+func (r *txRecorder) InTx(ctx context.Context, fn func(db.Querier) error) error {
+	r.units++
+	return r.DBQueriesMock.InTx(ctx, func(q db.Querier) error {
+		if err := fn(q); err != nil {
+			return err
+		}
+		return r.err
+	})
+}
+
+func TestInTx(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	env := config.Config{
+		SESSION: config.SessionConfig{RefreshTokenLifetime: time.Hour},
+		AUTH:    config.AuthConfig{EmailVerificationLifetime: time.Hour},
+	}
+	tx := &txRecorder{DBQueriesMock: dbMock}
+	mailer := &utils.LogMailer{Out: io.Discard}
+	userHandler := UserHandler{DB: tx, Env: env}
+	accountHandler := AccountHandler{
+		DB:           tx,
+		UserHandler:  userHandler,
+		TokenHandler: RefreshTokenHandler{DB: tx, Env: env},
+		VerificationHandler: EmailVerificationHandler{
+			DB:          tx,
+			UserHandler: userHandler,
+			Mailer:      mailer,
+			Env:         env,
+		},
+	}
+
+	user, err := userHandler.CreateUser(context.Background(), CreateUserInput{
+		Username: "laurin",
+		Email:    "laurin@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+	if tx.units != 1 {
+		t.Fatalf("userHandler.CreateUser() ran %d units of work, want 1", tx.units)
+	}
+	if _, err := dbMock.GetClubById(context.Background(), user.ID); err != nil {
+		t.Fatalf("userHandler.CreateUser() didn't create the personal club: %v", err)
+	}
+
+	t.Run("nested units run in the outer one", func(t *testing.T) {
+		tx.units = 0
+		_, err := accountHandler.UpdateAccount(context.Background(), user, UpdateAccountInput{Username: "laurin", Email: "max@test.de"})
+		if err != nil {
+			t.Fatalf("accountHandler.UpdateAccount() = %v, want nil", err)
+		}
+		if tx.units != 1 {
+			t.Fatalf("accountHandler.UpdateAccount() ran %d units of work, want 1", tx.units)
+		}
+	})
+
+	t.Run("failed commit", func(t *testing.T) {
+		tx.err = errors.New("commit failed")
+		defer func() { tx.err = nil }()
+
+		err := inTx(context.Background(), tx, func(db.Querier) error { return nil })
+		var domainErr *DomainError
+		if !errors.As(err, &domainErr) || domainErr.Kind != KindInternal {
+			t.Fatalf("inTx() = %v, want an internal error", err)
+		}
+	})
+
+	t.Run("no mail for a rolled back email", func(t *testing.T) {
+		tx.err = errors.New("commit failed")
+		defer func() { tx.err = nil }()
+
+		_, err := accountHandler.UpdateAccount(context.Background(), user, UpdateAccountInput{Username: "laurin", Email: "rolled@test.de"})
+		if err == nil {
+			t.Fatalf("accountHandler.UpdateAccount() = nil, want an error")
+		}
+		if _, sent := mailer.LastTo("rolled@test.de"); sent {
+			t.Fatalf("accountHandler.UpdateAccount() mailed a link that was rolled back")
+		}
+	})
+
+	t.Run("failed unit", func(t *testing.T) {
+		want := NotFoundError(errors.New("gone"))
+		club := db.CreateClubParams{ID: uuid.New(), Name: "rolled back"}
+		err := inTx(context.Background(), tx, func(q db.Querier) error {
+			if _, err := q.CreateClub(context.Background(), club); err != nil {
+				return err
+			}
+			return want
+		})
+		if err != want {
+			t.Fatalf("inTx() = %v, want %v", err, want)
+		}
+		if _, err := dbMock.GetClubById(context.Background(), club.ID); err != sql.ErrNoRows {
+			t.Fatalf("inTx() kept the club of a failed unit")
+		}
+	})
+}
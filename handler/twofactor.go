@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	recoveryCodeCount                = 10
+	maxTwoFactorChallengeTries       = 5
+	totpSkew                   int64 = 1
+)
+
+var (
+	TwoFactorAlreadyEnabled = errors.New("Two-factor authentication is already enabled")
+	TwoFactorNotEnrolled    = errors.New("Two-factor authentication has not been set up")
+	TwoFactorNotEnabled     = errors.New("Two-factor authentication is not enabled")
+	TwoFactorCodeInvalid    = errors.New("Two-factor code is invalid")
+	ChallengeInvalid        = errors.New("Login challenge is invalid")
+	ChallengeExpired        = errors.New("Login challenge is expired")
+)
+
+type (
+	TwoFactorEnrollment struct {
+		Secret          string `json:"secret"`
+		ProvisioningUri string `json:"provisioningUri"`
+	}
+
+	TwoFactorCodeInput struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	TwoFactorLoginInput struct {
+		ChallengeToken string `json:"challengeToken" validate:"required"`
+		Code           string `json:"code" validate:"required"`
+	}
+
+	// TwoFactorChallenge is what login returns instead of tokens while the
+	// second factor is still missing.
+	TwoFactorChallenge struct {
+		TwoFactorRequired bool      `json:"twoFactorRequired"`
+		ChallengeToken    string    `json:"challengeToken"`
+		ExpiryDate        time.Time `json:"expiryDate"`
+	}
+)
+
+type TwoFactorHandler struct {
+	DB  db.Querier
+	Env config.Config
+	Now func() time.Time
+}
+
+func NewTwoFactorHandler(DBTX db.Querier, env config.Config) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		DB:  DBTX,
+		Env: env,
+		Now: time.Now,
+	}
+}
+
+func (h *TwoFactorHandler) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+// IsEnabled reports whether the user has confirmed a TOTP secret.
+func (h *TwoFactorHandler) IsEnabled(ctx context.Context, userId uuid.UUID) (bool, error) {
+	totp, err := h.DB.GetTotpByUserId(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, DatabaseError(err)
+	}
+	return totp.ConfirmedAt.Valid, nil
+}
+
+// Enroll creates a new TOTP secret for the user. It only takes effect once it
+// is confirmed with a first code, enrolling again before that replaces it.
+func (h *TwoFactorHandler) Enroll(ctx context.Context, user db.User) (TwoFactorEnrollment, error) {
+	enabled, err := h.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	if enabled {
+		return TwoFactorEnrollment{}, ConflictError(TwoFactorAlreadyEnabled)
+	}
+
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		return TwoFactorEnrollment{}, InternalError(err)
+	}
+
+	_, err = h.DB.UpsertTotpSecret(ctx, db.UpsertTotpSecretParams{UserID: user.ID, Secret: secret})
+	if err != nil {
+		return TwoFactorEnrollment{}, DatabaseError(err)
+	}
+
+	return TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningUri: utils.TotpProvisioningUri(h.Env.AUTH.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables 2FA if code matches the enrolled secret and returns the
+// recovery codes. They are only stored hashed, so this is the only time the
+// user gets to see them.
+func (h *TwoFactorHandler) Confirm(ctx context.Context, user db.User, code string) ([]string, error) {
+	totp, err := h.DB.GetTotpByUserId(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ValidationError(TwoFactorNotEnrolled)
+	} else if err != nil {
+		return nil, DatabaseError(err)
+	}
+	if totp.ConfirmedAt.Valid {
+		return nil, ConflictError(TwoFactorAlreadyEnabled)
+	}
+
+	step, ok := utils.ValidateTotp(totp.Secret, code, h.now(), totpSkew)
+	if !ok {
+		return nil, ValidationError(TwoFactorCodeInvalid)
+	}
+
+	var codes []string
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		_, err := q.ConfirmTotpByUserId(ctx, db.ConfirmTotpByUserIdParams{UserID: user.ID, LastUsedStep: step})
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		codes, err = h.withDB(q).newRecoveryCodes(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (h *TwoFactorHandler) newRecoveryCodes(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	err := h.DB.DeleteRecoveryCodesByUserId(ctx, userId)
+	if err != nil {
+		return nil, DatabaseError(err)
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, InternalError(err)
+	}
+
+	for _, code := range codes {
+		_, err = h.DB.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{
+			UserID:   userId,
+			CodeHash: utils.HashToken(code),
+		})
+		if err != nil {
+			return nil, DatabaseError(err)
+		}
+	}
+
+	return codes, nil
+}
+
+// Disable turns 2FA off again. It needs a current code or a recovery code.
+func (h *TwoFactorHandler) Disable(ctx context.Context, user db.User, code string) error {
+	enabled, err := h.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ValidationError(TwoFactorNotEnabled)
+	}
+
+	return inTx(ctx, h.DB, func(q db.Querier) error {
+		h := h.withDB(q)
+		err := h.VerifyCode(ctx, user.ID, code)
+		if err != nil {
+			return err
+		}
+
+		err = h.DB.DeleteRecoveryCodesByUserId(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		err = h.DB.DeleteTotpByUserId(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+}
+
+// VerifyCode accepts either a TOTP code or an unused recovery code. A TOTP
+// code is only accepted once, so a code seen over the shoulder can't be replayed.
+func (h *TwoFactorHandler) VerifyCode(ctx context.Context, userId uuid.UUID, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) != utils.TotpDigits {
+		return h.useRecoveryCode(ctx, userId, strings.ToLower(code))
+	}
+
+	totp, err := h.DB.GetTotpByUserId(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ValidationError(TwoFactorNotEnabled)
+	} else if err != nil {
+		return DatabaseError(err)
+	}
+
+	step, ok := utils.ValidateTotp(totp.Secret, code, h.now(), totpSkew)
+	if !ok {
+		return UnauthorizedError(TwoFactorCodeInvalid)
+	}
+
+	_, err = h.DB.UseTotpStep(ctx, db.UseTotpStepParams{UserID: userId, LastUsedStep: step})
+	if errors.Is(err, sql.ErrNoRows) {
+		return UnauthorizedError(TwoFactorCodeInvalid)
+	} else if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
+
+func (h *TwoFactorHandler) useRecoveryCode(ctx context.Context, userId uuid.UUID, code string) error {
+	_, err := h.DB.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   userId,
+		CodeHash: utils.HashToken(code),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return UnauthorizedError(TwoFactorCodeInvalid)
+	} else if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
+
+// CreateChallenge is the first step of a 2FA login. The password was right,
+// the returned token has to be sent back together with a code.
+func (h *TwoFactorHandler) CreateChallenge(ctx context.Context, user db.User, deviceLabel string) (TwoFactorChallenge, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return TwoFactorChallenge{}, InternalError(err)
+	}
+
+	challenge, err := h.DB.CreateTwoFactorChallenge(ctx, db.CreateTwoFactorChallengeParams{
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		DeviceLabel: deviceLabel,
+		ExpiryDate:  h.now().Add(h.Env.AUTH.TwoFactorChallengeLifetime),
+	})
+	if err != nil {
+		return TwoFactorChallenge{}, DatabaseError(err)
+	}
+
+	return TwoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiryDate:        challenge.ExpiryDate,
+	}, nil
+}
+
+// VerifyChallenge is the second step of a 2FA login. It returns the challenge
+// once the code was right, a challenge can only be answered wrong a few times.
+// On a wrong code the challenge is returned with the error, so the caller knows
+// which account the failure belongs to.
+func (h *TwoFactorHandler) VerifyChallenge(ctx context.Context, input TwoFactorLoginInput) (db.TwoFactorChallenge, error) {
+	if input.ChallengeToken == "" {
+		return db.TwoFactorChallenge{}, UnauthorizedError(ChallengeInvalid)
+	}
+
+	challenge, err := h.DB.GetTwoFactorChallengeByHash(ctx, utils.HashToken(input.ChallengeToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.TwoFactorChallenge{}, UnauthorizedError(ChallengeInvalid)
+	} else if err != nil {
+		return db.TwoFactorChallenge{}, DatabaseError(err)
+	}
+
+	if challenge.ExpiryDate.Before(h.now()) {
+		return db.TwoFactorChallenge{}, h.dropChallenge(ctx, challenge, ChallengeExpired)
+	}
+
+	codeErr := h.VerifyCode(ctx, challenge.UserID, input.Code)
+	if codeErr != nil {
+		challenge, err = h.DB.CountTwoFactorChallengeAttempt(ctx, challenge.ID)
+		if err != nil {
+			return db.TwoFactorChallenge{}, DatabaseError(err)
+		}
+		if challenge.Attempts >= maxTwoFactorChallengeTries {
+			return challenge, h.dropChallenge(ctx, challenge, ChallengeInvalid)
+		}
+		return challenge, codeErr
+	}
+
+	err = h.DB.DeleteTwoFactorChallengeById(ctx, challenge.ID)
+	if err != nil {
+		return db.TwoFactorChallenge{}, DatabaseError(err)
+	}
+
+	return challenge, nil
+}
+
+func (h *TwoFactorHandler) dropChallenge(ctx context.Context, challenge db.TwoFactorChallenge, reason error) error {
+	err := h.DB.DeleteTwoFactorChallengeById(ctx, challenge.ID)
+	if err != nil {
+		return DatabaseError(err)
+	}
+	return UnauthorizedError(reason)
+}
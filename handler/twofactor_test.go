@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestTwoFactorHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	now := time.Now()
+	twoFactorHandler := TwoFactorHandler{
+		DB: dbMock,
+		Env: config.Config{
+			AUTH: config.AuthConfig{
+				TwoFactorIssuer:            "Tennis Analysis",
+				TwoFactorChallengeLifetime: 5 * time.Minute,
+			},
+		},
+		Now: func() time.Time { return now },
+	}
+	userHandler := UserHandler{DB: dbMock}
+	ctx := context.Background()
+
+	user, err := userHandler.CreateUser(ctx, CreateUserInput{
+		Username: "laurin",
+		Email:    "laurin@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+
+	wantErr := func(t *testing.T, err error, code int, msg error) {
+		t.Helper()
+		if KindOf(err).Status() != code || !errors.Is(err, msg) {
+			t.Fatalf("got error %v, want %d %v", err, code, msg)
+		}
+	}
+	codeAt := func(t *testing.T, secret string, at time.Time) string {
+		t.Helper()
+		code, err := utils.TotpCode(secret, utils.TotpStep(at))
+		if err != nil {
+			t.Fatalf("utils.TotpCode() = %v", err)
+		}
+		return code
+	}
+
+	var secret string
+	var recoveryCodes []string
+
+	t.Run("Enroll and Confirm", func(t *testing.T) {
+		enrollment, err := twoFactorHandler.Enroll(ctx, user)
+		if err != nil {
+			t.Fatalf("twoFactorHandler.Enroll() = %v, want nil", err)
+		}
+		secret = enrollment.Secret
+
+		enabled, _ := twoFactorHandler.IsEnabled(ctx, user.ID)
+		if enabled {
+			t.Fatalf("twoFactorHandler.IsEnabled() before confirmation = true, want false")
+		}
+
+		_, err = twoFactorHandler.Confirm(ctx, user, "000000")
+		if code, _ := utils.TotpCode(secret, utils.TotpStep(now)); code != "000000" {
+			wantErr(t, err, http.StatusBadRequest, TwoFactorCodeInvalid)
+		}
+
+		recoveryCodes, err = twoFactorHandler.Confirm(ctx, user, codeAt(t, secret, now))
+		if err != nil {
+			t.Fatalf("twoFactorHandler.Confirm() = %v, want nil", err)
+		}
+		if len(recoveryCodes) != recoveryCodeCount {
+			t.Fatalf("twoFactorHandler.Confirm() returned %d recovery codes, want %d", len(recoveryCodes), recoveryCodeCount)
+		}
+
+		enabled, _ = twoFactorHandler.IsEnabled(ctx, user.ID)
+		if !enabled {
+			t.Fatalf("twoFactorHandler.IsEnabled() after confirmation = false, want true")
+		}
+
+		_, err = twoFactorHandler.Enroll(ctx, user)
+		wantErr(t, err, http.StatusConflict, TwoFactorAlreadyEnabled)
+	})
+
+	t.Run("VerifyCode rejects replays", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		code := codeAt(t, secret, now)
+		if err := twoFactorHandler.VerifyCode(ctx, user.ID, code); err != nil {
+			t.Fatalf("twoFactorHandler.VerifyCode() = %v, want nil", err)
+		}
+		err := twoFactorHandler.VerifyCode(ctx, user.ID, code)
+		wantErr(t, err, http.StatusUnauthorized, TwoFactorCodeInvalid)
+	})
+
+	t.Run("VerifyCode with recovery code", func(t *testing.T) {
+		if err := twoFactorHandler.VerifyCode(ctx, user.ID, recoveryCodes[0]); err != nil {
+			t.Fatalf("twoFactorHandler.VerifyCode(recovery code) = %v, want nil", err)
+		}
+		err := twoFactorHandler.VerifyCode(ctx, user.ID, recoveryCodes[0])
+		wantErr(t, err, http.StatusUnauthorized, TwoFactorCodeInvalid)
+	})
+
+	t.Run("challenge", func(t *testing.T) {
+		challenge, err := twoFactorHandler.CreateChallenge(ctx, user, "phone")
+		if err != nil {
+			t.Fatalf("twoFactorHandler.CreateChallenge() = %v, want nil", err)
+		}
+
+		now = now.Add(time.Minute)
+		verified, err := twoFactorHandler.VerifyChallenge(ctx, TwoFactorLoginInput{
+			ChallengeToken: challenge.ChallengeToken,
+			Code:           codeAt(t, secret, now),
+		})
+		if err != nil {
+			t.Fatalf("twoFactorHandler.VerifyChallenge() = %v, want nil", err)
+		}
+		if verified.UserID != user.ID || verified.DeviceLabel != "phone" {
+			t.Fatalf("twoFactorHandler.VerifyChallenge() = %+v, want challenge of %s from phone", verified, user.ID)
+		}
+
+		_, err = twoFactorHandler.VerifyChallenge(ctx, TwoFactorLoginInput{
+			ChallengeToken: challenge.ChallengeToken,
+			Code:           codeAt(t, secret, now.Add(time.Minute)),
+		})
+		wantErr(t, err, http.StatusUnauthorized, ChallengeInvalid)
+	})
+
+	t.Run("challenge allows only a few wrong codes", func(t *testing.T) {
+		challenge, _ := twoFactorHandler.CreateChallenge(ctx, user, "")
+		input := TwoFactorLoginInput{ChallengeToken: challenge.ChallengeToken, Code: "wrong-code"}
+
+		for i := 1; i < maxTwoFactorChallengeTries; i++ {
+			_, err := twoFactorHandler.VerifyChallenge(ctx, input)
+			wantErr(t, err, http.StatusUnauthorized, TwoFactorCodeInvalid)
+		}
+		_, err := twoFactorHandler.VerifyChallenge(ctx, input)
+		wantErr(t, err, http.StatusUnauthorized, ChallengeInvalid)
+
+		now = now.Add(time.Minute)
+		input.Code = codeAt(t, secret, now)
+		_, err = twoFactorHandler.VerifyChallenge(ctx, input)
+		wantErr(t, err, http.StatusUnauthorized, ChallengeInvalid)
+	})
+
+	t.Run("challenge expires", func(t *testing.T) {
+		challenge, _ := twoFactorHandler.CreateChallenge(ctx, user, "")
+		now = now.Add(10 * time.Minute)
+
+		_, err := twoFactorHandler.VerifyChallenge(ctx, TwoFactorLoginInput{
+			ChallengeToken: challenge.ChallengeToken,
+			Code:           codeAt(t, secret, now),
+		})
+		wantErr(t, err, http.StatusUnauthorized, ChallengeExpired)
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		if err := twoFactorHandler.Disable(ctx, user, codeAt(t, secret, now)); err != nil {
+			t.Fatalf("twoFactorHandler.Disable() = %v, want nil", err)
+		}
+		enabled, _ := twoFactorHandler.IsEnabled(ctx, user.ID)
+		if enabled {
+			t.Fatalf("twoFactorHandler.IsEnabled() after disabling = true, want false")
+		}
+	})
+}
@@ -2,14 +2,10 @@ package handler
 
 import (
 	"context"
-	"net/http"
-
-	"github.com/labstack/echo/v4"
 
 	"github.com/Laurin-Notemann/tennis-analysis/config"
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,7 +14,7 @@ type UserHandler struct {
 	Env config.Config
 }
 
-func NewUserHandler(DBTX *db.Queries, env config.Config) *UserHandler {
+func NewUserHandler(DBTX db.Querier, env config.Config) *UserHandler {
 	return &UserHandler{
 		DB:  DBTX,
 		Env: env,
@@ -34,7 +30,7 @@ type CreateUserInput struct {
 func (u *UserHandler) CreateUser(ctx context.Context, input CreateUserInput) (db.User, error) {
 	hashedPw, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return db.User{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.User{}, InternalError(err)
 	}
 
 	registeredUser := db.CreateUserParams{
@@ -43,17 +39,40 @@ func (u *UserHandler) CreateUser(ctx context.Context, input CreateUserInput) (db
 		PasswordHash: string(hashedPw),
 	}
 
-	user, err := u.DB.CreateUser(ctx, registeredUser)
-	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-		return db.User{}, echo.NewHTTPError(http.StatusConflict, err.Error())
-	}
+	var user db.User
+	err = inTx(ctx, u.DB, func(q db.Querier) error {
+		user, err = q.CreateUser(ctx, registeredUser)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return u.withDB(q).createPersonalClub(ctx, user)
+	})
 	if err != nil {
-		return db.User{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return db.User{}, err
 	}
 
 	return user, nil
 }
 
+// createPersonalClub gives a new account its own club. It shares the id of the
+// user, so the user id keeps working wherever a club id is expected.
+func (u *UserHandler) createPersonalClub(ctx context.Context, user db.User) error {
+	club, err := u.DB.CreateClub(ctx, db.CreateClubParams{ID: user.ID, Name: user.Username})
+	if err != nil {
+		return DatabaseError(err)
+	}
+
+	_, err = u.DB.UpsertClubMember(ctx, db.UpsertClubMemberParams{
+		ClubID: club.ID,
+		UserID: user.ID,
+		Role:   RoleOwner,
+	})
+	if err != nil {
+		return DatabaseError(err)
+	}
+	return nil
+}
+
 func (u *UserHandler) GetAllUsers(ctx context.Context) ([]db.User, error) {
 	users, err := u.DB.GetAllUsers(ctx)
 	if err != nil {
@@ -94,6 +113,22 @@ func (u *UserHandler) UpdateUserById(ctx context.Context, args db.UpdateUserById
 	return user, nil
 }
 
+func (u *UserHandler) UpdatePasswordById(ctx context.Context, id uuid.UUID, password string) (db.User, error) {
+	hashedPw, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return db.User{}, InternalError(err)
+	}
+
+	user, err := u.DB.UpdateUserPasswordById(ctx, db.UpdateUserPasswordByIdParams{
+		PasswordHash: string(hashedPw),
+		ID:           id,
+	})
+	if err != nil {
+		return db.User{}, DatabaseError(err)
+	}
+	return user, nil
+}
+
 func (u *UserHandler) DeleteUserById(ctx context.Context, id uuid.UUID) (db.User, error) {
 	user, err := u.DB.DeleteUserById(ctx, id)
 	if err != nil {
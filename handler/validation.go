@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const minPasswordLength = 8
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// FieldError is one invalid field of an input. Field is the name from the json
+// tag, or the query tag for query parameters, so clients can put the message
+// next to their form field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors are all invalid fields of an input.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Validate checks input against the rules in the validate tags of its fields
+// and returns every broken rule at once as validation error with FieldErrors.
+// Rules are separated by commas:
+//
+//	required   not empty, blank strings and uuid.Nil are empty as well
+//	min=n      strings have at least n characters, numbers are at least n
+//	max=n      strings have at most n characters, numbers are at most n
+//	maxbytes=n strings have at most n bytes in UTF-8, like bcrypt counts them
+//	email      a single email address without a name
+//	password   at least 8 characters with a letter and a digit
+//	eqfield=F  equal to field F of the same input
+//	oneof=a b  one of the values separated by spaces
+//
+// All rules but required accept empty values, so optional fields only need to
+// be valid when they are given.
+func Validate(input interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(input))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fieldErrs := validateStruct(value)
+	if len(fieldErrs) > 0 {
+		return ValidationError(fieldErrs)
+	}
+	return nil
+}
+
+func validateStruct(value reflect.Value) FieldErrors {
+	fieldErrs := FieldErrors{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fieldErrs = append(fieldErrs, validateStruct(value.Field(i))...)
+			continue
+		}
+
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			message := checkRule(value, value.Field(i), name, param)
+			if message != "" {
+				fieldErrs = append(fieldErrs, FieldError{Field: jsonName(field), Message: message})
+				break
+			}
+		}
+	}
+	return fieldErrs
+}
+
+func checkRule(parent reflect.Value, field reflect.Value, rule string, param string) string {
+	empty := isEmpty(field)
+	if rule == "required" {
+		if empty {
+			return "is required"
+		}
+		return ""
+	}
+	if empty {
+		return ""
+	}
+	field = reflect.Indirect(field)
+
+	switch rule {
+	case "min", "max":
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: %s needs a number, got %q", rule, param))
+		}
+		size, unit := measure(field)
+		if rule == "min" && size < limit {
+			return strings.TrimSpace(fmt.Sprintf("must be at least %d %s", limit, unit))
+		}
+		if rule == "max" && size > limit {
+			return strings.TrimSpace(fmt.Sprintf("must be at most %d %s", limit, unit))
+		}
+	case "maxbytes":
+		limit, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: %s needs a number, got %q", rule, param))
+		}
+		if len(field.String()) > limit {
+			return fmt.Sprintf("must be at most %d bytes", limit)
+		}
+	case "email":
+		address, err := mail.ParseAddress(field.String())
+		if err != nil || address.Address != field.String() {
+			return "must be a valid email address"
+		}
+	case "password":
+		if !strongPassword(field.String()) {
+			return fmt.Sprintf("must have at least %d characters with a letter and a digit", minPasswordLength)
+		}
+	case "eqfield":
+		other, ok := parent.Type().FieldByName(param)
+		if !ok {
+			panic("validate: eqfield names unknown field " + param)
+		}
+		if !reflect.DeepEqual(field.Interface(), parent.FieldByIndex(other.Index).Interface()) {
+			return "must match " + jsonName(other)
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		for _, option := range options {
+			if fmt.Sprint(field.Interface()) == option {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	default:
+		panic("validate: unknown rule " + rule)
+	}
+	return ""
+}
+
+func isEmpty(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return field.IsNil() || isEmpty(field.Elem())
+	case reflect.String:
+		return strings.TrimSpace(field.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	}
+	if field.Type() == uuidType {
+		return field.Interface().(uuid.UUID) == uuid.Nil
+	}
+	return field.IsZero()
+}
+
+// measure returns the size that min and max compare and what it counts.
+func measure(field reflect.Value) (int, string) {
+	switch field.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(field.String()), "characters"
+	case reflect.Slice, reflect.Map:
+		return field.Len(), "entries"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(field.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(field.Uint()), ""
+	}
+	panic("validate: min and max don't work on " + field.Kind().String())
+}
+
+func strongPassword(password string) bool {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	return letter && digit
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		name = field.Tag.Get("query")
+	}
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidate(t *testing.T) {
+	type input struct {
+		Name    string     `json:"name" validate:"required,min=3,max=5"`
+		Email   string     `json:"email" validate:"email"`
+		Secret  string     `json:"secret" validate:"password"`
+		Repeat  string     `json:"repeat" validate:"eqfield=Secret"`
+		Role    string     `json:"role" validate:"oneof=owner coach"`
+		Owner   uuid.UUID  `json:"owner" validate:"required"`
+		Partner *uuid.UUID `json:"partner" validate:"required"`
+		Days    int        `json:"days" validate:"min=0,max=10"`
+		Tags    []string   `json:"tags" validate:"max=2"`
+		Ignored string     `json:"-" validate:"required"`
+	}
+	id := uuid.New()
+	valid := input{Name: "max", Owner: id, Partner: &id, Ignored: "set"}
+
+	tests := []struct {
+		name  string
+		input input
+		want  FieldErrors
+	}{
+		{"optional fields may be empty", valid, nil},
+		{"every field is reported", input{Name: " ", Email: "Max <max@test.de>", Secret: "password", Repeat: "other", Role: "admin", Days: 11, Tags: []string{"a", "b", "c"}}, FieldErrors{
+			{Field: "name", Message: "is required"},
+			{Field: "email", Message: "must be a valid email address"},
+			{Field: "secret", Message: "must have at least 8 characters with a letter and a digit"},
+			{Field: "repeat", Message: "must match secret"},
+			{Field: "role", Message: "must be one of owner, coach"},
+			{Field: "owner", Message: "is required"},
+			{Field: "partner", Message: "is required"},
+			{Field: "days", Message: "must be at most 10"},
+			{Field: "tags", Message: "must be at most 2 entries"},
+			{Field: "Ignored", Message: "is required"},
+		}},
+		{"only the first broken rule counts", input{Name: "maximilian", Owner: id, Partner: &id, Ignored: "set"}, FieldErrors{
+			{Field: "name", Message: "must be at most 5 characters"},
+		}},
+		{"lengths count characters", input{Name: "äöü", Email: "max@test.de", Secret: "pässwört1", Repeat: "pässwört1", Role: "coach", Owner: id, Partner: &id, Ignored: "set"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.input)
+			var want error
+			if tt.want != nil {
+				want = ValidationError(tt.want)
+			}
+			if !reflect.DeepEqual(err, want) {
+				t.Fatalf("Validate(%+v) = %v, want %v", tt.input, err, want)
+			}
+		})
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	err := Validate(RegisterInput{Username: "laurin", Email: "laurin@test.de", Password: "Test1234", Confirm: "Test"})
+	want := ValidationError(FieldErrors{{Field: "confirm", Message: "must match password"}})
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Validate(RegisterInput) = %v, want %v", err, want)
+	}
+
+	// bcrypt only looks at the first 72 bytes, not characters
+	err = Validate(PasswordSetInput{Password: strings.Repeat("ä", 36) + "1"})
+	want = ValidationError(FieldErrors{{Field: "password", Message: "must be at most 72 bytes"}})
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Validate(PasswordSetInput) = %v, want %v", err, want)
+	}
+
+	err = Validate(ChangeRoleInput{Role: RoleViewer})
+	if err != nil {
+		t.Fatalf("Validate(ChangeRoleInput) = %v, want nil", err)
+	}
+
+	err = Validate(TeamListInput{ListInput: ListInput{Limit: 101}, Sort: "age"})
+	want = ValidationError(FieldErrors{
+		{Field: "limit", Message: "must be at most 100"},
+		{Field: "sort", Message: "must be one of name, -name, createdAt, -createdAt"},
+	})
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Validate(TeamListInput) = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+var (
+	VerificationTokenInvalid = errors.New("Verification token is invalid")
+	VerificationTokenExpired = errors.New("Verification token is expired")
+	EmailAlreadyVerified     = errors.New("Email is already verified")
+	EmailNotVerified         = errors.New("Email is not verified")
+)
+
+type VerifyEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type EmailVerificationHandler struct {
+	DB          db.Querier
+	UserHandler UserHandler
+	Mailer      utils.Mailer
+	Env         config.Config
+}
+
+func NewEmailVerificationHandler(
+	DBTX db.Querier,
+	env config.Config,
+	userHandler UserHandler,
+	mailer utils.Mailer,
+) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		DB:          DBTX,
+		UserHandler: userHandler,
+		Mailer:      mailer,
+		Env:         env,
+	}
+}
+
+// SendVerification mails a new verification link to the user. Links that were
+// sent before stop working.
+func (h *EmailVerificationHandler) SendVerification(ctx context.Context, user db.User) error {
+	mail, err := h.CreateVerification(ctx, user)
+	if err != nil {
+		return err
+	}
+	return h.MailVerification(ctx, mail)
+}
+
+// CreateVerification stores a new verification link for the user and returns
+// the mail with it, links that were created before stop working. Callers that
+// run it in a transaction pass the mail to MailVerification once the
+// transaction is committed, so no link is sent that was rolled back.
+func (h *EmailVerificationHandler) CreateVerification(ctx context.Context, user db.User) (utils.Message, error) {
+	if user.EmailVerifiedAt.Valid {
+		return utils.Message{}, ValidationError(EmailAlreadyVerified)
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return utils.Message{}, InternalError(err)
+	}
+
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		err := q.DeleteEmailVerificationsByUserId(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		_, err = q.CreateEmailVerification(ctx, db.CreateEmailVerificationParams{
+			UserID:     user.ID,
+			TokenHash:  utils.HashToken(token),
+			ExpiryDate: time.Now().Add(h.Env.AUTH.EmailVerificationLifetime),
+		})
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.Message{}, err
+	}
+
+	link := h.Env.ECHO.PublicUrl + "/verify-email?token=" + token
+	return utils.Message{
+		To:      user.Email,
+		Subject: "Verify your Tennis Analysis email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nplease confirm that this is your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username,
+			link,
+			h.Env.AUTH.EmailVerificationLifetime,
+		),
+	}, nil
+}
+
+// MailVerification sends a mail from CreateVerification.
+func (h *EmailVerificationHandler) MailVerification(ctx context.Context, mail utils.Message) error {
+	err := h.Mailer.Send(ctx, mail)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return nil
+}
+
+// mailCommittedVerification sends a mail from CreateVerification after the
+// change it belongs to was committed. A failure can't undo that change anymore,
+// so it is only logged and the user asks for a new link.
+func (h *EmailVerificationHandler) mailCommittedVerification(ctx context.Context, mail utils.Message) {
+	err := h.MailVerification(ctx, mail)
+	if err != nil {
+		log.Printf("sending a verification mail failed: %v\n", err)
+	}
+}
+
+// ConfirmVerification marks the email of the user the token was sent to as
+// verified.
+func (h *EmailVerificationHandler) ConfirmVerification(ctx context.Context, token string) (db.User, error) {
+	if token == "" {
+		return db.User{}, ValidationError(VerificationTokenInvalid)
+	}
+
+	verification, err := h.DB.GetEmailVerificationByHash(ctx, utils.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, ValidationError(VerificationTokenInvalid)
+	} else if err != nil {
+		return db.User{}, DatabaseError(err)
+	}
+
+	if verification.ExpiryDate.Before(time.Now()) {
+		return db.User{}, ValidationError(VerificationTokenExpired)
+	}
+
+	var user db.User
+	err = inTx(ctx, h.DB, func(q db.Querier) error {
+		user, err = q.VerifyUserEmailById(ctx, verification.UserID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+
+		err = q.DeleteEmailVerificationsByUserId(ctx, user.ID)
+		if err != nil {
+			return DatabaseError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return db.User{}, err
+	}
+
+	return user, nil
+}
+
+// AllowsLogin reports whether the user may start a new session under the
+// configured verification policy.
+func (h *EmailVerificationHandler) AllowsLogin(user db.User) error {
+	if h.Env.AUTH.RequireVerifiedEmail == config.RequireVerifiedLogin && !user.EmailVerifiedAt.Valid {
+		return ForbiddenError(EmailNotVerified)
+	}
+	return nil
+}
+
+// AllowsWrite reports whether the user may change data under the configured
+// verification policy.
+func (h *EmailVerificationHandler) AllowsWrite(user db.User) error {
+	policy := h.Env.AUTH.RequireVerifiedEmail
+	if (policy == config.RequireVerifiedLogin || policy == config.RequireVerifiedWrites) && !user.EmailVerifiedAt.Valid {
+		return ForbiddenError(EmailNotVerified)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+)
+
+func TestEmailVerificationHandler(t *testing.T) {
+	dbMock := utils.NewDBQueriesMock()
+	env := config.Config{
+		AUTH: config.AuthConfig{
+			EmailVerificationLifetime: time.Hour,
+			RequireVerifiedEmail:      config.RequireVerifiedWrites,
+		},
+		ECHO: config.EchoConfig{PublicUrl: "http://localhost:3000"},
+	}
+	mailer := &utils.LogMailer{Out: io.Discard}
+	userHandler := UserHandler{DB: dbMock}
+	verificationHandler := EmailVerificationHandler{
+		DB:          dbMock,
+		UserHandler: userHandler,
+		Mailer:      mailer,
+		Env:         env,
+	}
+
+	user, err := userHandler.CreateUser(context.Background(), CreateUserInput{
+		Username: "laurin",
+		Email:    "laurin@test.de",
+		Password: "Test",
+	})
+	if err != nil {
+		t.Fatalf("userHandler.CreateUser() = %v, want nil", err)
+	}
+
+	sendToken := func(t *testing.T, user db.User) string {
+		err := verificationHandler.SendVerification(context.Background(), user)
+		if err != nil {
+			t.Fatalf("verificationHandler.SendVerification() = %v, want nil", err)
+		}
+		msg, ok := mailer.LastTo(user.Email)
+		if !ok {
+			t.Fatalf("verificationHandler.SendVerification() didn't send a mail")
+		}
+		_, token, found := strings.Cut(msg.Body, "/verify-email?token=")
+		if !found {
+			t.Fatalf("verification mail doesn't contain a link: %q", msg.Body)
+		}
+		return strings.Fields(token)[0]
+	}
+
+	t.Run("policy before verification", func(t *testing.T) {
+		if err := verificationHandler.AllowsLogin(user); err != nil {
+			t.Fatalf("verificationHandler.AllowsLogin(unverified) = %v, want nil", err)
+		}
+		want := ForbiddenError(EmailNotVerified)
+		if err := verificationHandler.AllowsWrite(user); !reflect.DeepEqual(err, want) {
+			t.Fatalf("verificationHandler.AllowsWrite(unverified) = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("ConfirmVerification expired", func(t *testing.T) {
+		verificationHandler.Env.AUTH.EmailVerificationLifetime = -time.Minute
+		token := sendToken(t, user)
+		verificationHandler.Env.AUTH.EmailVerificationLifetime = time.Hour
+
+		_, err := verificationHandler.ConfirmVerification(context.Background(), token)
+		want := ValidationError(VerificationTokenExpired)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("verificationHandler.ConfirmVerification(expired) = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("ConfirmVerification", func(t *testing.T) {
+		oldToken := sendToken(t, user)
+		token := sendToken(t, user)
+
+		_, err := verificationHandler.ConfirmVerification(context.Background(), oldToken)
+		want := ValidationError(VerificationTokenInvalid)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("verificationHandler.ConfirmVerification(replaced) = %v, want %v", err, want)
+		}
+
+		verified, err := verificationHandler.ConfirmVerification(context.Background(), token)
+		if err != nil {
+			t.Fatalf("verificationHandler.ConfirmVerification() = %v, want nil", err)
+		}
+		if !verified.EmailVerifiedAt.Valid {
+			t.Fatalf("verificationHandler.ConfirmVerification() = %+v, want verified user", verified)
+		}
+		if err := verificationHandler.AllowsWrite(verified); err != nil {
+			t.Fatalf("verificationHandler.AllowsWrite(verified) = %v, want nil", err)
+		}
+
+		err = verificationHandler.SendVerification(context.Background(), verified)
+		want = ValidationError(EmailAlreadyVerified)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("verificationHandler.SendVerification(verified) = %v, want %v", err, want)
+		}
+	})
+}
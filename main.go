@@ -3,8 +3,14 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -45,35 +51,122 @@ func main() {
 		log.Fatal(err)
 	}
 
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	err = runCommand(ctx, dbCon, cfg, args)
+	dbCon.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve starts the api server and blocks until it stopped after SIGINT or
+// SIGTERM. Requests in flight get cfg.ECHO.ShutdownTimeout to finish.
+func serve(ctx context.Context, dbCon *sql.DB, cfg config.Config, args []string) error {
+	migrator, err := db.NewMigrator(dbCon)
+	if err != nil {
+		return err
+	}
+	err = checkSchema(ctx, migrator, cfg)
+	if err != nil {
+		return fmt.Errorf("can't start with this database: %w", err)
+	}
+
 	dbQueries := db.New(dbCon)
 
-	tokenGen := utils.ProdTokenGenerator{}
+	signingKeys, err := utils.NewKeySetFromConfig(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("can't load jwt signing keys: %w", err)
+	}
+	tokenGen := utils.ProdTokenGenerator{Keys: signingKeys}
 	userHandler := handler.NewUserHandler(dbQueries, cfg)
-	tokenHandler := handler.NewRefreshTokenHandler(dbQueries, cfg, &tokenGen)
-	authHanlder := handler.NewAuthenticationHandler(dbQueries, *userHandler, *tokenHandler, &tokenGen)
-  playerHandler := handler.NewPlayerHandler(dbQueries)
+	tokenHandler := handler.NewRefreshTokenHandler(dbQueries, cfg)
+
+	mailer, err := utils.NewMailer(cfg.MAIL)
+	if err != nil {
+		return fmt.Errorf("can't create mailer: %w", err)
+	}
+	verificationHandler := handler.NewEmailVerificationHandler(dbQueries, cfg, *userHandler, mailer)
+
+	authHanlder := handler.NewAuthenticationHandler(dbQueries, *userHandler, *tokenHandler, &tokenGen, *verificationHandler)
+	playerHandler := handler.NewPlayerHandler(dbQueries)
 	teamHandler := handler.NewTeamHandler(dbQueries)
+	passwordHandler := handler.NewPasswordHandler(dbQueries, cfg, *userHandler, *tokenHandler, mailer)
+	accountHandler := handler.NewAccountHandler(dbQueries, *userHandler, *tokenHandler, *verificationHandler)
+
+	loginAttemptStore, err := utils.NewLoginAttemptStore(cfg.LOGIN, dbQueries)
+	if err != nil {
+		return fmt.Errorf("can't create login attempt store: %w", err)
+	}
+	loginThrottleHandler := handler.NewLoginThrottleHandler(loginAttemptStore, cfg)
+	twoFactorHandler := handler.NewTwoFactorHandler(dbQueries, cfg)
+	authorizationHandler := handler.NewAuthorizationHandler(dbQueries, *userHandler)
+	clubHandler := handler.NewClubHandler(dbQueries, cfg, mailer)
+	personalTokenHandler := handler.NewPersonalTokenHandler(dbQueries, *userHandler)
+
+	var oidcProvider utils.OIDCProvider
+	if cfg.OIDC.Issuer != "" {
+		oidcProvider, err = utils.NewOIDCClient(ctx, cfg.OIDC, nil)
+		if err != nil {
+			return fmt.Errorf("can't reach oidc provider: %w", err)
+		}
+	}
+	oidcHandler := handler.NewOIDCHandler(dbQueries, cfg, *userHandler, oidcProvider)
+	healthHandler := handler.NewHealthHandler(dbCon, migrator)
 
 	resourceHandler := handler.ResourceHandlers{
-		UserHandler:  *userHandler,
-		TokenHandler: *tokenHandler,
-		AuthHandler:  *authHanlder,
-    PlayerHandler: *playerHandler,
-    TeamHandler: *teamHandler,
+		UserHandler:          *userHandler,
+		TokenHandler:         *tokenHandler,
+		AuthHandler:          *authHanlder,
+		PlayerHandler:        *playerHandler,
+		TeamHandler:          *teamHandler,
+		PasswordHandler:      *passwordHandler,
+		VerificationHandler:  *verificationHandler,
+		AccountHandler:       *accountHandler,
+		LoginThrottleHandler: *loginThrottleHandler,
+		TwoFactorHandler:     *twoFactorHandler,
+		AuthorizationHandler: *authorizationHandler,
+		ClubHandler:          *clubHandler,
+		PersonalTokenHandler: *personalTokenHandler,
+		OIDCHandler:          *oidcHandler,
+		HealthHandler:        *healthHandler,
 	}
 
 	server := api.NewApi(ctx, resourceHandler, &tokenGen)
 
-  echoPort := cfg.ECHO.Port
-  echoHost := cfg.ECHO.Host
+	echoPort := cfg.ECHO.Port
+	echoHost := cfg.ECHO.Host
 
-  echoString := echoHost +":"+ fmt.Sprint(echoPort)
+	echoString := echoHost + ":" + fmt.Sprint(echoPort)
 
-	err = server.Start(echoString)
-	if err != nil {
-		log.Fatal(err)
+	stop, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start(echoString)
+	}()
+
+	select {
+	case err = <-started:
+		return err
+	case <-stop.Done():
 	}
 
-	dbCon.Close()
+	log.Printf("shutting down, draining for %s\n", cfg.ECHO.DrainDelay)
+	healthHandler.Drain()
+	time.Sleep(cfg.ECHO.DrainDelay)
 
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, cfg.ECHO.ShutdownTimeout)
+	defer cancelShutdown()
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("requests didn't finish in time: %w", err)
+	}
+	if err = <-started; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
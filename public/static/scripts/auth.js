@@ -1,15 +1,18 @@
+import { getCsrfToken } from "./utils.js"
+
 export async function isLoggedIn() {
-  const accessToken = localStorage.getItem("access-token")
+  const refreshToken = localStorage.getItem("refresh-token")
 
   const body = {
-    accessToken,
+    refreshToken,
   }
 
   const res = await fetch("/api/refresh", {
     method: "POST",
     body: JSON.stringify(body),
     headers: {
-      "Content-Type": "application/json"
+      "Content-Type": "application/json",
+      "X-CSRF-Token": getCsrfToken()
     }
   })
 
@@ -39,13 +42,22 @@ export async function isLoggedIn() {
 
 export function setAccessTokenAndUser({ success, payload }) {
   if (success) {
-    localStorage.setItem("access-token", payload.accessToken);
-    localStorage.setItem("userId", payload.user.ID)
-    localStorage.setItem("username", payload.user.Username)
+    // with cookie sessions the tokens never reach the page
+    if (payload.accessToken) {
+      localStorage.setItem("access-token", payload.accessToken);
+    }
+    // without one a concurrent refresh of another tab stored the new one
+    if (payload.refreshToken) {
+      localStorage.setItem("refresh-token", payload.refreshToken);
+    }
+    localStorage.setItem("userId", payload.user.id)
+    localStorage.setItem("username", payload.user.username)
     return true
   } else {
     localStorage.clear("access-token")
+    localStorage.clear("refresh-token")
     localStorage.clear("userId")
+    localStorage.clear("clubId")
     localStorage.clear("username")
     return false
   }
@@ -1,4 +1,4 @@
-import { addMessage, displayErrorMessage, getHeaders } from "./utils.js";
+import { addMessage, displayErrorMessage, getActiveClubId, getHeaders } from "./utils.js";
 import { loadNavBar } from "./navbar.js";
 
 const createPlayer = document.querySelector(`[data-form="create-player-form"]`);
@@ -16,7 +16,7 @@ createPlayer.addEventListener("submit", async e => {
   const body = {
     firstName: data.get("first-name"),
     lastName: data.get("last-name"),
-    userId: localStorage.getItem("userId")
+    clubId: getActiveClubId()
   }
 
   const headers = getHeaders()
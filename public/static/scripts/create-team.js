@@ -1,5 +1,5 @@
 import { loadNavBar } from "./navbar.js";
-import { addMessage, displayErrorMessage, getHeaders, fetchAllPlayers } from "./utils.js";
+import { addMessage, displayErrorMessage, getActiveClubId, getHeaders, fetchAllPlayers } from "./utils.js";
 
 loadNavBar()
 
@@ -15,22 +15,22 @@ async function loadPlayerDropdown(dropdownEl, playerNumber) {
   const allPlayers = await fetchAllPlayers()
   allPlayers.map(player => {
     const playerButton = document.createElement("button")
-    playerButton.innerHTML = player.FirstName + " " + player.LastName
+    playerButton.innerHTML = player.firstName + " " + player.lastName
     playerButton.addEventListener("click", e => {
       e.preventDefault()
 
       if (playerNumber == 1) {
         const playerOneInput = document.querySelector(`[data-input="player-one"]`)
-        playerOneInput.value = player.ID
+        playerOneInput.value = player.id
         const playerOneButton = document.querySelector(`[data-button="choose-player-one"]`)
-        playerOneButton.innerHTML = player.FirstName + " " + player.LastName
+        playerOneButton.innerHTML = player.firstName + " " + player.lastName
         dropdownPOne.style.visibility = "hidden"
         dropdownPOne.visible = false
       } else if (playerNumber == 2) {
         const playerTwoInput = document.querySelector(`[data-input="player-two"]`)
-        playerTwoInput.value = player.ID
+        playerTwoInput.value = player.id
         const playerTwoButton = document.querySelector(`[data-button="choose-player-two"]`)
-        playerTwoButton.innerHTML = player.FirstName + " " + player.LastName
+        playerTwoButton.innerHTML = player.firstName + " " + player.lastName
         dropdownPTwo.style.visibility = "hidden"
         dropdownPTwo.visible = false
       }
@@ -105,12 +105,11 @@ createTeamForm.addEventListener("submit", async e => {
       teamName = nameInput
     }
 
-    const userId = localStorage.getItem("userId")
     const body = {
-      PlayerOne: playerOneId,
-      PlayerTwo: playerTwoId,
-      Name: teamName,
-      UserID: userId
+      playerOne: playerOneId,
+      playerTwo: playerTwoId,
+      name: teamName,
+      clubId: getActiveClubId()
     }
     const headers = getHeaders()
     const res = await fetch("/api/teams", {
@@ -0,0 +1,138 @@
+const descriptionEl = document.querySelector(`[data-text="docs-description"]`)
+const operationsEl = document.querySelector(`[data-list="docs-operations"]`)
+
+function element(tag, className, text) {
+  const el = document.createElement(tag)
+  if (className) {
+    el.classList.add(className)
+  }
+  if (text !== undefined) {
+    el.textContent = text
+  }
+  return el
+}
+
+function schemaName(schema) {
+  return schema.$ref.split("/").pop()
+}
+
+// describeSchema writes a schema as short text, objects by their name
+function describeSchema(schema) {
+  if (!schema) {
+    return ""
+  }
+  if (schema.$ref) {
+    return schemaName(schema)
+  }
+  if (schema.oneOf) {
+    return schema.oneOf.map(describeSchema).join(" or ")
+  }
+  if (schema.allOf) {
+    return schema.allOf.map(describeSchema).join(" and ") + (schema.nullable ? " or null" : "")
+  }
+  if (schema.type === "array") {
+    return describeSchema(schema.items) + "[]"
+  }
+  let text = schema.format ? `${schema.type} (${schema.format})` : schema.type || "any"
+  if (schema.enum) {
+    text += ": " + schema.enum.join(", ")
+  }
+  const limits = ["minLength", "maxLength", "minimum", "maximum", "minItems", "maxItems"]
+    .filter(limit => schema[limit] !== undefined)
+    .map(limit => `${limit} ${schema[limit]}`)
+  if (limits.length > 0) {
+    text += ` [${limits.join(", ")}]`
+  }
+  if (schema.nullable) {
+    text += " or null"
+  }
+  return text
+}
+
+function renderContent(title, content) {
+  const wrapper = element("div", "docs-content")
+  wrapper.appendChild(element("h4", null, title))
+  const schema = content && content["application/json"] && content["application/json"].schema
+  if (schema) {
+    wrapper.appendChild(element("code", null, describeSchema(schema)))
+  }
+  return wrapper
+}
+
+function renderOperation(path, method, operation) {
+  const details = element("details", "docs-operation")
+  const summary = element("summary")
+  summary.appendChild(element("span", "docs-method-" + method, method.toUpperCase()))
+  summary.appendChild(element("code", null, path))
+  summary.appendChild(element("span", "docs-summary", operation.summary))
+  details.appendChild(summary)
+
+  if (operation.description) {
+    details.appendChild(element("p", null, operation.description))
+  }
+  if (operation.security) {
+    details.appendChild(element("p", null, "Needs a logged in user."))
+  }
+  if (operation.parameters) {
+    const params = operation.parameters.map(param => `${param.name} (${param.in})`)
+    details.appendChild(element("p", null, "Parameters: " + params.join(", ")))
+  }
+  if (operation.requestBody) {
+    details.appendChild(renderContent("Request", operation.requestBody.content))
+  }
+  for (const [status, response] of Object.entries(operation.responses)) {
+    details.appendChild(renderContent(`${status}: ${response.description}`, response.content))
+  }
+  return details
+}
+
+function renderSchemas(schemas) {
+  const section = element("section", "docs-tag")
+  section.appendChild(element("h2", null, "Schemas"))
+  for (const [name, schema] of Object.entries(schemas)) {
+    const details = element("details", "docs-operation")
+    details.id = name
+    details.appendChild(element("summary", null, name))
+    const required = schema.required || []
+    const list = element("ul")
+    for (const [field, fieldSchema] of Object.entries(schema.properties || {})) {
+      const optional = required.includes(field) ? "" : "?"
+      const item = element("li")
+      item.appendChild(element("code", null, `${field}${optional}: ${describeSchema(fieldSchema)}`))
+      if (fieldSchema.description) {
+        item.appendChild(element("span", "docs-summary", fieldSchema.description))
+      }
+      list.appendChild(item)
+    }
+    details.appendChild(list)
+    section.appendChild(details)
+  }
+  return section
+}
+
+async function loadDocs() {
+  const res = await fetch("/api/openapi.json")
+  if (res.status != 200) {
+    descriptionEl.textContent = "Couldn't load the API documentation"
+    return
+  }
+  const spec = await res.json()
+  descriptionEl.textContent = spec.info.description
+
+  const tags = new Map()
+  for (const [path, methods] of Object.entries(spec.paths)) {
+    for (const [method, operation] of Object.entries(methods)) {
+      const tag = operation.tags[0]
+      if (!tags.has(tag)) {
+        const section = element("section", "docs-tag")
+        section.appendChild(element("h2", null, tag))
+        tags.set(tag, section)
+        operationsEl.appendChild(section)
+      }
+      tags.get(tag).appendChild(renderOperation(path, method, operation))
+    }
+  }
+  operationsEl.appendChild(renderSchemas(spec.components.schemas))
+}
+
+loadDocs()
@@ -25,9 +25,9 @@ editPlayerForm.addEventListener("submit", async e => {
 
   const data = new URLSearchParams(new FormData(e.target))
   const body = {
-    FirstName: data.get("first-name"),
-    LastName: data.get("last-name"),
-    ID: playerId
+    firstName: data.get("first-name"),
+    lastName: data.get("last-name"),
+    id: playerId
   }
 
   const res = await fetch("/api/players", {
@@ -1,4 +1,5 @@
 import { loadNavBar } from "./navbar.js"
+import { getActiveClubId } from "./utils.js"
 
 loadNavBar()
 
@@ -57,7 +58,7 @@ function testButon() {
     e.preventDefault()
 
     const token = localStorage.getItem("access-token")
-    const res = await fetch("/api/players/" + userId, {
+    const res = await fetch("/api/players/" + getActiveClubId(), {
       headers: {
         Authorization: "Bearer " + token
       }
@@ -0,0 +1,24 @@
+import { loadNavBar } from "./navbar.js";
+import { getHeaders } from "./utils.js";
+
+const messageEl = document.querySelector(`[data-text="join-message"]`);
+const token = new URLSearchParams(window.location.search).get("token")
+
+async function joinClub() {
+  const res = await fetch("/api/club-invitations/accept", {
+    method: "POST",
+    body: JSON.stringify({ token: token }),
+    headers: getHeaders()
+  })
+  if (res.status == 200) {
+    const club = await res.json()
+    localStorage.setItem("clubId", club.id)
+    messageEl.innerHTML = "You joined the club as " + club.role + "."
+  } else {
+    const payload = await res.json()
+    messageEl.innerHTML = payload.message
+  }
+}
+
+joinClub()
+loadNavBar()
@@ -1,8 +1,11 @@
-import { setAccessTokenAndUser } from "./auth.js";
+import { isLoggedIn, setAccessTokenAndUser } from "./auth.js";
 import { loadNavBar } from "./navbar.js";
 import { displayErrorMessage } from "./utils.js";
 
 const loginUserForm = document.querySelector(`[data-form="login-user-form"]`);
+const twoFactorForm = document.querySelector(`[data-form="two-factor-form"]`);
+const oidcLoginLink = document.querySelector(`[data-link="oidc-login"]`);
+let challengeToken = ""
 
 loginUserForm.addEventListener("submit", async e => {
   e.preventDefault();
@@ -23,6 +26,12 @@ loginUserForm.addEventListener("submit", async e => {
   })
   if (res.status == 200) {
     const userPayload = await res.json()
+    if (userPayload.twoFactorRequired) {
+      challengeToken = userPayload.challengeToken
+      loginUserForm.hidden = true
+      twoFactorForm.hidden = false
+      return
+    }
     setAccessTokenAndUser({ success: true, payload: userPayload })
     window.location.href = "/"
   }
@@ -31,5 +40,59 @@ loginUserForm.addEventListener("submit", async e => {
   }
 })
 
+twoFactorForm.addEventListener("submit", async e => {
+  e.preventDefault();
+
+  const data = new URLSearchParams(new FormData(e.target))
+
+  const res = await fetch("/api/login/2fa", {
+    method: "POST",
+    body: JSON.stringify({ challengeToken: challengeToken, code: data.get("code") }),
+    headers: {
+      "Content-Type": "application/json"
+    }
+  })
+  if (res.status == 200) {
+    const userPayload = await res.json()
+    setAccessTokenAndUser({ success: true, payload: userPayload })
+    window.location.href = "/"
+  }
+  else {
+    displayErrorMessage(await res.json())
+  }
+})
+
+// single sign-on ends back on this page with the outcome in the fragment
+async function finishSingleSignOn() {
+  const result = new URLSearchParams(window.location.hash.slice(1))
+  history.replaceState(null, "", window.location.pathname)
+
+  if (result.has("error")) {
+    displayErrorMessage({ message: result.get("error") })
+  } else if (result.has("challengeToken")) {
+    challengeToken = result.get("challengeToken")
+    loginUserForm.hidden = true
+    twoFactorForm.hidden = false
+  } else if (result.get("sso") == "success") {
+    if (result.has("refreshToken")) {
+      localStorage.setItem("refresh-token", result.get("refreshToken"))
+    }
+    if (setAccessTokenAndUser(await isLoggedIn())) {
+      window.location.href = "/"
+    }
+  }
+}
+
+async function showSingleSignOn() {
+  const res = await fetch("/api/oidc/config")
+  const config = await res.json()
+  if (config.enabled) {
+    oidcLoginLink.textContent = `Login with ${config.providerName}`
+    oidcLoginLink.hidden = false
+  }
+}
+
+finishSingleSignOn()
+showSingleSignOn()
 loadNavBar()
 
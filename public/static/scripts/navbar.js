@@ -1,4 +1,5 @@
 import { isLoggedIn, setAccessTokenAndUser } from "./auth.js"
+import { getHeaders } from "./utils.js"
 
 export async function loadNavBar() {
   const navbar = document.querySelector("nav")
@@ -8,10 +9,16 @@ export async function loadNavBar() {
     const userInfo = document.createElement("div")
     userInfo.classList.add("user-information")
     const logoutButton = document.createElement("button")
-    logoutButton.addEventListener("click", e => {
+    logoutButton.addEventListener("click", async e => {
       e.preventDefault()
+      await fetch("/api/logout", {
+        method: "POST",
+        headers: getHeaders()
+      })
       localStorage.clear("access-token")
+      localStorage.clear("refresh-token")
       localStorage.clear("userId")
+      localStorage.clear("clubId")
       localStorage.clear("username")
       localStorage.clear("first-name")
       localStorage.clear("last-name")
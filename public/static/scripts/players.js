@@ -1,5 +1,5 @@
 import { loadNavBar } from "./navbar.js";
-import { getHeaders } from "./utils.js";
+import { fetchAll, getActiveClubId, getHeaders } from "./utils.js";
 
 loadNavBar()
 
@@ -17,25 +17,18 @@ async function renderAllPlayer() {
     window.location.href = "/create-player"
   })
   players.append(createPlayer)
-  const headers = getHeaders()
-  const userId = localStorage.getItem("userId")
-  const res = await fetch("/api/players/" + userId, {
-    headers: {
-      Authorization: headers.Authorization
-    }
-  })
-  if (res.status == 500) {
+  const playersObj = await fetchAll("/api/players/" + getActiveClubId())
+  if (playersObj == null) {
     const errorEl = document.createElement("p")
     errorEl.innerHTML = "Couldn't fetch players"
-  } else if (res.status == 200) {
-    const playersObj = await res.json()
-    if (playersObj == null) {
+  } else {
+    if (playersObj.length === 0) {
       const noPlayerMessage = document.createElement("p")
       noPlayerMessage.innerHTML = "No players created yet"
       htmlBody.append(noPlayerMessage)
     } else {
       playersObj.map(player => {
-        const playerId = player.ID
+        const playerId = player.id
         const playerEl = document.createElement("div")
         playerEl.classList.add("player-team-obj")
 
@@ -43,8 +36,8 @@ async function renderAllPlayer() {
         playerEditButton.innerHTML = "Edit"
         playerEditButton.addEventListener("click", e => {
           e.preventDefault()
-          localStorage.setItem("player-first-name", player.FirstName)
-          localStorage.setItem("player-last-name", player.LastName)
+          localStorage.setItem("player-first-name", player.firstName)
+          localStorage.setItem("player-last-name", player.lastName)
           window.location.href = "/edit-player/" + playerId
         })
 
@@ -66,7 +59,7 @@ async function renderAllPlayer() {
         })
 
         const playerName = document.createElement("p")
-        playerName.innerHTML = player.FirstName + " " + player.LastName
+        playerName.innerHTML = player.firstName + " " + player.lastName
 
         playerEl.appendChild(playerName)
         const editDelete = document.createElement("div")
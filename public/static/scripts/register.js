@@ -1,6 +1,6 @@
 import { setAccessTokenAndUser } from "./auth.js";
 import { loadNavBar } from "./navbar.js";
-import { displayErrorMessage } from "./utils.js";
+import { displayErrorMessage, getCsrfToken } from "./utils.js";
 
 const registerUserForm = document.querySelector(`[data-form="register-user-form"]`);
 
@@ -28,7 +28,11 @@ registerUserForm.addEventListener("submit", async e => {
       }
     })
     const userPayload = await res.json()
-    if (res.status == 201) {
+    if (res.status == 201 && !userPayload.accessToken && !getCsrfToken()) {
+      const messageEl = document.createElement("p")
+      messageEl.innerHTML = "Please confirm your email with the link we just sent you, then log in."
+      document.querySelector(".form-wrapper").appendChild(messageEl)
+    } else if (res.status == 201) {
       setAccessTokenAndUser({ success: true, payload: userPayload })
       window.location.href = "/"
     } else if (res.status == 409) {
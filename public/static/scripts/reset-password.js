@@ -0,0 +1,66 @@
+import { loadNavBar } from "./navbar.js";
+
+const requestResetForm = document.querySelector(`[data-form="request-reset-form"]`);
+const confirmResetForm = document.querySelector(`[data-form="confirm-reset-form"]`);
+const token = new URLSearchParams(window.location.search).get("token")
+
+if (token) {
+  requestResetForm.hidden = true
+  confirmResetForm.hidden = false
+}
+
+function showMessage(message) {
+  const htmlBody = document.querySelector(".form-wrapper")
+  let messageEl = document.querySelector("#reset-message")
+  if (!messageEl) {
+    messageEl = document.createElement("p")
+    messageEl.id = "reset-message"
+    htmlBody.appendChild(messageEl)
+  }
+  messageEl.innerHTML = message
+}
+
+requestResetForm.addEventListener("submit", async e => {
+  e.preventDefault();
+
+  const data = new URLSearchParams(new FormData(e.target))
+
+  const res = await fetch("/api/password-reset", {
+    method: "POST",
+    body: JSON.stringify({ email: data.get("email") }),
+    headers: {
+      "Content-Type": "application/json"
+    }
+  })
+  const payload = await res.json()
+  showMessage(payload.message)
+})
+
+confirmResetForm.addEventListener("submit", async e => {
+  e.preventDefault();
+
+  const data = new URLSearchParams(new FormData(e.target))
+
+  const body = {
+    token: token,
+    password: data.get("password"),
+    confirm: data.get("confirm"),
+  }
+
+  const res = await fetch("/api/password-reset/confirm", {
+    method: "POST",
+    body: JSON.stringify(body),
+    headers: {
+      "Content-Type": "application/json"
+    }
+  })
+  if (res.status == 200) {
+    window.location.href = "/login"
+  }
+  else {
+    const payload = await res.json()
+    showMessage(payload.message)
+  }
+})
+
+loadNavBar()
@@ -1,5 +1,5 @@
 import { loadNavBar } from "./navbar.js";
-import { getHeaders, fetchAllPlayers } from "./utils.js";
+import { getActiveClubId, getHeaders, fetchAll, fetchAllPlayers } from "./utils.js";
 
 loadNavBar()
 
@@ -18,26 +18,18 @@ async function showAllTeams() {
   })
   teams.append(createTeam)
 
-  const headers = getHeaders()
-  const userId = localStorage.getItem("userId")
-  const res = await fetch("/api/teams/" + userId, {
-    headers: {
-      Authorization: headers.Authorization
-    }
-  })
-  if (res.status == 500) {
+  const teamsObj = await fetchAll("/api/teams/" + getActiveClubId(), { hasSecondPlayer: "true" })
+  if (teamsObj == null) {
     const errorEl = document.createElement("p")
     errorEl.innerHTML = "Couldn't fetch teams"
-  } else if (res.status == 200) {
-    const teamsOb = await res.json()
-    const teamsObj = teamsOb.filter(team => team.PlayerTwo != null)
-    if (teamsObj == null || teamsObj.length === 0) {
+  } else {
+    if (teamsObj.length === 0) {
       const noTeamMessage = document.createElement("p")
       noTeamMessage.innerHTML = "No teams created yet"
       htmlBody.append(noTeamMessage)
     } else {
       teamsObj.map(async team => {
-        const teamId = team.ID
+        const teamId = team.id
         const teamEl = document.createElement("div")
         teamEl.classList.add("player-team-obj")
 
@@ -45,8 +37,8 @@ async function showAllTeams() {
         teamEditButton.innerHTML = "Edit"
         teamEditButton.addEventListener("click", e => {
           e.preventDefault()
-          localStorage.setItem("team-player-one", team.PlayerOne.ID)
-          localStorage.setItem("team-player-two", team.PlayerTwo.ID)
+          localStorage.setItem("team-player-one", team.playerOne)
+          localStorage.setItem("team-player-two", team.playerTwo)
           window.location.href = "/edit-team/" + teamId
         })
 
@@ -69,17 +61,17 @@ async function showAllTeams() {
 
         const players = await fetchAllPlayers()
 
-        if (team.PlayerTwo) {
-          const playerOne = players.find(player => player.ID == team.PlayerOne)
-          const playerTwo = players.find(player => player.ID == team.PlayerTwo)
+        if (team.playerTwo) {
+          const playerOne = players.find(player => player.id == team.playerOne)
+          const playerTwo = players.find(player => player.id == team.playerTwo)
 
           let teamNaming = "[No Team name]"
-          if (team.Name != "") {
-            teamNaming = team.Name
+          if (team.name != "") {
+            teamNaming = team.name
           }
           const teamName = document.createElement("p")
 
-          teamName.innerHTML = `Team Name: "${teamNaming}" Player One: "${playerOne.FirstName} ${playerOne.LastName}" Player Two: "${playerTwo.FirstName} ${playerTwo.LastName}"`
+          teamName.innerHTML = `Team Name: "${teamNaming}" Player One: "${playerOne.firstName} ${playerOne.lastName}" Player Two: "${playerTwo.firstName} ${playerTwo.lastName}"`
           teamEl.appendChild(teamName)
 
           const editDelete = document.createElement("div")
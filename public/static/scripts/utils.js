@@ -7,32 +7,50 @@ export function displayErrorMessage(userPayload) {
     htmlBody.appendChild(errorMessage)
   }
 
-  if (userPayload.message.includes("users_username_unique")) {
-    errorMessage.innerHTML = "Username already exists"
-  } else if (userPayload.message.includes("users_email_unique")) {
-    errorMessage.innerHTML = "Email already exists"
-  } else if (userPayload.message == "password") {
+  // the server explains its errors in message, the rest are checks of the page
+  if (userPayload.message == "password") {
     errorMessage.innerHTML = "Wrong Password combination"
-  } else if (userPayload.message.includes("sql: no rows in result set")) {
-    errorMessage.innerHTML = "Wrong Username"
-  } else if (userPayload.message.includes("hashedPassword")) {
-    errorMessage.innerHTML = "Wrong Password"
-  } else if (userPayload.message.includes("team")) {
+  } else if (userPayload.message == "team") {
     errorMessage.innerHTML = "Please enter two different Players"
-  } else if (userPayload.message.includes("no player")) {
+  } else if (userPayload.message == "no player") {
     errorMessage.innerHTML = "Please enter two Players"
+  } else if (userPayload.message) {
+    errorMessage.textContent = userPayload.message
   } else {
     errorMessage.innerHTML = "Error with request"
   }
 
 }
 
+// getCsrfToken reads the token the server sets next to the session cookies.
+// It is empty when the server hands out bearer tokens instead.
+export function getCsrfToken() {
+  const cookie = document.cookie.split("; ").find(c => c.startsWith("csrf_token="))
+  return cookie ? cookie.substring("csrf_token=".length) : ""
+}
+
+// getActiveClubId is the club the user works in. Without a picked club it is
+// the personal club, which shares the id of the user.
+export function getActiveClubId() {
+  return localStorage.getItem("clubId") || localStorage.getItem("userId")
+}
+
 export function getHeaders() {
   const token = localStorage.getItem("access-token")
   const headers = {
-    Authorization: "Bearer " + token,
     "Content-Type": "application/json"
   }
+  if (token) {
+    headers.Authorization = "Bearer " + token
+  }
+  const csrfToken = getCsrfToken()
+  if (csrfToken) {
+    headers["X-CSRF-Token"] = csrfToken
+  }
+  const clubId = getActiveClubId()
+  if (clubId) {
+    headers["X-Club-Id"] = clubId
+  }
 
   return headers
 }
@@ -59,18 +77,30 @@ export function addMessage(messageEl, message, resource, url) {
   messageEl.append(buttonToPlayers)
 }
 
-export async function fetchAllPlayers() {
-  const headers = getHeaders()
-  const userId = localStorage.getItem("userId")
-  const res = await fetch("/api/players/" + userId, {
-    headers: {
-      Authorization: headers.Authorization
+// fetchAll reads every page of a list route, following nextCursor until the
+// last page. It returns null when a page can't be fetched.
+export async function fetchAll(path, params = {}) {
+  const items = []
+  let cursor = ""
+  do {
+    const query = new URLSearchParams({ ...params, limit: "100" })
+    if (cursor) {
+      query.set("cursor", cursor)
     }
-  })
-  if (res.status == 200) {
-    const allPlayers = await res.json()
-    return allPlayers
-  }
-  return []
+    const res = await fetch(path + "?" + query, {
+      headers: getHeaders()
+    })
+    if (res.status != 200) {
+      return null
+    }
+    const page = await res.json()
+    items.push(...page.data)
+    cursor = page.nextCursor
+  } while (cursor)
+  return items
 }
 
+export async function fetchAllPlayers() {
+  const allPlayers = await fetchAll("/api/players/" + getActiveClubId())
+  return allPlayers || []
+}
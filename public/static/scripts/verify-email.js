@@ -0,0 +1,23 @@
+import { loadNavBar } from "./navbar.js";
+
+const messageEl = document.querySelector(`[data-text="verify-message"]`);
+const token = new URLSearchParams(window.location.search).get("token")
+
+async function verifyEmail() {
+  const res = await fetch("/api/verify-email", {
+    method: "POST",
+    body: JSON.stringify({ token: token }),
+    headers: {
+      "Content-Type": "application/json"
+    }
+  })
+  if (res.status == 200) {
+    messageEl.innerHTML = "Your email is verified, you can log in now."
+  } else {
+    const payload = await res.json()
+    messageEl.innerHTML = payload.message
+  }
+}
+
+verifyEmail()
+loadNavBar()
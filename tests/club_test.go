@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveOwnersConcurrently(t *testing.T) {
+	queries := utils.DbQueriesTest()
+	ctx := context.Background()
+	clubHandler := handler.NewClubHandler(queries, config.Config{}, nil)
+
+	var owners []uuid.UUID
+	for _, name := range []string{"owner-one", "owner-two"} {
+		user, err := queries.CreateUser(ctx, db.CreateUserParams{Username: name, Email: name + "@test.de", PasswordHash: "Test"})
+		require.NoError(t, err)
+		defer queries.DeleteUserById(ctx, user.ID)
+		owners = append(owners, user.ID)
+	}
+	club, err := queries.CreateClub(ctx, db.CreateClubParams{ID: uuid.New(), Name: "two owners"})
+	require.NoError(t, err)
+	defer queries.DeleteClubById(ctx, club.ID)
+	for _, owner := range owners {
+		_, err := queries.UpsertClubMember(ctx, db.UpsertClubMemberParams{ClubID: club.ID, UserID: owner, Role: handler.RoleOwner})
+		require.NoError(t, err)
+	}
+
+	errs := make([]error, len(owners))
+	var wg sync.WaitGroup
+	for i, owner := range owners {
+		wg.Add(1)
+		go func(i int, owner uuid.UUID) {
+			defer wg.Done()
+			_, errs[i] = clubHandler.RemoveMember(ctx, club.ID, owner)
+		}(i, owner)
+	}
+	wg.Wait()
+
+	removed := 0
+	for _, err := range errs {
+		if err == nil {
+			removed++
+		} else {
+			assert.True(t, errors.Is(err, handler.LastClubOwner), "RemoveMember() = %v, want nil or LastClubOwner", err)
+		}
+	}
+	assert.Equal(t, 1, removed, "exactly one of the two owners should be able to leave")
+
+	left, err := queries.CountClubOwners(ctx, club.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), left)
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresLoginAttemptStore(t *testing.T) {
+	store := utils.PostgresLoginAttemptStore{DB: utils.DbQueriesTest()}
+	ctx := context.Background()
+	key := "ip:test-" + time.Now().Format(time.RFC3339Nano)
+	now := time.Now().Truncate(time.Microsecond)
+
+	attempt, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), attempt.Failures)
+
+	_, err = store.RecordFailure(ctx, key, now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	attempt, err = store.RecordFailure(ctx, key, now, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempt.Failures)
+
+	require.NoError(t, store.Block(ctx, key, now.Add(time.Minute)))
+	attempt, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, attempt.BlockedUntil.Equal(now.Add(time.Minute)))
+
+	attempt, err = store.RecordFailure(ctx, key, now.Add(2*time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), attempt.Failures, "failures outside the window should be forgotten")
+
+	require.NoError(t, store.Reset(ctx, key))
+	attempt, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), attempt.Failures)
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/handler"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createSeedUser(ctx context.Context, q db.Querier, username string, email string, password string) (db.User, error) {
+	return handler.NewUserHandler(q, config.Config{}).CreateUser(ctx, handler.CreateUserInput{Username: username, Email: email, Password: password})
+}
+
+func TestSeed(t *testing.T) {
+	queries := utils.DbQueriesTest()
+	ctx := context.Background()
+	rollback := errors.New("rollback")
+
+	err := queries.InTx(ctx, func(q db.Querier) error {
+		result, err := utils.Seed(ctx, q, utils.SeedConfig{Seed: 1, Users: 1, Players: 4, DoublesTeams: 2, Matches: 3, Sets: 3, Password: "Demo1234"}, createSeedUser)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Matches)
+
+		user, err := q.GetUserByUsername(ctx, "demo-1-1")
+		require.NoError(t, err)
+		players, err := q.ListPlayersByClubIdOrderByLastName(ctx, db.ListPlayersByClubIdOrderByLastNameParams{ClubID: user.ID, PageSize: 10})
+		require.NoError(t, err)
+		assert.Len(t, players, 4)
+
+		recomputed, err := q.RecomputeStats(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, recomputed.Inserted, "every game should have stats for both teams already")
+		assert.NotZero(t, recomputed.Updated)
+		return rollback
+	})
+	assert.ErrorIs(t, err, rollback)
+}
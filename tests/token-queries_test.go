@@ -57,17 +57,24 @@ func TestRefreshTokenQueries(t *testing.T) {
 			if val.action == "create" {
 				tokenParams := getCreateTokenParams(val.tokenInput)
 
-				tokenUser, err := testDbQueries.CreateToken(context, tokenParams)
+				token, err := testDbQueries.CreateToken(context, tokenParams)
 				if assert.NoError(t, err) {
-					updatedUser, err := testDbQueries.GetUserById(context, user.ID)
+					secondToken, err := testDbQueries.CreateToken(context, db.CreateTokenParams{
+						UserID:      user.ID,
+						TokenHash:   "Second",
+						ExpiryDate:  time.Now(),
+						DeviceLabel: "phone",
+					})
 					assert.NoError(t, err)
 
-					assert.Equal(t, user.ID, tokenUser.ID)
-					assert.Equal(t, user.Username, tokenUser.Username)
-					assert.Equal(t, user.Email, tokenUser.Email)
-					assert.Equal(t, user.PasswordHash, tokenUser.PasswordHash)
-					assert.Equal(t, updatedUser, tokenUser)
+					sessions, err := testDbQueries.GetAllTokensByUserId(context, user.ID)
 					assert.NoError(t, err)
+
+					assert.Equal(t, user.ID, token.UserID)
+					assert.Equal(t, val.tokenInput.Token, token.TokenHash)
+					assert.Equal(t, "laptop", token.DeviceLabel)
+					assert.Equal(t, "phone", secondToken.DeviceLabel)
+					assert.Equal(t, 2, len(sessions))
 				}
 			}
 		})
@@ -96,8 +103,9 @@ func getTestUser(t *testing.T, d *db.Queries) db.User {
 
 func getCreateTokenParams(input testToken) db.CreateTokenParams {
 	return db.CreateTokenParams{
-		Token:      input.Token,
-		ExpiryDate: input.ExpiryDate,
-		UserID:     input.UserID,
+		UserID:      input.UserID,
+		TokenHash:   input.Token,
+		ExpiryDate:  input.ExpiryDate,
+		DeviceLabel: "laptop",
 	}
 }
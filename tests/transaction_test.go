@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/Laurin-Notemann/tennis-analysis/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInTx(t *testing.T) {
+	queries := utils.DbQueriesTest()
+	ctx := context.Background()
+
+	t.Run("rolls back when the unit fails", func(t *testing.T) {
+		id := uuid.New()
+		failed := errors.New("failed")
+
+		err := queries.InTx(ctx, func(q db.Querier) error {
+			_, err := q.CreateClub(ctx, db.CreateClubParams{ID: id, Name: "rolled back"})
+			require.NoError(t, err)
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+
+		_, err = queries.GetClubById(ctx, id)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("commits nested units once", func(t *testing.T) {
+		id := uuid.New()
+		failed := errors.New("failed")
+
+		err := queries.InTx(ctx, func(q db.Querier) error {
+			_, err := q.CreateClub(ctx, db.CreateClubParams{ID: id, Name: "nested"})
+			require.NoError(t, err)
+			return q.(*db.Queries).InTx(ctx, func(q db.Querier) error {
+				_, err := q.GetClubById(ctx, id)
+				require.NoError(t, err, "the nested unit should see the writes of the outer one")
+				return failed
+			})
+		})
+		assert.ErrorIs(t, err, failed)
+		_, err = queries.GetClubById(ctx, id)
+		assert.ErrorIs(t, err, sql.ErrNoRows, "a failed nested unit should roll back the outer one")
+
+		err = queries.InTx(ctx, func(q db.Querier) error {
+			_, err := q.CreateClub(ctx, db.CreateClubParams{ID: id, Name: "committed"})
+			return err
+		})
+		require.NoError(t, err)
+		club, err := queries.GetClubById(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "committed", club.Name)
+
+		_, err = queries.DeleteClubById(ctx, id)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreateClub(ctx context.Context, arg db.CreateClubParams) (db.Club, error) {
+	if d.hasClub(arg.ID) {
+		return db.Club{}, uniqueViolation("clubs_pkey")
+	}
+	club := db.Club{
+		ID:        arg.ID,
+		Name:      arg.Name,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+	}
+	d.clubs = append(d.clubs, club)
+	return club, nil
+}
+
+func (d *DBQueriesMock) GetClubById(ctx context.Context, id uuid.UUID) (db.Club, error) {
+	idx := slices.IndexFunc(d.clubs, func(c db.Club) bool { return c.ID == id })
+	if idx == -1 {
+		return db.Club{}, sql.ErrNoRows
+	}
+	return d.clubs[idx], nil
+}
+
+func (d *DBQueriesMock) GetClubsByUserId(ctx context.Context, userId uuid.UUID) ([]db.GetClubsByUserIdRow, error) {
+	var clubs []db.GetClubsByUserIdRow
+	for _, m := range d.clubMembers {
+		if m.UserID != userId {
+			continue
+		}
+		club, err := d.GetClubById(ctx, m.ClubID)
+		if err != nil {
+			continue
+		}
+		clubs = append(clubs, db.GetClubsByUserIdRow{
+			ID:        club.ID,
+			Name:      club.Name,
+			CreatedAt: club.CreatedAt,
+			UpdatedAt: club.UpdatedAt,
+			Role:      m.Role,
+		})
+	}
+	return clubs, nil
+}
+
+func (d *DBQueriesMock) UpdateClubById(ctx context.Context, arg db.UpdateClubByIdParams) (db.Club, error) {
+	idx := slices.IndexFunc(d.clubs, func(c db.Club) bool { return c.ID == arg.ID })
+	if idx == -1 {
+		return db.Club{}, sql.ErrNoRows
+	}
+	d.clubs[idx].Name = arg.Name
+	d.clubs[idx].UpdatedAt = dbNow()
+	return d.clubs[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteClubById(ctx context.Context, id uuid.UUID) (db.Club, error) {
+	club, err := d.GetClubById(ctx, id)
+	if err != nil {
+		return db.Club{}, err
+	}
+	d.deleteClub(id)
+	return club, nil
+}
+
+func (d *DBQueriesMock) UpsertClubMember(ctx context.Context, arg db.UpsertClubMemberParams) (db.ClubMember, error) {
+	if !slices.Contains(clubRoles, arg.Role) {
+		return db.ClubMember{}, checkViolation("club_members", "CK_Club_members_Role")
+	}
+	if !d.hasClub(arg.ClubID) {
+		return db.ClubMember{}, foreignKeyViolation("club_members", "FK_Club_members.club_id")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.ClubMember{}, foreignKeyViolation("club_members", "FK_Club_members.user_id")
+	}
+	idx := slices.IndexFunc(d.clubMembers, func(m db.ClubMember) bool {
+		return m.ClubID == arg.ClubID && m.UserID == arg.UserID
+	})
+	if idx != -1 {
+		d.clubMembers[idx].Role = arg.Role
+		d.clubMembers[idx].UpdatedAt = dbNow()
+		return d.clubMembers[idx], nil
+	}
+
+	member := db.ClubMember{
+		ClubID:    arg.ClubID,
+		UserID:    arg.UserID,
+		Role:      arg.Role,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+	}
+	d.clubMembers = append(d.clubMembers, member)
+	return member, nil
+}
+
+func (d *DBQueriesMock) GetClubMember(ctx context.Context, arg db.GetClubMemberParams) (db.ClubMember, error) {
+	idx := slices.IndexFunc(d.clubMembers, func(m db.ClubMember) bool {
+		return m.ClubID == arg.ClubID && m.UserID == arg.UserID
+	})
+	if idx == -1 {
+		return db.ClubMember{}, sql.ErrNoRows
+	}
+	return d.clubMembers[idx], nil
+}
+
+func (d *DBQueriesMock) GetClubMembersByClubId(ctx context.Context, clubId uuid.UUID) ([]db.GetClubMembersByClubIdRow, error) {
+	var members []db.GetClubMembersByClubIdRow
+	for _, m := range d.clubMembers {
+		if m.ClubID == clubId {
+			members = append(members, db.GetClubMembersByClubIdRow{
+				ClubID:    m.ClubID,
+				UserID:    m.UserID,
+				Role:      m.Role,
+				CreatedAt: m.CreatedAt,
+				UpdatedAt: m.UpdatedAt,
+				Username:  d.usernameOf(m.UserID),
+			})
+		}
+	}
+	slices.SortFunc(members, func(a, b db.GetClubMembersByClubIdRow) int { return strings.Compare(a.Username, b.Username) })
+	return members, nil
+}
+
+func (d *DBQueriesMock) CountClubOwners(ctx context.Context, clubId uuid.UUID) (int64, error) {
+	var count int64
+	for _, m := range d.clubMembers {
+		if m.ClubID == clubId && m.Role == "owner" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// LockClubOwners can't lock anything, the mock runs one query at a time.
+func (d *DBQueriesMock) LockClubOwners(ctx context.Context, clubId uuid.UUID) ([]uuid.UUID, error) {
+	var owners []uuid.UUID
+	for _, m := range d.clubMembers {
+		if m.ClubID == clubId && m.Role == "owner" {
+			owners = append(owners, m.UserID)
+		}
+	}
+	return owners, nil
+}
+
+func (d *DBQueriesMock) DeleteClubMember(ctx context.Context, arg db.DeleteClubMemberParams) (db.ClubMember, error) {
+	idx := slices.IndexFunc(d.clubMembers, func(m db.ClubMember) bool {
+		return m.ClubID == arg.ClubID && m.UserID == arg.UserID
+	})
+	if idx == -1 {
+		return db.ClubMember{}, sql.ErrNoRows
+	}
+	member := d.clubMembers[idx]
+	d.clubMembers = slices.Delete(d.clubMembers, idx, idx+1)
+	return member, nil
+}
+
+func (d *DBQueriesMock) CreateClubInvitation(ctx context.Context, arg db.CreateClubInvitationParams) (db.ClubInvitation, error) {
+	if !slices.Contains(clubRoles, arg.Role) {
+		return db.ClubInvitation{}, checkViolation("club_invitations", "CK_Club_invitations_Role")
+	}
+	if contains(d.clubInvitations, func(i db.ClubInvitation) bool { return i.TokenHash == arg.TokenHash }) {
+		return db.ClubInvitation{}, uniqueViolation("club_invitations_token_hash_key")
+	}
+	if !d.hasClub(arg.ClubID) {
+		return db.ClubInvitation{}, foreignKeyViolation("club_invitations", "FK_Club_invitations.club_id")
+	}
+	if arg.InvitedBy != nil && !d.hasUser(*arg.InvitedBy) {
+		return db.ClubInvitation{}, foreignKeyViolation("club_invitations", "FK_Club_invitations.invited_by")
+	}
+	invitation := db.ClubInvitation{
+		ID:         uuid.New(),
+		ClubID:     arg.ClubID,
+		Email:      arg.Email,
+		Role:       arg.Role,
+		TokenHash:  arg.TokenHash,
+		InvitedBy:  arg.InvitedBy,
+		ExpiryDate: arg.ExpiryDate,
+		CreatedAt:  dbNow(),
+	}
+	d.clubInvitations = append(d.clubInvitations, invitation)
+	return invitation, nil
+}
+
+func (d *DBQueriesMock) GetClubInvitationByHash(ctx context.Context, tokenHash string) (db.ClubInvitation, error) {
+	idx := slices.IndexFunc(d.clubInvitations, func(i db.ClubInvitation) bool { return i.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.ClubInvitation{}, sql.ErrNoRows
+	}
+	return d.clubInvitations[idx], nil
+}
+
+func (d *DBQueriesMock) GetOpenClubInvitationsByClubId(ctx context.Context, clubId uuid.UUID) ([]db.ClubInvitation, error) {
+	var invitations []db.ClubInvitation
+	for _, i := range d.clubInvitations {
+		if i.ClubID == clubId && !i.AcceptedAt.Valid {
+			invitations = append(invitations, i)
+		}
+	}
+	return invitations, nil
+}
+
+func (d *DBQueriesMock) AcceptClubInvitation(ctx context.Context, id uuid.UUID) (db.ClubInvitation, error) {
+	idx := slices.IndexFunc(d.clubInvitations, func(i db.ClubInvitation) bool { return i.ID == id && !i.AcceptedAt.Valid })
+	if idx == -1 {
+		return db.ClubInvitation{}, sql.ErrNoRows
+	}
+	d.clubInvitations[idx].AcceptedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	return d.clubInvitations[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteClubInvitation(ctx context.Context, arg db.DeleteClubInvitationParams) (db.ClubInvitation, error) {
+	idx := slices.IndexFunc(d.clubInvitations, func(i db.ClubInvitation) bool {
+		return i.ID == arg.ID && i.ClubID == arg.ClubID
+	})
+	if idx == -1 {
+		return db.ClubInvitation{}, sql.ErrNoRows
+	}
+	invitation := d.clubInvitations[idx]
+	d.clubInvitations = slices.Delete(d.clubInvitations, idx, idx+1)
+	return invitation, nil
+}
+
+// clubRoles are the roles the club tables allow.
+var clubRoles = []string{"owner", "coach", "viewer"}
+
+func (d *DBQueriesMock) usernameOf(id uuid.UUID) string {
+	idx := slices.IndexFunc(d.users, func(u db.User) bool { return u.ID == id })
+	if idx == -1 {
+		return ""
+	}
+	return d.users[idx].Username
+}
@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The mock keeps the constraints of the schema in db/migrations. Writes that
+// break one fail with the error Postgres returns, so handlers map them the same
+// way they map the errors of the real database.
+
+func uniqueViolation(constraint string) error {
+	return &pq.Error{
+		Code:       "23505",
+		Constraint: constraint,
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+	}
+}
+
+func foreignKeyViolation(table string, constraint string) error {
+	return &pq.Error{
+		Code:       "23503",
+		Table:      table,
+		Constraint: constraint,
+		Message:    fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+	}
+}
+
+func checkViolation(table string, constraint string) error {
+	return &pq.Error{
+		Code:       "23514",
+		Table:      table,
+		Constraint: constraint,
+		Message:    fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+	}
+}
+
+// dbNow is Now() of the database, which keeps microseconds.
+func dbNow() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func contains[T any](rows []T, match func(T) bool) bool {
+	return slices.IndexFunc(rows, match) != -1
+}
+
+func (d *DBQueriesMock) hasUser(id uuid.UUID) bool {
+	return contains(d.users, func(u db.User) bool { return u.ID == id })
+}
+
+func (d *DBQueriesMock) hasClub(id uuid.UUID) bool {
+	return contains(d.clubs, func(c db.Club) bool { return c.ID == id })
+}
+
+func (d *DBQueriesMock) hasPlayer(id uuid.UUID) bool {
+	return contains(d.players, func(p db.Player) bool { return p.ID == id })
+}
+
+func (d *DBQueriesMock) hasTeam(id uuid.UUID) bool {
+	return contains(d.teams, func(t db.Team) bool { return t.ID == id })
+}
+
+func (d *DBQueriesMock) hasMatch(id uuid.UUID) bool {
+	return contains(d.matches, func(m db.Match) bool { return m.ID == id })
+}
+
+func (d *DBQueriesMock) hasSet(id uuid.UUID) bool {
+	return contains(d.sets, func(s db.Set) bool { return s.ID == id })
+}
+
+func (d *DBQueriesMock) hasGame(id uuid.UUID) bool {
+	return contains(d.games, func(g db.Game) bool { return g.ID == id })
+}
+
+// deleteUser removes a user like DELETE does: the delete_user_cascade trigger
+// takes down the clubs nobody else owns, the foreign keys everything else.
+func (d *DBQueriesMock) deleteUser(id uuid.UUID) {
+	for _, member := range slices.Clone(d.clubMembers) {
+		if member.UserID != id || member.Role != "owner" {
+			continue
+		}
+		otherOwner := contains(d.clubMembers, func(m db.ClubMember) bool {
+			return m.ClubID == member.ClubID && m.Role == "owner" && m.UserID != id
+		})
+		if !otherOwner {
+			d.deleteClub(member.ClubID)
+		}
+	}
+
+	d.users = slices.DeleteFunc(d.users, func(u db.User) bool { return u.ID == id })
+	for _, token := range slices.Clone(d.tokens) {
+		if token.UserID == id {
+			d.deleteToken(token.ID)
+		}
+	}
+	d.passwordResets = slices.DeleteFunc(d.passwordResets, func(r db.PasswordReset) bool { return r.UserID == id })
+	d.emailVerifications = slices.DeleteFunc(d.emailVerifications, func(v db.EmailVerification) bool { return v.UserID == id })
+	d.totps = slices.DeleteFunc(d.totps, func(t db.UserTotp) bool { return t.UserID == id })
+	d.recoveryCodes = slices.DeleteFunc(d.recoveryCodes, func(c db.RecoveryCode) bool { return c.UserID == id })
+	d.twoFactorChallenges = slices.DeleteFunc(d.twoFactorChallenges, func(c db.TwoFactorChallenge) bool { return c.UserID == id })
+	d.clubMembers = slices.DeleteFunc(d.clubMembers, func(m db.ClubMember) bool { return m.UserID == id })
+	d.personalTokens = slices.DeleteFunc(d.personalTokens, func(t db.PersonalAccessToken) bool { return t.UserID == id })
+	d.userIdentities = slices.DeleteFunc(d.userIdentities, func(i db.UserIdentity) bool { return i.UserID == id })
+	for idx, invitation := range d.clubInvitations {
+		if invitation.InvitedBy != nil && *invitation.InvitedBy == id {
+			d.clubInvitations[idx].InvitedBy = nil
+		}
+	}
+}
+
+// deleteClub removes a club like DELETE does: the delete_club_cascade trigger
+// takes down the players of its teams and the teams, the foreign keys its
+// members, invitations and matches.
+func (d *DBQueriesMock) deleteClub(id uuid.UUID) {
+	for _, team := range slices.Clone(d.teams) {
+		if team.ClubID != id {
+			continue
+		}
+		d.deletePlayer(team.PlayerOne)
+		if team.PlayerTwo != nil {
+			d.deletePlayer(*team.PlayerTwo)
+		}
+	}
+	for _, team := range slices.Clone(d.teams) {
+		if team.ClubID == id {
+			d.deleteTeam(team.ID)
+		}
+	}
+
+	d.clubs = slices.DeleteFunc(d.clubs, func(c db.Club) bool { return c.ID == id })
+	d.clubMembers = slices.DeleteFunc(d.clubMembers, func(m db.ClubMember) bool { return m.ClubID == id })
+	d.clubInvitations = slices.DeleteFunc(d.clubInvitations, func(i db.ClubInvitation) bool { return i.ClubID == id })
+	for _, match := range slices.Clone(d.matches) {
+		if match.ClubID == id {
+			d.deleteMatch(match.ID)
+		}
+	}
+}
+
+// deletePlayer removes a player with the teams it is the first player of, and
+// leaves the teams it is the second player of with one player.
+func (d *DBQueriesMock) deletePlayer(id uuid.UUID) {
+	d.players = slices.DeleteFunc(d.players, func(p db.Player) bool { return p.ID == id })
+	for _, team := range slices.Clone(d.teams) {
+		if team.PlayerOne == id {
+			d.deleteTeam(team.ID)
+		}
+	}
+	for idx, team := range d.teams {
+		if team.PlayerTwo != nil && *team.PlayerTwo == id {
+			d.teams[idx].PlayerTwo = nil
+		}
+	}
+}
+
+// deleteTeam removes a team with the matches it played, the games it served
+// and its points and stats.
+func (d *DBQueriesMock) deleteTeam(id uuid.UUID) {
+	d.teams = slices.DeleteFunc(d.teams, func(t db.Team) bool { return t.ID == id })
+	for _, match := range slices.Clone(d.matches) {
+		if match.TeamOne == id || match.TeamTwo == id {
+			d.deleteMatch(match.ID)
+		}
+	}
+	for _, game := range slices.Clone(d.games) {
+		if game.ServerID == id {
+			d.deleteGame(game.ID)
+		}
+	}
+	d.points = slices.DeleteFunc(d.points, func(p db.Point) bool { return p.TeamID == id })
+	d.stats = slices.DeleteFunc(d.stats, func(s db.Stat) bool { return s.TeamID != nil && *s.TeamID == id })
+}
+
+// deleteMatch removes a match with its sets and their games.
+func (d *DBQueriesMock) deleteMatch(id uuid.UUID) {
+	d.matches = slices.DeleteFunc(d.matches, func(m db.Match) bool { return m.ID == id })
+	for _, set := range slices.Clone(d.sets) {
+		if set.MatchID != id {
+			continue
+		}
+		d.sets = slices.DeleteFunc(d.sets, func(s db.Set) bool { return s.ID == set.ID })
+		for _, game := range slices.Clone(d.games) {
+			if game.SetID != nil && *game.SetID == set.ID {
+				d.deleteGame(game.ID)
+			}
+		}
+	}
+}
+
+// deleteGame removes a game with its points. Its stats stay without a game.
+func (d *DBQueriesMock) deleteGame(id uuid.UUID) {
+	d.games = slices.DeleteFunc(d.games, func(g db.Game) bool { return g.ID == id })
+	d.points = slices.DeleteFunc(d.points, func(p db.Point) bool { return p.GameID != nil && *p.GameID == id })
+	for idx, stat := range d.stats {
+		if stat.GameID != nil && *stat.GameID == id {
+			d.stats[idx].GameID = nil
+		}
+	}
+}
+
+// deleteToken removes a session with the hashes it was rotated from.
+func (d *DBQueriesMock) deleteToken(id uuid.UUID) {
+	d.tokens = slices.DeleteFunc(d.tokens, func(t db.RefreshToken) bool { return t.ID == id })
+	d.rotatedTokens = slices.DeleteFunc(d.rotatedTokens, func(t db.RotatedRefreshToken) bool { return t.SessionID == id })
+}
+
+// InTx runs fn as one unit of work like db.Queries does: when fn fails, the
+// mock is put back the way it was before. Units of work inside of fn run in
+// the outer one.
+func (d *DBQueriesMock) InTx(ctx context.Context, fn func(db.Querier) error) error {
+	if d.inTx {
+		return fn(d)
+	}
+
+	saved := d.clone()
+	d.inTx = true
+	err := fn(d)
+	d.inTx = false
+	if err != nil {
+		*d = saved
+	}
+	return err
+}
+
+func (d *DBQueriesMock) clone() DBQueriesMock {
+	saved := *d
+	saved.users = slices.Clone(d.users)
+	saved.tokens = slices.Clone(d.tokens)
+	saved.rotatedTokens = slices.Clone(d.rotatedTokens)
+	saved.passwordResets = slices.Clone(d.passwordResets)
+	saved.emailVerifications = slices.Clone(d.emailVerifications)
+	saved.loginAttempts = maps.Clone(d.loginAttempts)
+	saved.totps = slices.Clone(d.totps)
+	saved.recoveryCodes = slices.Clone(d.recoveryCodes)
+	saved.twoFactorChallenges = slices.Clone(d.twoFactorChallenges)
+	saved.clubs = slices.Clone(d.clubs)
+	saved.clubMembers = slices.Clone(d.clubMembers)
+	saved.clubInvitations = slices.Clone(d.clubInvitations)
+	saved.personalTokens = slices.Clone(d.personalTokens)
+	saved.userIdentities = slices.Clone(d.userIdentities)
+	saved.players = slices.Clone(d.players)
+	saved.teams = slices.Clone(d.teams)
+	saved.matches = slices.Clone(d.matches)
+	saved.sets = slices.Clone(d.sets)
+	saved.games = slices.Clone(d.games)
+	saved.points = slices.Clone(d.points)
+	saved.stats = slices.Clone(d.stats)
+	return saved
+}
+
+// ilikePrefix is `value ILIKE (prefix || '%')` for a prefix escaped like the
+// handlers escape it.
+func ilikePrefix(value string, prefix string) bool {
+	prefix = strings.NewReplacer(`\\`, `\`, `\%`, `%`, `\_`, `_`).Replace(prefix)
+	return strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix))
+}
+
+// compareKeyset orders rows by a sort value and their id, like the list
+// queries do. Names are compared byte by byte, Postgres compares them by the
+// collation of the database, which agrees for the names the tests use.
+func compareKeyset(name string, createdAt time.Time, id uuid.UUID, sortBy string, otherName string, otherCreatedAt time.Time, otherId uuid.UUID) int {
+	var c int
+	if sortBy == "created_at" {
+		c = createdAt.Compare(otherCreatedAt)
+	} else {
+		c = strings.Compare(name, otherName)
+	}
+	if c != 0 {
+		return c
+	}
+	return bytes.Compare(id[:], otherId[:])
+}
+
+// keysetPage sorts rows, drops the ones up to the cursor and cuts the rest to
+// the page size. key gives the sort value of a row, a name or its creation
+// time depending on sortBy.
+func keysetPage[T any](rows []T, key func(T) (string, time.Time, uuid.UUID), sortBy string, descending bool, cursorId *uuid.UUID, cursorName string, cursorCreatedAt time.Time, pageSize int32) []T {
+	order := 1
+	if descending {
+		order = -1
+	}
+	slices.SortFunc(rows, func(a, b T) int {
+		aName, aCreatedAt, aId := key(a)
+		bName, bCreatedAt, bId := key(b)
+		return order * compareKeyset(aName, aCreatedAt, aId, sortBy, bName, bCreatedAt, bId)
+	})
+
+	page := []T{}
+	for _, row := range rows {
+		name, createdAt, id := key(row)
+		if cursorId != nil && order*compareKeyset(name, createdAt, id, sortBy, cursorName, cursorCreatedAt, *cursorId) <= 0 {
+			continue
+		}
+		if len(page) == int(pageSize) {
+			break
+		}
+		page = append(page, row)
+	}
+	return page
+}
+
+func inTimeRange(t time.Time, after sql.NullTime, before sql.NullTime) bool {
+	return (!after.Valid || !t.Before(after.Time)) && (!before.Valid || t.Before(before.Time))
+}
@@ -2,14 +2,58 @@ package utils
 
 import "github.com/Laurin-Notemann/tennis-analysis/db"
 
+// DBQueriesMock is a db.Querier that keeps every table in memory. It keeps the
+// constraints, cascades and triggers of the schema too, so tests can run
+// against it instead of a database.
 type DBQueriesMock struct {
-	users []db.User
-  tokens []db.RefreshToken
+	users               []db.User
+	tokens              []db.RefreshToken
+	rotatedTokens       []db.RotatedRefreshToken
+	passwordResets      []db.PasswordReset
+	emailVerifications  []db.EmailVerification
+	loginAttempts       map[string]db.LoginAttempt
+	totps               []db.UserTotp
+	recoveryCodes       []db.RecoveryCode
+	twoFactorChallenges []db.TwoFactorChallenge
+	clubs               []db.Club
+	clubMembers         []db.ClubMember
+	clubInvitations     []db.ClubInvitation
+	personalTokens      []db.PersonalAccessToken
+	userIdentities      []db.UserIdentity
+	players             []db.Player
+	teams               []db.Team
+	matches             []db.Match
+	sets                []db.Set
+	games               []db.Game
+	points              []db.Point
+	stats               []db.Stat
+	inTx                bool
 }
 
+var _ db.Querier = (*DBQueriesMock)(nil)
+
 func NewDBQueriesMock() *DBQueriesMock {
 	return &DBQueriesMock{
-		users: []db.User{},
-    tokens: []db.RefreshToken{},
+		users:               []db.User{},
+		tokens:              []db.RefreshToken{},
+		rotatedTokens:       []db.RotatedRefreshToken{},
+		passwordResets:      []db.PasswordReset{},
+		emailVerifications:  []db.EmailVerification{},
+		loginAttempts:       map[string]db.LoginAttempt{},
+		totps:               []db.UserTotp{},
+		recoveryCodes:       []db.RecoveryCode{},
+		twoFactorChallenges: []db.TwoFactorChallenge{},
+		clubs:               []db.Club{},
+		clubMembers:         []db.ClubMember{},
+		clubInvitations:     []db.ClubInvitation{},
+		personalTokens:      []db.PersonalAccessToken{},
+		userIdentities:      []db.UserIdentity{},
+		players:             []db.Player{},
+		teams:               []db.Team{},
+		matches:             []db.Match{},
+		sets:                []db.Set{},
+		games:               []db.Game{},
+		points:              []db.Point{},
+		stats:               []db.Stat{},
 	}
 }
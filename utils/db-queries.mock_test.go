@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func TestDBQueriesMockConstraints(t *testing.T) {
+	d := NewDBQueriesMock()
+	ctx := context.Background()
+
+	user, _ := d.CreateUser(ctx, db.CreateUserParams{Username: "laurin", Email: "laurin@test.de"})
+	d.CreateClub(ctx, db.CreateClubParams{ID: user.ID, Name: "laurin"})
+	team, _ := d.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{FirstName: "Laurin", LastName: "Notemann", ClubID: user.ID})
+
+	tests := []struct {
+		name       string
+		write      func() error
+		code       pq.ErrorCode
+		constraint string
+	}{
+		{"taken username", func() error {
+			_, err := d.CreateUser(ctx, db.CreateUserParams{Username: "laurin", Email: "other@test.de"})
+			return err
+		}, "23505", "users_username_unique"},
+		{"taken player name", func() error {
+			_, err := d.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{FirstName: "Laurin", LastName: "Notemann", ClubID: user.ID})
+			return err
+		}, "23505", "unique_firstlast_name"},
+		{"unknown club", func() error {
+			_, err := d.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{FirstName: "Oskar", LastName: "Kuech", ClubID: uuid.New()})
+			return err
+		}, "23503", "FK_Teams.club_id"},
+		{"same player twice", func() error {
+			_, err := d.CreateTeamWithTwoPlayers(ctx, db.CreateTeamWithTwoPlayersParams{ClubID: user.ID, PlayerOne: team.PlayerOne, PlayerTwo: &team.PlayerOne})
+			return err
+		}, "23514", "CK_Teams_DistinctPlayers"},
+		{"session of an unknown user", func() error {
+			_, err := d.CreateToken(ctx, db.CreateTokenParams{UserID: uuid.New(), TokenHash: "hash"})
+			return err
+		}, "23503", "FK_Refresh_token.user_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pqErr *pq.Error
+			err := tt.write()
+			if !errors.As(err, &pqErr) || pqErr.Code != tt.code || pqErr.Constraint != tt.constraint {
+				t.Fatalf("write = %v, want %s on %s", err, tt.code.Name(), tt.constraint)
+			}
+		})
+	}
+
+	if _, err := d.GetUserByEmail(ctx, "other@test.de"); err != sql.ErrNoRows {
+		t.Fatalf("a failed write kept its user")
+	}
+}
+
+func TestDBQueriesMockCascades(t *testing.T) {
+	d := NewDBQueriesMock()
+	ctx := context.Background()
+
+	user, _ := d.CreateUser(ctx, db.CreateUserParams{Username: "laurin", Email: "laurin@test.de"})
+	d.CreateClub(ctx, db.CreateClubParams{ID: user.ID, Name: "laurin"})
+	d.UpsertClubMember(ctx, db.UpsertClubMemberParams{ClubID: user.ID, UserID: user.ID, Role: "owner"})
+	d.CreateToken(ctx, db.CreateTokenParams{UserID: user.ID, TokenHash: "hash"})
+	one, _ := d.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{FirstName: "Laurin", LastName: "Notemann", ClubID: user.ID})
+	two, _ := d.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{FirstName: "Oskar", LastName: "Kuech", ClubID: user.ID})
+	doubles, _ := d.CreateTeamWithTwoPlayers(ctx, db.CreateTeamWithTwoPlayersParams{ClubID: user.ID, PlayerOne: one.PlayerOne, PlayerTwo: &two.PlayerOne})
+	match, _ := d.CreateMatch(ctx, db.CreateMatchParams{ClubID: user.ID, TeamOne: one.ID, TeamTwo: two.ID})
+	set, _ := d.CreateSet(ctx, match.ID)
+	game, _ := d.CreateGame(ctx, db.CreateGameParams{SetID: &set.ID, ServerID: one.ID})
+	d.CreatePoint(ctx, db.CreatePointParams{GameID: &game.ID, TeamID: two.ID})
+	d.CreateStat(ctx, db.CreateStatParams{GameID: &game.ID, TeamID: &one.ID})
+
+	count, _ := d.CountUserResources(ctx, user.ID)
+	want := db.CountUserResourcesRow{Clubs: 1, Teams: 3, Players: 2, Matches: 1, Sessions: 1}
+	if count != want {
+		t.Fatalf("CountUserResources() = %+v, want %+v", count, want)
+	}
+
+	d.DeletePlayerById(ctx, two.PlayerOne)
+	if _, err := d.GetTeamById(ctx, two.ID); err != sql.ErrNoRows {
+		t.Fatalf("DeletePlayerById() kept the team of the player")
+	}
+	if team, _ := d.GetTeamById(ctx, doubles.ID); team.PlayerTwo != nil {
+		t.Fatalf("DeletePlayerById() kept the player in the doubles team")
+	}
+	if len(d.matches)+len(d.sets)+len(d.games)+len(d.points) != 0 {
+		t.Fatalf("DeletePlayerById() kept the match of the player")
+	}
+	if len(d.stats) != 1 || d.stats[0].GameID != nil {
+		t.Fatalf("DeletePlayerById() didn't keep the stats of the other team without their game")
+	}
+
+	d.DeleteUserById(ctx, user.ID)
+	count, _ = d.CountUserResources(ctx, user.ID)
+	if count != (db.CountUserResourcesRow{}) || len(d.clubs)+len(d.teams)+len(d.players)+len(d.tokens) != 0 {
+		t.Fatalf("DeleteUserById() left %+v behind", count)
+	}
+}
+
+func TestDBQueriesMockInTx(t *testing.T) {
+	d := NewDBQueriesMock()
+	ctx := context.Background()
+	failed := errors.New("failed")
+
+	err := d.InTx(ctx, func(q db.Querier) error {
+		q.CreateUser(ctx, db.CreateUserParams{Username: "laurin", Email: "laurin@test.de"})
+		return q.(*DBQueriesMock).InTx(ctx, func(q db.Querier) error {
+			if _, err := q.GetUserByUsername(ctx, "laurin"); err != nil {
+				t.Fatalf("a nested unit doesn't see the writes of the outer one")
+			}
+			return failed
+		})
+	})
+	if err != failed {
+		t.Fatalf("InTx() = %v, want %v", err, failed)
+	}
+	if _, err := d.GetUserByUsername(ctx, "laurin"); err != sql.ErrNoRows {
+		t.Fatalf("InTx() kept the user of a failed unit")
+	}
+
+	d.InTx(ctx, func(q db.Querier) error {
+		_, err := q.CreateUser(ctx, db.CreateUserParams{Username: "laurin", Email: "laurin@test.de"})
+		return err
+	})
+	if _, err := d.GetUserByUsername(ctx, "laurin"); err != nil {
+		t.Fatalf("InTx() dropped the user of a unit: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreateEmailVerification(ctx context.Context, arg db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+	if contains(d.emailVerifications, func(v db.EmailVerification) bool { return v.TokenHash == arg.TokenHash }) {
+		return db.EmailVerification{}, uniqueViolation("email_verifications_token_hash_key")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.EmailVerification{}, foreignKeyViolation("email_verifications", "FK_Email_verifications.user_id")
+	}
+	verification := db.EmailVerification{
+		ID:         uuid.New(),
+		UserID:     arg.UserID,
+		TokenHash:  arg.TokenHash,
+		ExpiryDate: arg.ExpiryDate,
+		CreatedAt:  dbNow(),
+	}
+
+	d.emailVerifications = append(d.emailVerifications, verification)
+
+	return verification, nil
+}
+
+func (d *DBQueriesMock) GetEmailVerificationByHash(ctx context.Context, tokenHash string) (db.EmailVerification, error) {
+	idx := slices.IndexFunc(d.emailVerifications, func(v db.EmailVerification) bool { return v.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.EmailVerification{}, sql.ErrNoRows
+	}
+	return d.emailVerifications[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteEmailVerificationsByUserId(ctx context.Context, userId uuid.UUID) error {
+	var tempVerifications []db.EmailVerification
+	for _, verification := range d.emailVerifications {
+		if verification.UserID != userId {
+			tempVerifications = append(tempVerifications, verification)
+		}
+	}
+	d.emailVerifications = tempVerifications
+	return nil
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ErrUnknownKeyId      = errors.New("Token was signed with an unknown key")
+	ErrUnexpectedAlg     = errors.New("Token was signed with an unexpected algorithm")
+	ErrUnsupportedKey    = errors.New("Unsupported signing key, use an Ed25519 or RSA private key")
+	ErrNoSigningKey      = errors.New("No signing key configured, set JWT_KEY_FILES or JWT_ACCESS_TOKEN")
+	ErrUnsupportedKeyAlg = errors.New("Unsupported signing algorithm, use EdDSA or RS256")
+)
+
+// hmacKeyId is the key id of the legacy shared secret. Tokens signed before key
+// ids existed carry no kid at all and are matched against it as well.
+const hmacKeyId = "hs256"
+
+// SigningKey is one key of a KeySet. Private signs new tokens, Public verifies
+// them. For HS256 both are the shared secret.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private interface{}
+	Public  interface{}
+}
+
+// KeySet holds the key new tokens are signed with and every key whose tokens
+// are still accepted. Rotating means adding a new current key and keeping the
+// previous one around until the tokens it signed have expired.
+type KeySet struct {
+	current SigningKey
+	keys    map[string]SigningKey
+}
+
+// JsonWebKey is the public part of a signing key as described in RFC 7517.
+type JsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JsonWebKeySet struct {
+	Keys []JsonWebKey `json:"keys"`
+}
+
+func NewKeySet(current SigningKey, previous ...SigningKey) (*KeySet, error) {
+	set := &KeySet{
+		current: current,
+		keys:    map[string]SigningKey{},
+	}
+	for _, key := range append([]SigningKey{current}, previous...) {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	return set, nil
+}
+
+func NewHmacKeySet(secret string) *KeySet {
+	set, _ := NewKeySet(hmacKey(secret))
+	return set
+}
+
+func hmacKey(secret string) SigningKey {
+	return SigningKey{
+		ID:      hmacKeyId,
+		Method:  jwt.SigningMethodHS256,
+		Private: []byte(secret),
+		Public:  []byte(secret),
+	}
+}
+
+// NewKeySetFromConfig loads the PEM encoded private keys in cfg.KeyFiles. The
+// first one signs new tokens, the others only verify. The key id is the file
+// name without extension. Without key files the shared HS256 secret is used.
+// With both the secret only verifies, so the tokens it signed stay valid while
+// an instance switches to key files.
+func NewKeySetFromConfig(cfg config.JwtConfig) (*KeySet, error) {
+	if len(cfg.KeyFiles) == 0 {
+		if cfg.AccessToken == "" {
+			return nil, ErrNoSigningKey
+		}
+		return NewHmacKeySet(cfg.AccessToken), nil
+	}
+
+	var keys []SigningKey
+	for _, file := range cfg.KeyFiles {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		key, err := ParseSigningKeyPem(id, pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, key)
+	}
+	if cfg.AccessToken != "" {
+		keys = append(keys, hmacKey(cfg.AccessToken))
+	}
+	return NewKeySet(keys[0], keys[1:]...)
+}
+
+// ParseSigningKeyPem reads a PKCS#8 (or PKCS#1 for RSA) private key, as written
+// by `openssl genpkey -algorithm ed25519` or `openssl genrsa`.
+func ParseSigningKeyPem(id string, pemBytes []byte) (SigningKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return SigningKey{}, ErrUnsupportedKey
+	}
+
+	var private interface{}
+	var err error
+	if block.Type == "RSA PRIVATE KEY" {
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	switch key := private.(type) {
+	case ed25519.PrivateKey:
+		return SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, Private: key, Public: key.Public()}, nil
+	case *rsa.PrivateKey:
+		return SigningKey{ID: id, Method: jwt.SigningMethodRS256, Private: key, Public: &key.PublicKey}, nil
+	}
+	return SigningKey{}, ErrUnsupportedKey
+}
+
+// GenerateSigningKey creates a new key for alg ("EdDSA" or "RS256") and returns
+// it together with its PEM encoding so it can be stored as a key file.
+func GenerateSigningKey(id string, alg string) (SigningKey, []byte, error) {
+	var private interface{}
+	var err error
+	switch alg {
+	case jwt.SigningMethodEdDSA.Alg():
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case jwt.SigningMethodRS256.Alg():
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return SigningKey{}, nil, ErrUnsupportedKeyAlg
+	}
+	if err != nil {
+		return SigningKey{}, nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return SigningKey{}, nil, err
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	key, err := ParseSigningKeyPem(id, pemBytes)
+	return key, pemBytes, err
+}
+
+// Sign signs the claims with the current key and names it in the kid header.
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.Method, claims)
+	token.Header["kid"] = k.current.ID
+	return token.SignedString(k.current.Private)
+}
+
+// Keyfunc picks the verification key for a parsed token. The algorithm has to
+// match the key so a public key can never be used as an HMAC secret.
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = hmacKeyId
+	}
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyId
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrUnexpectedAlg
+	}
+	return key.Public, nil
+}
+
+// JWKS returns the public keys of the set. Shared secrets are never published.
+func (k *KeySet) JWKS() JsonWebKeySet {
+	set := JsonWebKeySet{Keys: []JsonWebKey{}}
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		key := k.keys[id]
+		jwk := JsonWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch public := key.Public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// KeySet turns published keys back into a set that can only verify tokens,
+// e.g. the keys of an OpenID Connect provider.
+func (s JsonWebKeySet) KeySet() (*KeySet, error) {
+	var keys []SigningKey
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.signingKey()
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, ErrUnsupportedKey
+	}
+	return NewKeySet(keys[0], keys[1:]...)
+}
+
+func (k JsonWebKey) signingKey() (SigningKey, error) {
+	switch {
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return SigningKey{}, ErrUnsupportedKey
+		}
+		return SigningKey{ID: k.Kid, Method: jwt.SigningMethodEdDSA, Public: ed25519.PublicKey(x)}, nil
+	case k.Kty == "RSA" && (k.Alg == "" || k.Alg == jwt.SigningMethodRS256.Alg()):
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return SigningKey{ID: k.Kid, Method: jwt.SigningMethodRS256, Public: public}, nil
+	}
+	return SigningKey{}, ErrUnsupportedKey
+}
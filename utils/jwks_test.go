@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testClaim() CustomTokenClaim {
+	return CustomTokenClaim{
+		UserID:   uuid.New(),
+		Username: "tim",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	}
+}
+
+func parse(keys *KeySet, token string) (*CustomTokenClaim, error) {
+	claim := &CustomTokenClaim{}
+	_, err := jwt.ParseWithClaims(token, claim, keys.Keyfunc)
+	return claim, err
+}
+
+func TestKeySetSignAndVerify(t *testing.T) {
+	for _, alg := range []string{"EdDSA", "RS256"} {
+		t.Run(alg, func(t *testing.T) {
+			key, _, err := GenerateSigningKey("key-1", alg)
+			assert.NoError(t, err)
+			keys, err := NewKeySet(key)
+			assert.NoError(t, err)
+
+			claim := testClaim()
+			token, err := keys.Sign(claim)
+			assert.NoError(t, err)
+
+			parsed, err := jwt.Parse(token, keys.Keyfunc)
+			if assert.NoError(t, err) {
+				assert.Equal(t, alg, parsed.Method.Alg())
+				assert.Equal(t, "key-1", parsed.Header["kid"])
+			}
+
+			got, err := parse(keys, token)
+			assert.NoError(t, err)
+			assert.Equal(t, claim.UserID, got.UserID)
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, _, err := GenerateSigningKey("old", "EdDSA")
+	assert.NoError(t, err)
+	newKey, _, err := GenerateSigningKey("new", "RS256")
+	assert.NoError(t, err)
+
+	oldKeys, _ := NewKeySet(oldKey)
+	oldToken, err := oldKeys.Sign(testClaim())
+	assert.NoError(t, err)
+
+	rotated, err := NewKeySet(newKey, oldKey)
+	assert.NoError(t, err)
+	_, err = parse(rotated, oldToken)
+	assert.NoError(t, err, "token of the previous key should still verify")
+
+	newToken, err := rotated.Sign(testClaim())
+	assert.NoError(t, err)
+	parsed, _ := jwt.Parse(newToken, rotated.Keyfunc)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	retired, _ := NewKeySet(newKey)
+	_, err = parse(retired, oldToken)
+	assert.ErrorIs(t, err, ErrUnknownKeyId)
+
+	_, err = NewKeySet(newKey, newKey)
+	assert.Error(t, err, "duplicate key ids must be rejected")
+}
+
+func TestKeySetRejectsAlgorithmConfusion(t *testing.T) {
+	key, _, err := GenerateSigningKey("key-1", "RS256")
+	assert.NoError(t, err)
+	keys, _ := NewKeySet(key)
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	assert.NoError(t, err)
+	publicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	// a token "signed" with HS256 using the published public key as secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim())
+	forged.Header["kid"] = "key-1"
+	token, err := forged.SignedString(publicPem)
+	assert.NoError(t, err)
+
+	_, err = parse(keys, token)
+	assert.ErrorIs(t, err, ErrUnexpectedAlg)
+}
+
+func TestHmacKeySetAcceptsTokensWithoutKid(t *testing.T) {
+	keys := NewHmacKeySet("secret")
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim()).SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = parse(keys, legacy)
+	assert.NoError(t, err)
+	assert.Empty(t, keys.JWKS().Keys, "shared secrets must not be published")
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	edKey, _, _ := GenerateSigningKey("a", "EdDSA")
+	rsaKey, _, _ := GenerateSigningKey("b", "RS256")
+	keys, _ := NewKeySet(rsaKey, edKey)
+
+	jwks := keys.JWKS()
+	if assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, JsonWebKey{Kty: "OKP", Kid: "a", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: jwks.Keys[0].X}, jwks.Keys[0])
+		assert.NotEmpty(t, jwks.Keys[0].X)
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "RS256", jwks.Keys[1].Alg)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+		assert.NotEmpty(t, jwks.Keys[1].N)
+	}
+}
+
+func TestNewKeySetFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, currentPem, _ := GenerateSigningKey("", "EdDSA")
+	_, previousPem, _ := GenerateSigningKey("", "RS256")
+	currentFile := filepath.Join(dir, "2024-02.pem")
+	previousFile := filepath.Join(dir, "2024-01.pem")
+	assert.NoError(t, os.WriteFile(currentFile, currentPem, 0600))
+	assert.NoError(t, os.WriteFile(previousFile, previousPem, 0600))
+
+	keys, err := NewKeySetFromConfig(config.JwtConfig{KeyFiles: []string{currentFile, previousFile}})
+	if assert.NoError(t, err) {
+		token, err := keys.Sign(testClaim())
+		assert.NoError(t, err)
+		parsed, err := jwt.Parse(token, keys.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-02", parsed.Header["kid"])
+		assert.Len(t, keys.JWKS().Keys, 2)
+	}
+
+	switching, err := NewKeySetFromConfig(config.JwtConfig{AccessToken: "secret", KeyFiles: []string{currentFile}})
+	if assert.NoError(t, err) {
+		legacy, err := NewHmacKeySet("secret").Sign(testClaim())
+		assert.NoError(t, err)
+		_, err = jwt.Parse(legacy, switching.Keyfunc)
+		assert.NoError(t, err, "tokens of the shared secret stay valid while switching")
+
+		token, err := switching.Sign(testClaim())
+		assert.NoError(t, err)
+		parsed, err := jwt.Parse(token, switching.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-02", parsed.Header["kid"])
+		assert.Len(t, switching.JWKS().Keys, 1)
+	}
+
+	_, err = NewKeySetFromConfig(config.JwtConfig{})
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	keys, err = NewKeySetFromConfig(config.JwtConfig{AccessToken: "secret"})
+	assert.NoError(t, err)
+	assert.Empty(t, keys.JWKS().Keys)
+}
@@ -10,28 +10,34 @@ import (
 )
 
 type TokenGenInput struct {
-	UserId        uuid.UUID
-	Username      string
-	Email         string
-	ExpiryDate    time.Time
-	SigningKey    string
-	IsAccessToken bool
+	UserId     uuid.UUID
+	SessionId  uuid.UUID
+	Username   string
+	Email      string
+	ExpiryDate time.Time
 }
 
+// TokenGenerator signs access tokens and hands out the keys to verify them, so
+// whatever signed a token is also what checks it.
 type TokenGenerator interface {
 	GenerateNewJwtToken(
 		input TokenGenInput,
 	) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	JWKS() JsonWebKeySet
 }
 
 type CustomTokenClaim struct {
-	UserID   uuid.UUID `json:"userId"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
+	UserID    uuid.UUID `json:"userId"`
+	SessionID uuid.UUID `json:"sessionId"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
 	jwt.RegisteredClaims
 }
 
-type ProdTokenGenerator struct{}
+type ProdTokenGenerator struct {
+	Keys *KeySet
+}
 
 const (
 	OneDay   = 24 * time.Hour
@@ -43,6 +49,7 @@ func (t *ProdTokenGenerator) GenerateNewJwtToken(
 ) (string, error) {
 	tokenClaim := CustomTokenClaim{
 		input.UserId,
+		input.SessionId,
 		input.Username,
 		input.Email,
 		jwt.RegisteredClaims{
@@ -50,11 +57,17 @@ func (t *ProdTokenGenerator) GenerateNewJwtToken(
 		},
 	}
 
-	tokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaim)
-
-	token, err := tokenObj.SignedString([]byte(input.SigningKey))
+	token, err := t.Keys.Sign(tokenClaim)
 	if err != nil {
 		return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	return token, nil
 }
+
+func (t *ProdTokenGenerator) Keyfunc(token *jwt.Token) (interface{}, error) {
+	return t.Keys.Keyfunc(token)
+}
+
+func (t *ProdTokenGenerator) JWKS() JsonWebKeySet {
+	return t.Keys.JWKS()
+}
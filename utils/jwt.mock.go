@@ -2,26 +2,38 @@ package utils
 
 import (
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// mockKeys is shared by every MockTokenGenerator without own keys, so tokens
+// from one mock verify with any other.
+var mockKeys = NewHmacKeySet("Test")
+
 type MockTokenGenerator struct {
-	CallOut           int
-	ExpiryDateAccess  time.Duration
-	ExpiryDateRefresh time.Duration
+	CallOut          int
+	ExpiryDateAccess time.Duration
+	Keys             *KeySet
+}
+
+func (t *MockTokenGenerator) keys() *KeySet {
+	if t.Keys == nil {
+		return mockKeys
+	}
+	return t.Keys
 }
 
 func (t *MockTokenGenerator) GenerateNewJwtToken(input TokenGenInput) (string, error) {
-	gen := ProdTokenGenerator{}
+	gen := ProdTokenGenerator{Keys: t.keys()}
 	t.CallOut++
-	if input.IsAccessToken {
-		time := time.Now().Add(t.ExpiryDateAccess)
-		input.ExpiryDate = time
-		token, err := gen.GenerateNewJwtToken(input)
-		return token, err
-	}
+	input.ExpiryDate = time.Now().Add(t.ExpiryDateAccess)
+	return gen.GenerateNewJwtToken(input)
+}
+
+func (t *MockTokenGenerator) Keyfunc(token *jwt.Token) (interface{}, error) {
+	return t.keys().Keyfunc(token)
+}
 
-	time := time.Now().Add(t.ExpiryDateRefresh)
-	input.ExpiryDate = time
-	token, err := gen.GenerateNewJwtToken(input)
-	return token, err
+func (t *MockTokenGenerator) JWKS() JsonWebKeySet {
+	return t.keys().JWKS()
 }
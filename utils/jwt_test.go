@@ -20,7 +20,7 @@ type TestGenerateJwtInput struct {
 	input TokenGenInput
 }
 
-var tokenGenerator = ProdTokenGenerator{}
+var tokenGenerator = ProdTokenGenerator{Keys: NewHmacKeySet("Random")}
 
 func TestGenerateJwt(t *testing.T) {
 	testInput := []TestGenerateJwtInput{
@@ -31,12 +31,10 @@ func TestGenerateJwt(t *testing.T) {
 				ExpectedError: nil,
 			},
 			input: TokenGenInput{
-				UserId:        uuid.New(),
-				Username:      "tim",
-				Email:         "tim@test",
-				ExpiryDate:    time.Now().Add(5 * time.Minute),
-				SigningKey:    "Random",
-				IsAccessToken: true,
+				UserId:     uuid.New(),
+				Username:   "tim",
+				Email:      "tim@test",
+				ExpiryDate: time.Now().Add(5 * time.Minute),
 			},
 		},
 		{
@@ -46,12 +44,10 @@ func TestGenerateJwt(t *testing.T) {
 				ExpectedError: nil,
 			},
 			input: TokenGenInput{
-				UserId:        uuid.New(),
-				Username:      "tim",
-				Email:         "tim@test",
-				ExpiryDate:    time.Now(),
-				SigningKey:    "Random",
-				IsAccessToken: true,
+				UserId:     uuid.New(),
+				Username:   "tim",
+				Email:      "tim@test",
+				ExpiryDate: time.Now(),
 			},
 		},
 	}
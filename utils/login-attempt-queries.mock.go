@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+func (d *DBQueriesMock) GetLoginAttempt(ctx context.Context, key string) (db.LoginAttempt, error) {
+	attempt, ok := d.loginAttempts[key]
+	if !ok {
+		return db.LoginAttempt{}, sql.ErrNoRows
+	}
+	return attempt, nil
+}
+
+func (d *DBQueriesMock) RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginAttempt, error) {
+	attempt, ok := d.loginAttempts[arg.Key]
+	if !ok {
+		attempt = db.LoginAttempt{Key: arg.Key, BlockedUntil: arg.FailedAt}
+	}
+	if attempt.LastFailureAt.Before(arg.WindowStart) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = arg.FailedAt
+	d.loginAttempts[arg.Key] = attempt
+	return attempt, nil
+}
+
+func (d *DBQueriesMock) BlockLoginAttempt(ctx context.Context, arg db.BlockLoginAttemptParams) error {
+	attempt, ok := d.loginAttempts[arg.Key]
+	if ok {
+		attempt.BlockedUntil = arg.BlockedUntil
+		d.loginAttempts[arg.Key] = attempt
+	}
+	return nil
+}
+
+func (d *DBQueriesMock) DeleteLoginAttempt(ctx context.Context, key string) error {
+	delete(d.loginAttempts, key)
+	return nil
+}
+
+func (d *DBQueriesMock) DeleteStaleLoginAttempts(ctx context.Context, arg db.DeleteStaleLoginAttemptsParams) error {
+	for key, attempt := range d.loginAttempts {
+		if attempt.LastFailureAt.Before(arg.WindowStart) && !attempt.BlockedUntil.After(arg.Now) {
+			delete(d.loginAttempts, key)
+		}
+	}
+	return nil
+}
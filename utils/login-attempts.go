@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+// LoginAttemptStore keeps count of failed logins per key (an account or an IP).
+type LoginAttemptStore interface {
+	// Get returns the attempts for key, or a zero LoginAttempt if there are none.
+	Get(ctx context.Context, key string) (db.LoginAttempt, error)
+	// RecordFailure counts a failed login. Failures from before windowStart are
+	// forgotten and counting starts over.
+	RecordFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time) (db.LoginAttempt, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// NewLoginAttemptStore returns the store selected by LOGIN_STORE. Use "postgres"
+// when more than one instance of the api is running, so they share the counts.
+func NewLoginAttemptStore(cfg config.LoginConfig, queries db.Querier) (LoginAttemptStore, error) {
+	switch cfg.Store {
+	case "postgres":
+		return &PostgresLoginAttemptStore{DB: queries}, nil
+	case "memory", "":
+		return NewMemoryLoginAttemptStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown login attempt store %q", cfg.Store)
+	}
+}
+
+// PostgresLoginAttemptStore keeps login attempts in the login_attempts table.
+// Like the memory store it deletes stale keys every pruneEvery failures it
+// records, so the table doesn't grow with every IP that ever failed. Pruning is
+// housekeeping, when it fails the error is logged and the failure is still
+// counted.
+type PostgresLoginAttemptStore struct {
+	DB     db.Querier
+	writes atomic.Int64
+}
+
+func (s *PostgresLoginAttemptStore) Get(ctx context.Context, key string) (db.LoginAttempt, error) {
+	attempt, err := s.DB.GetLoginAttempt(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.LoginAttempt{Key: key}, nil
+	}
+	return attempt, err
+}
+
+func (s *PostgresLoginAttemptStore) RecordFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time) (db.LoginAttempt, error) {
+	if s.writes.Add(1)%pruneEvery == 0 {
+		err := s.DB.DeleteStaleLoginAttempts(ctx, db.DeleteStaleLoginAttemptsParams{
+			WindowStart: windowStart,
+			Now:         failedAt,
+		})
+		if err != nil {
+			log.Printf("pruning stale login attempts failed: %v\n", err)
+		}
+	}
+
+	return s.DB.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		Key:         key,
+		FailedAt:    failedAt,
+		WindowStart: windowStart,
+	})
+}
+
+func (s *PostgresLoginAttemptStore) Block(ctx context.Context, key string, until time.Time) error {
+	return s.DB.BlockLoginAttempt(ctx, db.BlockLoginAttemptParams{BlockedUntil: until, Key: key})
+}
+
+func (s *PostgresLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.DB.DeleteLoginAttempt(ctx, key)
+}
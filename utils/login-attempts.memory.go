@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+// pruneEvery is how many recorded failures pass between sweeps for stale keys.
+const pruneEvery = 256
+
+// MemoryLoginAttemptStore keeps login attempts in the process. Counts are lost
+// on restart and not shared between instances.
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]db.LoginAttempt
+	writes   int
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{attempts: map[string]db.LoginAttempt{}}
+}
+
+func (s *MemoryLoginAttemptStore) Get(ctx context.Context, key string) (db.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return db.LoginAttempt{Key: key}, nil
+	}
+	return attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordFailure(ctx context.Context, key string, failedAt time.Time, windowStart time.Time) (db.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writes++
+	if s.writes%pruneEvery == 0 {
+		s.prune(failedAt, windowStart)
+	}
+
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(windowStart) {
+		attempt = db.LoginAttempt{Key: key, BlockedUntil: attempt.BlockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = failedAt
+	s.attempts[key] = attempt
+
+	return attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) Block(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil
+	}
+	attempt.BlockedUntil = until
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune drops keys that are neither blocked nor inside the window anymore.
+func (s *MemoryLoginAttemptStore) prune(now time.Time, windowStart time.Time) {
+	for key, attempt := range s.attempts {
+		if attempt.LastFailureAt.Before(windowStart) && !attempt.BlockedUntil.After(now) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+)
+
+func TestMemoryLoginAttemptStore(t *testing.T) {
+	store := NewMemoryLoginAttemptStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	attempt, err := store.Get(ctx, "ip:1")
+	if err != nil || attempt.Failures != 0 {
+		t.Fatalf("store.Get(unknown) = %+v, %v, want no failures", attempt, err)
+	}
+
+	store.RecordFailure(ctx, "ip:1", now, now.Add(-time.Hour))
+	attempt, _ = store.RecordFailure(ctx, "ip:1", now, now.Add(-time.Hour))
+	if attempt.Failures != 2 {
+		t.Fatalf("store.RecordFailure() = %+v, want 2 failures", attempt)
+	}
+
+	store.Block(ctx, "ip:1", now.Add(time.Minute))
+	attempt, _ = store.RecordFailure(ctx, "ip:1", now.Add(2*time.Hour), now.Add(time.Hour))
+	if attempt.Failures != 1 {
+		t.Fatalf("store.RecordFailure() after the window = %+v, want 1 failure", attempt)
+	}
+
+	store.Reset(ctx, "ip:1")
+	attempt, _ = store.Get(ctx, "ip:1")
+	if attempt.Failures != 0 {
+		t.Fatalf("store.Get() after reset = %+v, want no failures", attempt)
+	}
+}
+
+func TestPostgresLoginAttemptStorePrunes(t *testing.T) {
+	d := NewDBQueriesMock()
+	store := &PostgresLoginAttemptStore{DB: d}
+	ctx := context.Background()
+	now := time.Now()
+
+	store.RecordFailure(ctx, "ip:old", now.Add(-2*time.Hour), now.Add(-3*time.Hour))
+	store.RecordFailure(ctx, "ip:blocked", now.Add(-2*time.Hour), now.Add(-3*time.Hour))
+	store.Block(ctx, "ip:blocked", now.Add(time.Minute))
+	for i := 2; i < pruneEvery; i++ {
+		if _, err := store.RecordFailure(ctx, "ip:new", now, now.Add(-time.Hour)); err != nil {
+			t.Fatalf("store.RecordFailure() = %v, want nil", err)
+		}
+	}
+
+	for key, want := range map[string]int32{"ip:old": 0, "ip:blocked": 1, "ip:new": pruneEvery - 2} {
+		attempt, _ := store.Get(ctx, key)
+		if attempt.Failures != want {
+			t.Fatalf("store.Get(%q) = %d failures, want %d", key, attempt.Failures, want)
+		}
+	}
+}
+
+// failingPruneQuerier can't delete stale login attempts.
+type failingPruneQuerier struct {
+	*DBQueriesMock
+}
+
+func (q failingPruneQuerier) DeleteStaleLoginAttempts(ctx context.Context, arg db.DeleteStaleLoginAttemptsParams) error {
+	return errors.New("statement timeout")
+}
+
+func TestPostgresLoginAttemptStoreRecordsWhenPruningFails(t *testing.T) {
+	store := &PostgresLoginAttemptStore{DB: failingPruneQuerier{NewDBQueriesMock()}}
+	ctx := context.Background()
+	now := time.Now()
+
+	for i := 0; i < pruneEvery; i++ {
+		if _, err := store.RecordFailure(ctx, "ip:new", now, now.Add(-time.Hour)); err != nil {
+			t.Fatalf("store.RecordFailure() = %v, want nil", err)
+		}
+	}
+
+	attempt, _ := store.Get(ctx, "ip:new")
+	if attempt.Failures != pruneEvery {
+		t.Fatalf("store.Get() = %d failures, want %d", attempt.Failures, pruneEvery)
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// LogMailer doesn't deliver anything, it writes every mail to Out and keeps
+// it in Sent. It is meant for development and tests.
+type LogMailer struct {
+	Out  io.Writer
+	From string
+
+	mu   sync.Mutex
+	Sent []Message
+}
+
+// NewLogMailer appends mails to the file at path, or writes them to stdout if
+// path is empty.
+func NewLogMailer(path string, from string) (*LogMailer, error) {
+	if path == "" {
+		return &LogMailer{Out: os.Stdout, From: from}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &LogMailer{Out: file, From: from}, nil
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Sent = append(m.Sent, msg)
+	if m.Out == nil {
+		return nil
+	}
+
+	_, err := m.Out.Write(append(buildMessage(m.From, msg), "\r\n\r\n"...))
+	return err
+}
+
+// LastTo returns the last mail that was sent to the given address.
+func (m *LogMailer) LastTo(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.Sent) - 1; i >= 0; i-- {
+		if m.Sent[i].To == to {
+			return m.Sent[i], true
+		}
+	}
+	return Message{}, false
+}
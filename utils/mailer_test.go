@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMailer(t *testing.T) {
+	out := new(bytes.Buffer)
+	mailer := &LogMailer{Out: out, From: "no-reply@test.de"}
+
+	msg := Message{To: "laurin@test.de", Subject: "Hello", Body: "first line\nsecond line"}
+	err := mailer.Send(context.Background(), msg)
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "From: no-reply@test.de\r\n")
+	assert.Contains(t, out.String(), "To: laurin@test.de\r\n")
+	assert.Contains(t, out.String(), "Subject: Hello\r\n")
+	assert.Contains(t, out.String(), "first line\r\nsecond line")
+
+	sent, ok := mailer.LastTo("laurin@test.de")
+	assert.True(t, ok)
+	assert.Equal(t, msg, sent)
+
+	_, ok = mailer.LastTo("max@test.de")
+	assert.False(t, ok)
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(config.MailConfig{Driver: "log", LogFile: filepath.Join(t.TempDir(), "mails.log")})
+	if assert.NoError(t, err) {
+		assert.IsType(t, &LogMailer{}, mailer)
+	}
+
+	mailer, err = NewMailer(config.MailConfig{Driver: "smtp", SmtpHost: "localhost", SmtpPort: 25})
+	if assert.NoError(t, err) {
+		assert.IsType(t, &SmtpMailer{}, mailer)
+	}
+
+	_, err = NewMailer(config.MailConfig{Driver: "smtp"})
+	assert.Error(t, err)
+
+	_, err = NewMailer(config.MailConfig{Driver: "pigeon"})
+	assert.Error(t, err)
+}
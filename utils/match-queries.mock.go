@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+)
+
+func (d *DBQueriesMock) CreateMatch(ctx context.Context, arg db.CreateMatchParams) (db.Match, error) {
+	if arg.TeamOne == arg.TeamTwo {
+		return db.Match{}, checkViolation("matches", "CK_Matches_DistinctPlayers")
+	}
+	if !d.hasClub(arg.ClubID) {
+		return db.Match{}, foreignKeyViolation("matches", "FK_Matches.club_id")
+	}
+	if !d.hasTeam(arg.TeamOne) {
+		return db.Match{}, foreignKeyViolation("matches", "FK_Matches.team_one")
+	}
+	if !d.hasTeam(arg.TeamTwo) {
+		return db.Match{}, foreignKeyViolation("matches", "FK_Matches.team_two")
+	}
+
+	match := db.Match{
+		ID:           uuid.New(),
+		NumberOfSets: arg.NumberOfSets,
+		TeamOne:      arg.TeamOne,
+		TeamTwo:      arg.TeamTwo,
+		CreatedAt:    dbNow(),
+		UpdatedAt:    dbNow(),
+		ClubID:       arg.ClubID,
+	}
+	d.matches = append(d.matches, match)
+	return match, nil
+}
+
+func (d *DBQueriesMock) CreateSet(ctx context.Context, matchID uuid.UUID) (db.Set, error) {
+	if !d.hasMatch(matchID) {
+		return db.Set{}, foreignKeyViolation("sets", "FK_Sets.match_id")
+	}
+
+	set := db.Set{
+		ID:        uuid.New(),
+		MatchID:   matchID,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+	}
+	d.sets = append(d.sets, set)
+	return set, nil
+}
+
+func (d *DBQueriesMock) CreateGame(ctx context.Context, arg db.CreateGameParams) (db.Game, error) {
+	if !d.hasTeam(arg.ServerID) {
+		return db.Game{}, foreignKeyViolation("games", "FK_Games.server_id")
+	}
+	if arg.SetID != nil && !d.hasSet(*arg.SetID) {
+		return db.Game{}, foreignKeyViolation("games", "FK_Games.set_id")
+	}
+
+	game := db.Game{
+		ID:        uuid.New(),
+		ServerID:  arg.ServerID,
+		SetID:     arg.SetID,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+	}
+	d.games = append(d.games, game)
+	return game, nil
+}
+
+func (d *DBQueriesMock) CreatePoint(ctx context.Context, arg db.CreatePointParams) (db.Point, error) {
+	if !d.hasTeam(arg.TeamID) {
+		return db.Point{}, foreignKeyViolation("points", "FK_Points.team_id")
+	}
+	if arg.GameID != nil && !d.hasGame(*arg.GameID) {
+		return db.Point{}, foreignKeyViolation("points", "FK_Points.game_id")
+	}
+
+	point := db.Point{
+		ID:          uuid.New(),
+		Value:       arg.Value,
+		TeamID:      arg.TeamID,
+		CreatedAt:   dbNow(),
+		UpdatedAt:   dbNow(),
+		GameID:      arg.GameID,
+		PointsOrder: arg.PointsOrder,
+	}
+	d.points = append(d.points, point)
+	return point, nil
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type stubAuthorization struct {
+	identity    OIDCIdentity
+	nonce       string
+	redirectUrl string
+}
+
+// StubOIDCServer is a minimal local OpenID Connect provider for tests. Its
+// login page doesn't ask anything, it logs in whoever was set with LoginAs.
+type StubOIDCServer struct {
+	*httptest.Server
+	ClientId     string
+	ClientSecret string
+
+	keys *KeySet
+
+	mu       sync.Mutex
+	identity OIDCIdentity
+	codes    map[string]stubAuthorization
+}
+
+func NewStubOIDCServer(clientId string, clientSecret string) (*StubOIDCServer, error) {
+	key, _, err := GenerateSigningKey("stub", jwt.SigningMethodRS256.Alg())
+	if err != nil {
+		return nil, err
+	}
+	keys, err := NewKeySet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StubOIDCServer{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		keys:         keys,
+		codes:        map[string]stubAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	return s, nil
+}
+
+// Config points a client at the stub.
+func (s *StubOIDCServer) Config() config.OIDCConfig {
+	return config.OIDCConfig{
+		Issuer:       s.URL,
+		ClientId:     s.ClientId,
+		ClientSecret: s.ClientSecret,
+		Scopes:       []string{"openid", "email", "profile"},
+		ProviderName: "Stub",
+	}
+}
+
+// LoginAs sets who the next login is for. The issuer is always the stub.
+func (s *StubOIDCServer) LoginAs(identity OIDCIdentity) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identity = identity
+}
+
+func (s *StubOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, oidcDiscovery{
+		Issuer:                s.URL,
+		AuthorizationEndpoint: s.URL + "/authorize",
+		TokenEndpoint:         s.URL + "/token",
+		JwksUri:               s.URL + "/jwks",
+	})
+}
+
+func (s *StubOIDCServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectUrl, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != s.ClientId || query.Get("response_type") != "code" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := GenerateOpaqueToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.codes[code] = stubAuthorization{
+		identity:    s.identity,
+		nonce:       query.Get("nonce"),
+		redirectUrl: redirectUrl.String(),
+	}
+	s.mu.Unlock()
+
+	callback := redirectUrl.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectUrl.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectUrl.String(), http.StatusFound)
+}
+
+func (s *StubOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != url.QueryEscape(s.ClientId) || clientSecret != url.QueryEscape(s.ClientSecret) {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+	s.mu.Lock()
+	authorization, found := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !found || r.PostFormValue("grant_type") != "authorization_code" || authorization.redirectUrl != r.PostFormValue("redirect_uri") {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken, err := s.keys.Sign(oidcClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.URL,
+			Subject:   authorization.identity.Subject,
+			Audience:  jwt.ClaimStrings{s.ClientId},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce:             authorization.nonce,
+		Email:             authorization.identity.Email,
+		EmailVerified:     authorization.identity.EmailVerified,
+		PreferredUsername: authorization.identity.PreferredUsername,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJson(w, http.StatusOK, map[string]interface{}{
+		"access_token": code,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *StubOIDCServer) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, s.keys.JWKS())
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Laurin-Notemann/tennis-analysis/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrOIDCIssuerMismatch = errors.New("OpenID provider reported a different issuer")
+	ErrOIDCNoIdToken      = errors.New("OpenID provider didn't return an id token")
+	ErrOIDCNonceMismatch  = errors.New("Id token was issued for another login")
+)
+
+// OIDCIdentity is who the provider says logged in. Subject is only unique
+// together with the Issuer.
+type OIDCIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+}
+
+// OIDCProvider is an OpenID Connect provider that logs users in with the
+// authorization code flow.
+type OIDCProvider interface {
+	// AuthCodeURL is where the user is sent to log in. The provider sends them
+	// back to redirectUrl with a code and the state.
+	AuthCodeURL(state string, nonce string, redirectUrl string) string
+	// Exchange redeems the code and returns the identity from the verified id
+	// token, which has to carry nonce.
+	Exchange(ctx context.Context, code string, nonce string, redirectUrl string) (OIDCIdentity, error)
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// OIDCClient talks to a real provider found through its discovery document.
+// The signing keys are fetched on first use and again when a token names a
+// key that isn't known yet.
+type OIDCClient struct {
+	cfg       config.OIDCConfig
+	http      *http.Client
+	discovery oidcDiscovery
+
+	mu   sync.Mutex
+	keys *KeySet
+}
+
+// NewOIDCClient loads the discovery document of the configured issuer.
+func NewOIDCClient(ctx context.Context, cfg config.OIDCConfig, client *http.Client) (*OIDCClient, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	c := &OIDCClient{cfg: cfg, http: client}
+
+	issuer := strings.TrimSuffix(cfg.Issuer, "/")
+	err := c.getJson(ctx, issuer+"/.well-known/openid-configuration", &c.discovery)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(c.discovery.Issuer, "/") != issuer {
+		return nil, ErrOIDCIssuerMismatch
+	}
+	return c, nil
+}
+
+func (c *OIDCClient) AuthCodeURL(state string, nonce string, redirectUrl string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.cfg.ClientId},
+		"redirect_uri":  {redirectUrl},
+		"scope":         {strings.Join(c.cfg.Scopes, " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	separator := "?"
+	if strings.Contains(c.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return c.discovery.AuthorizationEndpoint + separator + query.Encode()
+}
+
+func (c *OIDCClient) Exchange(ctx context.Context, code string, nonce string, redirectUrl string) (OIDCIdentity, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectUrl},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientId), url.QueryEscape(c.cfg.ClientSecret))
+
+	var tokens struct {
+		IdToken string `json:"id_token"`
+	}
+	err = c.doJson(req, &tokens)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	if tokens.IdToken == "" {
+		return OIDCIdentity{}, ErrOIDCNoIdToken
+	}
+
+	return c.verifyIdToken(ctx, tokens.IdToken, nonce)
+}
+
+func (c *OIDCClient) verifyIdToken(ctx context.Context, idToken string, nonce string) (OIDCIdentity, error) {
+	claims := oidcClaims{}
+	_, err := jwt.ParseWithClaims(
+		idToken,
+		&claims,
+		func(token *jwt.Token) (interface{}, error) { return c.keyfunc(ctx, token) },
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(c.discovery.Issuer),
+		jwt.WithAudience(c.cfg.ClientId),
+	)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	if claims.ExpiresAt == nil {
+		return OIDCIdentity{}, jwt.ErrTokenRequiredClaimMissing
+	}
+	if claims.Nonce != nonce {
+		return OIDCIdentity{}, ErrOIDCNonceMismatch
+	}
+
+	return OIDCIdentity{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// keyfunc finds the provider key of token and reloads the keys once if the
+// provider rotated to a key we haven't seen.
+func (c *OIDCClient) keyfunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for reloaded := false; ; reloaded = true {
+		if c.keys == nil || reloaded {
+			set := JsonWebKeySet{}
+			err := c.getJson(ctx, c.discovery.JwksUri, &set)
+			if err != nil {
+				return nil, err
+			}
+			c.keys, err = set.KeySet()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// a provider with a single key doesn't have to name it
+		if _, named := token.Header["kid"]; !named && len(c.keys.keys) == 1 {
+			token.Header["kid"] = c.keys.current.ID
+		}
+		key, err := c.keys.Keyfunc(token)
+		if !errors.Is(err, ErrUnknownKeyId) || reloaded {
+			return key, err
+		}
+	}
+}
+
+func (c *OIDCClient) getJson(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.doJson(req, v)
+}
+
+func (c *OIDCClient) doJson(req *http.Request, v interface{}) error {
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: provider answered %s", req.Method, req.URL.Redacted(), res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
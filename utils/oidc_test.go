@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const stubRedirectUrl = "http://localhost:3000/api/oidc/callback"
+
+// stubLogin follows the stub login page and returns the code it redirects with.
+func stubLogin(t *testing.T, client *OIDCClient, state string, nonce string) string {
+	t.Helper()
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := noRedirect.Get(client.AuthCodeURL(state, nonce, stubRedirectUrl))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestJsonWebKeySetRoundTrip(t *testing.T) {
+	for _, alg := range []string{"EdDSA", "RS256"} {
+		t.Run(alg, func(t *testing.T) {
+			key, _, err := GenerateSigningKey("key-1", alg)
+			assert.NoError(t, err)
+			signing, err := NewKeySet(key)
+			assert.NoError(t, err)
+
+			verifying, err := signing.JWKS().KeySet()
+			assert.NoError(t, err)
+
+			token, err := signing.Sign(testClaim())
+			assert.NoError(t, err)
+			_, err = parse(verifying, token)
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err := JsonWebKeySet{Keys: []JsonWebKey{{Kty: "EC", Kid: "p256"}}}.KeySet()
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestOIDCClient(t *testing.T) {
+	stub, err := NewStubOIDCServer("tennis", "secret")
+	assert.NoError(t, err)
+	defer stub.Close()
+	ctx := context.Background()
+
+	client, err := NewOIDCClient(ctx, stub.Config(), nil)
+	assert.NoError(t, err)
+
+	stub.LoginAs(OIDCIdentity{Subject: "42", Email: "tim@test.de", EmailVerified: true, PreferredUsername: "tim"})
+
+	t.Run("code is exchanged for the identity", func(t *testing.T) {
+		code := stubLogin(t, client, "state", "nonce")
+		identity, err := client.Exchange(ctx, code, "nonce", stubRedirectUrl)
+		assert.NoError(t, err)
+		assert.Equal(t, OIDCIdentity{
+			Issuer:            stub.URL,
+			Subject:           "42",
+			Email:             "tim@test.de",
+			EmailVerified:     true,
+			PreferredUsername: "tim",
+		}, identity)
+	})
+
+	t.Run("codes work once", func(t *testing.T) {
+		code := stubLogin(t, client, "state", "nonce")
+		_, err := client.Exchange(ctx, code, "nonce", stubRedirectUrl)
+		assert.NoError(t, err)
+		_, err = client.Exchange(ctx, code, "nonce", stubRedirectUrl)
+		assert.Error(t, err)
+	})
+
+	t.Run("id tokens of another login are rejected", func(t *testing.T) {
+		code := stubLogin(t, client, "state", "nonce")
+		_, err := client.Exchange(ctx, code, "other nonce", stubRedirectUrl)
+		assert.ErrorIs(t, err, ErrOIDCNonceMismatch)
+	})
+
+	t.Run("wrong client secret is rejected", func(t *testing.T) {
+		cfg := stub.Config()
+		cfg.ClientSecret = "wrong"
+		other, err := NewOIDCClient(ctx, cfg, nil)
+		assert.NoError(t, err)
+		code := stubLogin(t, other, "state", "nonce")
+		_, err = other.Exchange(ctx, code, "nonce", stubRedirectUrl)
+		assert.Error(t, err)
+	})
+
+	t.Run("id tokens for other clients are rejected", func(t *testing.T) {
+		cfg := stub.Config()
+		cfg.ClientId = "someone-else"
+		other, err := NewOIDCClient(ctx, cfg, nil)
+		assert.NoError(t, err)
+		_, err = other.verifyIdToken(ctx, signStubToken(t, stub, "nonce"), "nonce")
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("issuer has to match the discovery document", func(t *testing.T) {
+		cfg := stub.Config()
+		cfg.Issuer = stub.URL + "/elsewhere"
+		_, err := NewOIDCClient(ctx, cfg, nil)
+		assert.Error(t, err)
+	})
+}
+
+func signStubToken(t *testing.T, stub *StubOIDCServer, nonce string) string {
+	t.Helper()
+	claim := testClaim()
+	token, err := stub.keys.Sign(oidcClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    stub.URL,
+			Subject:   "42",
+			Audience:  jwt.ClaimStrings{stub.ClientId},
+			ExpiresAt: claim.ExpiresAt,
+		},
+		Nonce: nonce,
+	})
+	assert.NoError(t, err)
+	return token
+}
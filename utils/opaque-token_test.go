@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpaqueToken(t *testing.T) {
+	first, err := GenerateOpaqueToken()
+	assert.NoError(t, err)
+	second, err := GenerateOpaqueToken()
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Regexp(t, "^[A-Za-z0-9_-]{43}$", first)
+
+	assert.Equal(t, HashToken(first), HashToken(first))
+	assert.NotEqual(t, HashToken(first), HashToken(second))
+	assert.NotEqual(t, first, HashToken(first))
+}
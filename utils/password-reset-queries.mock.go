@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreatePasswordReset(ctx context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+	if contains(d.passwordResets, func(r db.PasswordReset) bool { return r.TokenHash == arg.TokenHash }) {
+		return db.PasswordReset{}, uniqueViolation("password_resets_token_hash_key")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.PasswordReset{}, foreignKeyViolation("password_resets", "FK_Password_resets.user_id")
+	}
+	reset := db.PasswordReset{
+		ID:         uuid.New(),
+		UserID:     arg.UserID,
+		TokenHash:  arg.TokenHash,
+		ExpiryDate: arg.ExpiryDate,
+		CreatedAt:  dbNow(),
+	}
+
+	d.passwordResets = append(d.passwordResets, reset)
+
+	return reset, nil
+}
+
+func (d *DBQueriesMock) GetPasswordResetByHash(ctx context.Context, tokenHash string) (db.PasswordReset, error) {
+	idx := slices.IndexFunc(d.passwordResets, func(r db.PasswordReset) bool { return r.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.PasswordReset{}, sql.ErrNoRows
+	}
+	return d.passwordResets[idx], nil
+}
+
+func (d *DBQueriesMock) UsePasswordReset(ctx context.Context, id uuid.UUID) (db.PasswordReset, error) {
+	idx := slices.IndexFunc(d.passwordResets, func(r db.PasswordReset) bool { return r.ID == id && !r.UsedAt.Valid })
+	if idx == -1 {
+		return db.PasswordReset{}, sql.ErrNoRows
+	}
+	d.passwordResets[idx].UsedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	return d.passwordResets[idx], nil
+}
+
+func (d *DBQueriesMock) DeletePasswordResetsByUserId(ctx context.Context, userId uuid.UUID) error {
+	var tempResets []db.PasswordReset
+	for _, reset := range d.passwordResets {
+		if reset.UserID != userId {
+			tempResets = append(tempResets, reset)
+		}
+	}
+	d.passwordResets = tempResets
+	return nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreatePersonalAccessToken(ctx context.Context, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	if contains(d.personalTokens, func(t db.PersonalAccessToken) bool { return t.TokenHash == arg.TokenHash }) {
+		return db.PersonalAccessToken{}, uniqueViolation("personal_access_tokens_token_hash_key")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.PersonalAccessToken{}, foreignKeyViolation("personal_access_tokens", "FK_Personal_access_tokens.user_id")
+	}
+	token := db.PersonalAccessToken{
+		ID:         uuid.New(),
+		UserID:     arg.UserID,
+		Name:       arg.Name,
+		TokenHash:  arg.TokenHash,
+		Scopes:     arg.Scopes,
+		ExpiryDate: arg.ExpiryDate,
+		CreatedAt:  dbNow(),
+	}
+	d.personalTokens = append(d.personalTokens, token)
+	return token, nil
+}
+
+func (d *DBQueriesMock) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (db.PersonalAccessToken, error) {
+	idx := slices.IndexFunc(d.personalTokens, func(t db.PersonalAccessToken) bool { return t.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.PersonalAccessToken{}, sql.ErrNoRows
+	}
+	return d.personalTokens[idx], nil
+}
+
+func (d *DBQueriesMock) GetPersonalAccessTokensByUserId(ctx context.Context, userId uuid.UUID) ([]db.PersonalAccessToken, error) {
+	var tokens []db.PersonalAccessToken
+	for i := len(d.personalTokens) - 1; i >= 0; i-- {
+		if d.personalTokens[i].UserID == userId {
+			tokens = append(tokens, d.personalTokens[i])
+		}
+	}
+	return tokens, nil
+}
+
+func (d *DBQueriesMock) TouchPersonalAccessToken(ctx context.Context, id uuid.UUID) error {
+	idx := slices.IndexFunc(d.personalTokens, func(t db.PersonalAccessToken) bool { return t.ID == id })
+	if idx != -1 {
+		d.personalTokens[idx].LastUsedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	}
+	return nil
+}
+
+func (d *DBQueriesMock) DeletePersonalAccessToken(ctx context.Context, arg db.DeletePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	idx := slices.IndexFunc(d.personalTokens, func(t db.PersonalAccessToken) bool {
+		return t.ID == arg.ID && t.UserID == arg.UserID
+	})
+	if idx == -1 {
+		return db.PersonalAccessToken{}, sql.ErrNoRows
+	}
+	token := d.personalTokens[idx]
+	d.personalTokens = slices.Delete(d.personalTokens, idx, idx+1)
+	return token, nil
+}
+
+func (d *DBQueriesMock) DeletePersonalAccessTokensByUserId(ctx context.Context, userId uuid.UUID) error {
+	d.personalTokens = slices.DeleteFunc(d.personalTokens, func(t db.PersonalAccessToken) bool { return t.UserID == userId })
+	return nil
+}
@@ -2,19 +2,148 @@ package utils
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
 func (d *DBQueriesMock) GetPlayerById(ctx context.Context, id uuid.UUID) (db.Player, error) {
-	return db.Player{}, nil
+	idx := slices.IndexFunc(d.players, func(p db.Player) bool { return p.ID == id })
+	if idx == -1 {
+		return db.Player{}, sql.ErrNoRows
+	}
+	return d.players[idx], nil
 }
 
 func (d *DBQueriesMock) DeletePlayerById(ctx context.Context, id uuid.UUID) (db.Player, error) {
-	return db.Player{}, nil
+	player, err := d.GetPlayerById(ctx, id)
+	if err != nil {
+		return db.Player{}, err
+	}
+	d.deletePlayer(id)
+	return player, nil
 }
 
 func (d *DBQueriesMock) UpdatePlayerById(ctx context.Context, arg db.UpdatePlayerByIdParams) (db.Player, error) {
-	return db.Player{}, nil
+	idx := slices.IndexFunc(d.players, func(p db.Player) bool { return p.ID == arg.ID })
+	if idx == -1 {
+		return db.Player{}, sql.ErrNoRows
+	}
+	if d.hasPlayerNamed(arg.FirstName, arg.LastName, arg.ID) {
+		return db.Player{}, uniqueViolation("unique_firstlast_name")
+	}
+	d.players[idx].FirstName = arg.FirstName
+	d.players[idx].LastName = arg.LastName
+	d.players[idx].UpdatedAt = dbNow()
+	return d.players[idx], nil
+}
+
+func (d *DBQueriesMock) GetClubIdByPlayerId(ctx context.Context, playerId uuid.UUID) (uuid.UUID, error) {
+	idx := slices.IndexFunc(d.teams, func(t db.Team) bool {
+		return t.PlayerOne == playerId || (t.PlayerTwo != nil && *t.PlayerTwo == playerId)
+	})
+	if idx == -1 {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return d.teams[idx].ClubID, nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByFirstName(ctx context.Context, arg db.ListPlayersByClubIdOrderByFirstNameParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(playersByCreatedAt(db.ListPlayersByClubIdOrderByLastNameParams(arg)), arg.CursorName, "first_name", false), nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByFirstNameDesc(ctx context.Context, arg db.ListPlayersByClubIdOrderByFirstNameDescParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(playersByCreatedAt(db.ListPlayersByClubIdOrderByLastNameParams(arg)), arg.CursorName, "first_name", true), nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByLastName(ctx context.Context, arg db.ListPlayersByClubIdOrderByLastNameParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(playersByCreatedAt(arg), arg.CursorName, "last_name", false), nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByLastNameDesc(ctx context.Context, arg db.ListPlayersByClubIdOrderByLastNameDescParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(playersByCreatedAt(db.ListPlayersByClubIdOrderByLastNameParams(arg)), arg.CursorName, "last_name", true), nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByCreatedAt(ctx context.Context, arg db.ListPlayersByClubIdOrderByCreatedAtParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(arg, "", "created_at", false), nil
+}
+
+func (d *DBQueriesMock) ListPlayersByClubIdOrderByCreatedAtDesc(ctx context.Context, arg db.ListPlayersByClubIdOrderByCreatedAtDescParams) ([]db.PlayerSummary, error) {
+	return d.listPlayers(db.ListPlayersByClubIdOrderByCreatedAtParams(arg), "", "created_at", true), nil
+}
+
+// playersByCreatedAt returns the filters of arg as the params of the
+// created_at query, the cursor name is passed on its own.
+func playersByCreatedAt(arg db.ListPlayersByClubIdOrderByLastNameParams) db.ListPlayersByClubIdOrderByCreatedAtParams {
+	return db.ListPlayersByClubIdOrderByCreatedAtParams{
+		ClubID:        arg.ClubID,
+		NamePrefix:    arg.NamePrefix,
+		CreatedAfter:  arg.CreatedAfter,
+		CreatedBefore: arg.CreatedBefore,
+		CursorID:      arg.CursorID,
+		PageSize:      arg.PageSize,
+	}
+}
+
+// listPlayers lists the players of the club with the teams and matches they
+// played in, of any club like the player_summaries view does. The list
+// queries only differ in the column they sort by and its direction.
+func (d *DBQueriesMock) listPlayers(arg db.ListPlayersByClubIdOrderByCreatedAtParams, cursorName string, sortBy string, descending bool) []db.PlayerSummary {
+	rows := []db.PlayerSummary{}
+	for _, player := range d.players {
+		named := ilikePrefix(player.FirstName+" "+player.LastName, arg.NamePrefix) || ilikePrefix(player.LastName, arg.NamePrefix)
+		if player.ClubID != arg.ClubID || !named || !inTimeRange(player.CreatedAt, arg.CreatedAfter, arg.CreatedBefore) {
+			continue
+		}
+		rows = append(rows, d.playerStats(player))
+	}
+
+	return keysetPage(rows, func(p db.PlayerSummary) (string, time.Time, uuid.UUID) {
+		if sortBy == "first_name" {
+			return p.FirstName, p.CreatedAt, p.ID
+		}
+		return p.LastName, p.CreatedAt, p.ID
+	}, sortBy, descending, arg.CursorID, cursorName, arg.CursorCreatedAt, arg.PageSize)
+}
+
+func (d *DBQueriesMock) playerStats(player db.Player) db.PlayerSummary {
+	row := db.PlayerSummary{
+		ID:        player.ID,
+		FirstName: player.FirstName,
+		LastName:  player.LastName,
+		CreatedAt: player.CreatedAt,
+		UpdatedAt: player.UpdatedAt,
+		ClubID:    player.ClubID,
+	}
+	var teams []uuid.UUID
+	for _, team := range d.teams {
+		if team.PlayerOne != player.ID && (team.PlayerTwo == nil || *team.PlayerTwo != player.ID) {
+			continue
+		}
+		teams = append(teams, team.ID)
+		if team.PlayerTwo != nil {
+			row.TeamCount++
+		}
+	}
+	for _, match := range d.matches {
+		if !slices.Contains(teams, match.TeamOne) && !slices.Contains(teams, match.TeamTwo) {
+			continue
+		}
+		row.MatchCount++
+		if !row.LastPlayedAt.Valid || match.CreatedAt.After(row.LastPlayedAt.Time) {
+			row.LastPlayedAt = sql.NullTime{Time: match.CreatedAt, Valid: true}
+		}
+	}
+	return row
+}
+
+// hasPlayerNamed checks the unique first and last name of players, except for
+// the player that is being changed.
+func (d *DBQueriesMock) hasPlayerNamed(firstName string, lastName string, except uuid.UUID) bool {
+	return contains(d.players, func(p db.Player) bool {
+		return p.ID != except && p.FirstName == firstName && p.LastName == lastName
+	})
 }
@@ -0,0 +1,326 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+)
+
+// SeedConfig says how much demo data Seed creates. Players, DoublesTeams and
+// Matches are per user, every user gets a club of their own. The same Seed
+// creates the same names, teams and scores every time.
+type SeedConfig struct {
+	Seed         int64
+	Users        int
+	Players      int
+	DoublesTeams int
+	Matches      int
+	Sets         int
+	Password     string
+}
+
+// SeedUserCreator registers an account with its personal club on q. How
+// accounts are set up is up to the handlers, which build on this package, so
+// the caller of Seed passes theirs in.
+type SeedUserCreator func(ctx context.Context, q db.Querier, username string, email string, password string) (db.User, error)
+
+type SeedResult struct {
+	Users   int
+	Players int
+	Teams   int
+	Matches int
+	Points  int
+}
+
+var (
+	seedFirstNames = []string{
+		"Anna", "Ben", "Clara", "David", "Emma", "Felix", "Greta", "Hannah", "Ida", "Jonas",
+		"Karl", "Lea", "Lukas", "Marie", "Moritz", "Nina", "Otto", "Paul", "Paula", "Quentin",
+		"Rosa", "Simon", "Sophie", "Theo", "Ute", "Valentin", "Wanda", "Xaver", "Yara", "Zoe",
+		"Alexander", "Charlotte", "Elias", "Frieda", "Henry", "Johanna", "Leon", "Mia", "Noah", "Lina",
+	}
+	seedLastNames = []string{
+		"Becker", "Braun", "Fischer", "Frank", "Hartmann", "Hoffmann", "Keller", "Klein", "Koch", "Krause",
+		"Maier", "Lange", "Lehmann", "Meyer", "Müller", "Neumann", "Seidel", "Richter", "Schäfer", "Schmid",
+		"Schmidt", "Schneider", "Schulz", "Schwarz", "Wagner", "Walter", "Weber", "Werner", "Wolf", "Zimmermann",
+		"Berger", "Fuchs", "Günther", "Huber", "Jung", "Kaiser", "Lorenz", "Peters", "Roth", "Vogel",
+	}
+)
+
+// Seed fills q with demo accounts, players, singles and doubles teams and
+// matches scored point by point. The names of the accounts and the last names
+// of the players contain the seed, so player names stay unique over all clubs
+// like the schema asks, also next to data seeded with another seed. Run it in
+// a transaction to keep nothing of a failed run.
+func Seed(ctx context.Context, q db.Querier, cfg SeedConfig, createUser SeedUserCreator) (SeedResult, error) {
+	if cfg.Users < 1 {
+		return SeedResult{}, errors.New("seed at least one user")
+	}
+	if cfg.Matches > 0 && cfg.Players < 2 {
+		return SeedResult{}, errors.New("matches need at least two players per user")
+	}
+	if cfg.Sets < 1 || cfg.Sets%2 == 0 {
+		return SeedResult{}, errors.New("matches are played over an odd number of sets")
+	}
+	if cfg.DoublesTeams > cfg.Players*(cfg.Players-1)/2 {
+		return SeedResult{}, fmt.Errorf("%d players make at most %d doubles teams", cfg.Players, cfg.Players*(cfg.Players-1)/2)
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	names := rng.Perm(len(seedFirstNames) * len(seedLastNames))
+	var result SeedResult
+
+	for u := 0; u < cfg.Users; u++ {
+		username := fmt.Sprintf("demo-%d-%d", cfg.Seed, u+1)
+		user, err := createUser(ctx, q, username, username+"@example.com", cfg.Password)
+		if err != nil {
+			return result, err
+		}
+		if _, err := q.VerifyUserEmailById(ctx, user.ID); err != nil {
+			return result, err
+		}
+		// the personal club shares the id of its owner
+		clubID := user.ID
+		result.Users++
+
+		singles := make([]seedTeam, cfg.Players)
+		for p := range singles {
+			first, last := seedPlayerName(names, cfg.Seed, result.Players)
+			team, err := q.CreateNewTeamWithOnePlayer(ctx, db.CreateNewTeamWithOnePlayerParams{
+				FirstName: first,
+				LastName:  last,
+				Name:      first + " " + last,
+				ClubID:    clubID,
+			})
+			if err != nil {
+				return result, err
+			}
+			singles[p] = seedTeam{Team: team, Skill: 0.35 + rng.Float64()*0.3, LastName: last}
+			result.Players++
+			result.Teams++
+		}
+
+		doubles := make([]seedTeam, 0, cfg.DoublesTeams)
+		paired := map[[2]int]bool{}
+		for len(doubles) < cfg.DoublesTeams {
+			one, two := rng.Intn(cfg.Players), rng.Intn(cfg.Players)
+			if one == two || paired[[2]int{one, two}] || paired[[2]int{two, one}] {
+				continue
+			}
+			paired[[2]int{one, two}] = true
+
+			team, err := q.CreateTeamWithTwoPlayers(ctx, db.CreateTeamWithTwoPlayersParams{
+				Name:      singles[one].LastName + " / " + singles[two].LastName,
+				PlayerOne: singles[one].PlayerOne,
+				PlayerTwo: &singles[two].PlayerOne,
+				ClubID:    clubID,
+			})
+			if err != nil {
+				return result, err
+			}
+			doubles = append(doubles, seedTeam{Team: team, Skill: (singles[one].Skill + singles[two].Skill) / 2})
+			result.Teams++
+		}
+
+		for m := 0; m < cfg.Matches; m++ {
+			teams := singles
+			if len(doubles) >= 2 && rng.Float64() < 0.3 {
+				teams = doubles
+			}
+			one := rng.Intn(len(teams))
+			two := rng.Intn(len(teams) - 1)
+			if two >= one {
+				two++
+			}
+
+			sets := simulateMatch(rng, cfg.Sets, teams[one].Skill, teams[two].Skill)
+			points, err := seedMatch(ctx, q, clubID, cfg.Sets, teams[one], teams[two], sets)
+			if err != nil {
+				return result, err
+			}
+			result.Matches++
+			result.Points += points
+		}
+	}
+
+	if _, err := q.RecomputeStats(ctx); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// seedPlayerName is the n-th name of the shuffled combinations of first and
+// last names. The last name gets the seed and, once the combinations are used
+// up, the round.
+func seedPlayerName(names []int, seed int64, n int) (string, string) {
+	name := names[n%len(names)]
+	first := seedFirstNames[name%len(seedFirstNames)]
+	last := fmt.Sprintf("%s %d", seedLastNames[name/len(seedFirstNames)], seed)
+	if round := n / len(names); round > 0 {
+		last = fmt.Sprintf("%s-%d", last, round+1)
+	}
+	return first, last
+}
+
+type seedTeam struct {
+	db.Team
+	Skill    float64
+	LastName string
+}
+
+// seedMatch stores a simulated match with its sets, games, points and the
+// stats recorded while it was played. It returns the number of points.
+func seedMatch(ctx context.Context, q db.Querier, clubID uuid.UUID, numberOfSets int, one seedTeam, two seedTeam, sets []simulatedSet) (int, error) {
+	match, err := q.CreateMatch(ctx, db.CreateMatchParams{
+		ClubID:       clubID,
+		TeamOne:      one.ID,
+		TeamTwo:      two.ID,
+		NumberOfSets: sql.NullInt32{Int32: int32(numberOfSets), Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	teamIDs := [2]uuid.UUID{one.ID, two.ID}
+	var points int
+	for _, simSet := range sets {
+		set, err := q.CreateSet(ctx, match.ID)
+		if err != nil {
+			return points, err
+		}
+		for _, simGame := range simSet.Games {
+			game, err := q.CreateGame(ctx, db.CreateGameParams{SetID: &set.ID, ServerID: teamIDs[simGame.Server]})
+			if err != nil {
+				return points, err
+			}
+
+			var score [2]int32
+			for order, winner := range simGame.Points {
+				score[winner]++
+				_, err := q.CreatePoint(ctx, db.CreatePointParams{
+					GameID:      &game.ID,
+					TeamID:      teamIDs[winner],
+					Value:       sql.NullInt32{Int32: score[winner], Valid: true},
+					PointsOrder: sql.NullInt32{Int32: int32(order + 1), Valid: true},
+				})
+				if err != nil {
+					return points, err
+				}
+				points++
+			}
+
+			for team, teamID := range teamIDs {
+				teamID := teamID
+				_, err := q.CreateStat(ctx, db.CreateStatParams{
+					GameID:       &game.ID,
+					TeamID:       &teamID,
+					Aces:         sql.NullInt32{Int32: simGame.Aces[team], Valid: true},
+					DoubleFaults: sql.NullInt32{Int32: simGame.DoubleFaults[team], Valid: true},
+					NetPoints:    sql.NullInt32{Int32: simGame.NetPoints[team], Valid: true},
+				})
+				if err != nil {
+					return points, err
+				}
+			}
+		}
+	}
+	return points, nil
+}
+
+// simulatedGame is a game of a simulated match. Server and the entries of
+// Points are 0 for the first team and 1 for the second, the stats are indexed
+// the same way.
+type simulatedGame struct {
+	Server       int
+	TieBreak     bool
+	Points       []int
+	Aces         [2]int32
+	DoubleFaults [2]int32
+	NetPoints    [2]int32
+}
+
+type simulatedSet struct {
+	Games  []simulatedGame
+	Winner int
+}
+
+// simulateMatch plays a match over at most sets sets point by point. Whoever
+// serves wins most of their points, the stronger team wins more of both kinds.
+// Games go to deuce, sets to a tie-break at 6-6.
+func simulateMatch(rng *rand.Rand, sets int, skillOne float64, skillTwo float64) []simulatedSet {
+	skill := [2]float64{skillOne, skillTwo}
+	server := rng.Intn(2)
+	var played []simulatedSet
+	var setsWon [2]int
+
+	for setsWon[0] <= sets/2 && setsWon[1] <= sets/2 {
+		var set simulatedSet
+		var games [2]int
+		for {
+			tieBreak := games[0] == 6 && games[1] == 6
+			game := simulateGame(rng, server, tieBreak, skill)
+			set.Games = append(set.Games, game)
+			games[game.Points[len(game.Points)-1]]++
+			server = 1 - server
+
+			if tieBreak || (games[0] >= 6 || games[1] >= 6) && abs(games[0]-games[1]) >= 2 {
+				break
+			}
+		}
+		if games[0] > games[1] {
+			set.Winner = 0
+		} else {
+			set.Winner = 1
+		}
+		setsWon[set.Winner]++
+		played = append(played, set)
+	}
+	return played
+}
+
+func simulateGame(rng *rand.Rand, server int, tieBreak bool, skill [2]float64) simulatedGame {
+	game := simulatedGame{Server: server, TieBreak: tieBreak}
+	target := 4
+	if tieBreak {
+		target = 7
+	}
+
+	var score [2]int
+	for (score[0] < target && score[1] < target) || abs(score[0]-score[1]) < 2 {
+		pointServer := server
+		if tieBreak && (len(game.Points)+1)/2%2 == 1 {
+			pointServer = 1 - server
+		}
+		receiver := 1 - pointServer
+
+		winner := receiver
+		serveWin := 0.62 + (skill[pointServer]-skill[receiver])*0.5
+		if rng.Float64() < serveWin {
+			winner = pointServer
+			if rng.Float64() < 0.1 {
+				game.Aces[pointServer]++
+			}
+		} else if rng.Float64() < 0.12 {
+			game.DoubleFaults[pointServer]++
+		}
+		if rng.Float64() < 0.15 {
+			game.NetPoints[winner]++
+		}
+
+		score[winner]++
+		game.Points = append(game.Points, winner)
+	}
+	return game
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
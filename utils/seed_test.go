@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"golang.org/x/exp/slices"
+)
+
+func TestSimulateMatch(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		sets := 3
+		if i%2 == 1 {
+			sets = 5
+		}
+		played := simulateMatch(rng, sets, 0.35+rng.Float64()*0.3, 0.35+rng.Float64()*0.3)
+
+		var setsWon [2]int
+		for _, set := range played {
+			var games [2]int
+			for _, game := range set.Games {
+				var points [2]int
+				for _, winner := range game.Points {
+					points[winner]++
+				}
+				winner := game.Points[len(game.Points)-1]
+				target := 4
+				if game.TieBreak {
+					target = 7
+				}
+				if points[winner] < target || points[winner]-points[1-winner] < 2 {
+					t.Fatalf("a game ended %d:%d", points[winner], points[1-winner])
+				}
+				games[winner]++
+			}
+
+			winner := set.Winner
+			sixToFour := games[winner] == 6 && games[1-winner] <= 4
+			closeSet := games[winner] == 7 && (games[1-winner] == 5 || games[1-winner] == 6 && set.Games[len(set.Games)-1].TieBreak)
+			if !sixToFour && !closeSet {
+				t.Fatalf("a set ended %d:%d", games[winner], games[1-winner])
+			}
+			setsWon[winner]++
+		}
+
+		winner := played[len(played)-1].Winner
+		if setsWon[winner] != sets/2+1 || setsWon[1-winner] > sets/2 {
+			t.Fatalf("a match over %d sets ended %d:%d", sets, setsWon[winner], setsWon[1-winner])
+		}
+	}
+}
+
+// createSeedUser sets an account up like the user handler does, which the
+// tests of this package can't import.
+func createSeedUser(ctx context.Context, q db.Querier, username string, email string, password string) (db.User, error) {
+	user, err := q.CreateUser(ctx, db.CreateUserParams{Username: username, Email: email, PasswordHash: password})
+	if err != nil {
+		return db.User{}, err
+	}
+	if _, err := q.CreateClub(ctx, db.CreateClubParams{ID: user.ID, Name: username}); err != nil {
+		return db.User{}, err
+	}
+	_, err = q.UpsertClubMember(ctx, db.UpsertClubMemberParams{ClubID: user.ID, UserID: user.ID, Role: "owner"})
+	return user, err
+}
+
+func TestSeed(t *testing.T) {
+	cfg := SeedConfig{Seed: 7, Users: 2, Players: 6, DoublesTeams: 3, Matches: 4, Sets: 3, Password: "Demo1234"}
+
+	seed := func(t *testing.T, cfg SeedConfig) (*DBQueriesMock, SeedResult) {
+		d := NewDBQueriesMock()
+		var result SeedResult
+		err := d.InTx(context.Background(), func(q db.Querier) error {
+			var err error
+			result, err = Seed(context.Background(), q, cfg, createSeedUser)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Seed() = %v, want nil", err)
+		}
+		return d, result
+	}
+
+	d, result := seed(t, cfg)
+	want := SeedResult{Users: 2, Players: 12, Teams: 18, Matches: 8, Points: len(d.points)}
+	if result != want || len(d.users) != 2 || len(d.players) != 12 || len(d.teams) != 18 || len(d.matches) != 8 {
+		t.Fatalf("Seed() = %+v, want %+v", result, want)
+	}
+	if len(d.stats) != 2*len(d.games) {
+		t.Fatalf("Seed() recorded %d stats for %d games, want two per game", len(d.stats), len(d.games))
+	}
+	if !slices.ContainsFunc(d.stats, func(s db.Stat) bool { return s.Deuce.Int32 > 0 }) {
+		t.Fatalf("Seed() didn't recompute the deuces")
+	}
+
+	t.Run("same seed", func(t *testing.T) {
+		again, _ := seed(t, cfg)
+		if !reflect.DeepEqual(seedFingerprint(d), seedFingerprint(again)) {
+			t.Fatalf("Seed() with the same seed created different data")
+		}
+	})
+
+	t.Run("other seed", func(t *testing.T) {
+		cfg := cfg
+		cfg.Seed = 8
+		other, _ := seed(t, cfg)
+		if reflect.DeepEqual(seedFingerprint(d), seedFingerprint(other)) {
+			t.Fatalf("Seed() with another seed created the same data")
+		}
+
+		names := []int{0}
+		first, last := seedPlayerName(names, 7, 0)
+		otherFirst, otherLast := seedPlayerName(names, 8, 0)
+		if first+" "+last == otherFirst+" "+otherLast {
+			t.Fatalf("seedPlayerName() = %s %s for seed 7 and 8, want names that don't clash", first, last)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		cfg := cfg
+		cfg.Sets = 2
+		if _, err := Seed(context.Background(), NewDBQueriesMock(), cfg, createSeedUser); err == nil {
+			t.Fatalf("Seed() over 2 sets = nil, want error")
+		}
+	})
+}
+
+// seedFingerprint is the seeded data without ids and times: the player names
+// and the team names of the winners of every point, in order.
+func seedFingerprint(d *DBQueriesMock) []string {
+	var fingerprint []string
+	for _, player := range d.players {
+		fingerprint = append(fingerprint, player.FirstName+" "+player.LastName)
+	}
+	for _, point := range d.points {
+		team, _ := d.GetTeamById(context.Background(), point.TeamID)
+		fingerprint = append(fingerprint, team.Name)
+	}
+	return fingerprint
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreateStat(ctx context.Context, arg db.CreateStatParams) (db.Stat, error) {
+	if arg.GameID != nil && !d.hasGame(*arg.GameID) {
+		return db.Stat{}, foreignKeyViolation("stats", "FK_Stats.game_id")
+	}
+	if arg.TeamID != nil && !d.hasTeam(*arg.TeamID) {
+		return db.Stat{}, foreignKeyViolation("stats", "FK_Stats.team_id")
+	}
+
+	stat := db.Stat{
+		ID:             uuid.New(),
+		Aces:           arg.Aces,
+		DoubleFaults:   arg.DoubleFaults,
+		NetPoints:      arg.NetPoints,
+		Deuce:          sql.NullInt32{Valid: true},
+		PointsWonDeuce: sql.NullInt32{Valid: true},
+		GameID:         arg.GameID,
+		TeamID:         arg.TeamID,
+	}
+	d.stats = append(d.stats, stat)
+	return stat, nil
+}
+
+// RecomputeStats counts the deuces of every game of a match and the points
+// each team won from deuce or advantage, like the query does.
+func (d *DBQueriesMock) RecomputeStats(ctx context.Context) (db.RecomputeStatsRow, error) {
+	var result db.RecomputeStatsRow
+	for _, game := range d.games {
+		match, ok := d.matchOfGame(game)
+		if !ok {
+			continue
+		}
+
+		var points []db.Point
+		for _, point := range d.points {
+			if point.GameID != nil && *point.GameID == game.ID {
+				points = append(points, point)
+			}
+		}
+		slices.SortFunc(points, comparePointOrder)
+
+		var deuce int32
+		won := map[uuid.UUID]int32{}
+		score := map[uuid.UUID]int32{}
+		for _, point := range points {
+			if score[match.TeamOne] >= 3 && score[match.TeamTwo] >= 3 {
+				won[point.TeamID]++
+			}
+			score[point.TeamID]++
+			if score[match.TeamOne] == score[match.TeamTwo] && score[match.TeamOne] >= 3 {
+				deuce++
+			}
+		}
+
+		for _, team := range []uuid.UUID{match.TeamOne, match.TeamTwo} {
+			updated := false
+			for idx, stat := range d.stats {
+				if stat.GameID != nil && *stat.GameID == game.ID && stat.TeamID != nil && *stat.TeamID == team {
+					d.stats[idx].Deuce = sql.NullInt32{Int32: deuce, Valid: true}
+					d.stats[idx].PointsWonDeuce = sql.NullInt32{Int32: won[team], Valid: true}
+					updated = true
+					result.Updated++
+				}
+			}
+			if updated {
+				continue
+			}
+
+			gameID, teamID := game.ID, team
+			d.stats = append(d.stats, db.Stat{
+				ID:             uuid.New(),
+				Aces:           sql.NullInt32{Valid: true},
+				DoubleFaults:   sql.NullInt32{Valid: true},
+				NetPoints:      sql.NullInt32{Valid: true},
+				Deuce:          sql.NullInt32{Int32: deuce, Valid: true},
+				PointsWonDeuce: sql.NullInt32{Int32: won[team], Valid: true},
+				GameID:         &gameID,
+				TeamID:         &teamID,
+			})
+			result.Inserted++
+		}
+	}
+	return result, nil
+}
+
+func (d *DBQueriesMock) matchOfGame(game db.Game) (db.Match, bool) {
+	if game.SetID == nil {
+		return db.Match{}, false
+	}
+	setIdx := slices.IndexFunc(d.sets, func(s db.Set) bool { return s.ID == *game.SetID })
+	if setIdx == -1 {
+		return db.Match{}, false
+	}
+	matchIdx := slices.IndexFunc(d.matches, func(m db.Match) bool { return m.ID == d.sets[setIdx].MatchID })
+	if matchIdx == -1 {
+		return db.Match{}, false
+	}
+	return d.matches[matchIdx], true
+}
+
+// comparePointOrder orders the points of a game by points_order with the
+// unordered ones last, then by when they were recorded.
+func comparePointOrder(a db.Point, b db.Point) int {
+	if a.PointsOrder.Valid != b.PointsOrder.Valid {
+		if a.PointsOrder.Valid {
+			return -1
+		}
+		return 1
+	}
+	if a.PointsOrder.Int32 != b.PointsOrder.Int32 {
+		if a.PointsOrder.Int32 < b.PointsOrder.Int32 {
+			return -1
+		}
+		return 1
+	}
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return slices.Compare(a.ID[:], b.ID[:])
+}
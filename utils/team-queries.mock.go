@@ -2,31 +2,173 @@ package utils
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
 func (d *DBQueriesMock) CreateNewTeamWithOnePlayer(ctx context.Context, args db.CreateNewTeamWithOnePlayerParams) (db.Team, error) {
-	return db.Team{}, nil
+	if d.hasPlayerNamed(args.FirstName, args.LastName, uuid.Nil) {
+		return db.Team{}, uniqueViolation("unique_firstlast_name")
+	}
+	if !d.hasClub(args.ClubID) {
+		return db.Team{}, foreignKeyViolation("teams", "FK_Teams.club_id")
+	}
+
+	player := db.Player{
+		ID:        uuid.New(),
+		FirstName: args.FirstName,
+		LastName:  args.LastName,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+		ClubID:    args.ClubID,
+	}
+	d.players = append(d.players, player)
+
+	team := db.Team{
+		ID:        uuid.New(),
+		Name:      args.Name,
+		PlayerOne: player.ID,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+		ClubID:    args.ClubID,
+	}
+	d.teams = append(d.teams, team)
+	return team, nil
 }
 
 func (d *DBQueriesMock) CreateTeamWithTwoPlayers(ctx context.Context, arg db.CreateTeamWithTwoPlayersParams) (db.Team, error) {
-	return db.Team{}, nil
+	team := db.Team{
+		ID:        uuid.New(),
+		Name:      arg.Name,
+		PlayerOne: arg.PlayerOne,
+		PlayerTwo: arg.PlayerTwo,
+		CreatedAt: dbNow(),
+		UpdatedAt: dbNow(),
+		ClubID:    arg.ClubID,
+	}
+	if err := d.checkTeam(team); err != nil {
+		return db.Team{}, err
+	}
+	d.teams = append(d.teams, team)
+	return team, nil
 }
 
 func (d *DBQueriesMock) GetTeamById(ctx context.Context, id uuid.UUID) (db.Team, error) {
-	return db.Team{}, nil
+	idx := slices.IndexFunc(d.teams, func(t db.Team) bool { return t.ID == id })
+	if idx == -1 {
+		return db.Team{}, sql.ErrNoRows
+	}
+	return d.teams[idx], nil
 }
 
 func (d *DBQueriesMock) UpdateTeamById(ctx context.Context, arg db.UpdateTeamByIdParams) (db.Team, error) {
-	return db.Team{}, nil
+	idx := slices.IndexFunc(d.teams, func(t db.Team) bool { return t.ID == arg.ID })
+	if idx == -1 {
+		return db.Team{}, sql.ErrNoRows
+	}
+
+	team := d.teams[idx]
+	team.PlayerOne = arg.PlayerOne
+	team.PlayerTwo = arg.PlayerTwo
+	team.Name = arg.Name
+	team.UpdatedAt = dbNow()
+	if err := d.checkTeam(team); err != nil {
+		return db.Team{}, err
+	}
+	d.teams[idx] = team
+	return team, nil
 }
 
 func (d *DBQueriesMock) DeleteTeamById(ctx context.Context, id uuid.UUID) (db.Team, error) {
-	return db.Team{}, nil
+	team, err := d.GetTeamById(ctx, id)
+	if err != nil {
+		return db.Team{}, err
+	}
+	d.deleteTeam(id)
+	return team, nil
+}
+
+func (d *DBQueriesMock) GetAllTeamsByClubId(ctx context.Context, clubId uuid.UUID) ([]db.Team, error) {
+	var teams []db.Team
+	for _, team := range d.teams {
+		if team.ClubID == clubId {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (d *DBQueriesMock) ListTeamsByClubIdOrderByName(ctx context.Context, arg db.ListTeamsByClubIdOrderByNameParams) ([]db.Team, error) {
+	return d.listTeams(teamsByCreatedAt(arg), arg.CursorName, "name", false), nil
+}
+
+func (d *DBQueriesMock) ListTeamsByClubIdOrderByNameDesc(ctx context.Context, arg db.ListTeamsByClubIdOrderByNameDescParams) ([]db.Team, error) {
+	return d.listTeams(teamsByCreatedAt(db.ListTeamsByClubIdOrderByNameParams(arg)), arg.CursorName, "name", true), nil
+}
+
+func (d *DBQueriesMock) ListTeamsByClubIdOrderByCreatedAt(ctx context.Context, arg db.ListTeamsByClubIdOrderByCreatedAtParams) ([]db.Team, error) {
+	return d.listTeams(arg, "", "created_at", false), nil
+}
+
+func (d *DBQueriesMock) ListTeamsByClubIdOrderByCreatedAtDesc(ctx context.Context, arg db.ListTeamsByClubIdOrderByCreatedAtDescParams) ([]db.Team, error) {
+	return d.listTeams(db.ListTeamsByClubIdOrderByCreatedAtParams(arg), "", "created_at", true), nil
+}
+
+// teamsByCreatedAt returns the filters of arg as the params of the created_at
+// query, the cursor name is passed on its own.
+func teamsByCreatedAt(arg db.ListTeamsByClubIdOrderByNameParams) db.ListTeamsByClubIdOrderByCreatedAtParams {
+	return db.ListTeamsByClubIdOrderByCreatedAtParams{
+		ClubID:        arg.ClubID,
+		NamePrefix:    arg.NamePrefix,
+		HasPlayerTwo:  arg.HasPlayerTwo,
+		CreatedAfter:  arg.CreatedAfter,
+		CreatedBefore: arg.CreatedBefore,
+		CursorID:      arg.CursorID,
+		PageSize:      arg.PageSize,
+	}
+}
+
+// listTeams filters and pages the teams of a club like the list queries, which
+// only differ in the column they sort by and its direction.
+func (d *DBQueriesMock) listTeams(arg db.ListTeamsByClubIdOrderByCreatedAtParams, cursorName string, sortBy string, descending bool) []db.Team {
+	var teams []db.Team
+	for _, team := range d.teams {
+		hasPlayerTwo := !arg.HasPlayerTwo.Valid || (team.PlayerTwo != nil) == arg.HasPlayerTwo.Bool
+		if team.ClubID != arg.ClubID || !ilikePrefix(team.Name, arg.NamePrefix) || !hasPlayerTwo ||
+			!inTimeRange(team.CreatedAt, arg.CreatedAfter, arg.CreatedBefore) {
+			continue
+		}
+		teams = append(teams, team)
+	}
+
+	return keysetPage(teams, func(t db.Team) (string, time.Time, uuid.UUID) {
+		return t.Name, t.CreatedAt, t.ID
+	}, sortBy, descending, arg.CursorID, cursorName, arg.CursorCreatedAt, arg.PageSize)
 }
 
-func (d *DBQueriesMock) GetAllTeamsByUserId(ctx context.Context, userId uuid.UUID) ([]db.Team, error){
-	return []db.Team{}, nil
+// checkTeam checks the constraints of the teams table for a new or changed
+// team.
+func (d *DBQueriesMock) checkTeam(team db.Team) error {
+	if team.PlayerTwo != nil && *team.PlayerTwo == team.PlayerOne {
+		return checkViolation("teams", "CK_Teams_DistinctPlayers")
+	}
+	if team.PlayerTwo != nil && contains(d.teams, func(t db.Team) bool {
+		return t.ID != team.ID && t.PlayerOne == team.PlayerOne && t.PlayerTwo != nil && *t.PlayerTwo == *team.PlayerTwo
+	}) {
+		return uniqueViolation("unique_players")
+	}
+	if !d.hasClub(team.ClubID) {
+		return foreignKeyViolation("teams", "FK_Teams.club_id")
+	}
+	if !d.hasPlayer(team.PlayerOne) {
+		return foreignKeyViolation("teams", "FK_Teams.player_one")
+	}
+	if team.PlayerTwo != nil && !d.hasPlayer(*team.PlayerTwo) {
+		return foreignKeyViolation("teams", "FK_Teams.player_two")
+	}
+	return nil
 }
@@ -2,23 +2,127 @@ package utils
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 )
 
-func (d *DBQueriesMock) CreateToken(ctx context.Context, arg db.CreateTokenParams) (db.User, error) {
-	return db.User{}, nil
+func (d *DBQueriesMock) CreateToken(ctx context.Context, arg db.CreateTokenParams) (db.RefreshToken, error) {
+	if contains(d.tokens, func(t db.RefreshToken) bool { return t.TokenHash == arg.TokenHash }) {
+		return db.RefreshToken{}, uniqueViolation("refresh_tokens_token_key")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.RefreshToken{}, foreignKeyViolation("refresh_tokens", "FK_Refresh_token.user_id")
+	}
+	newToken := db.RefreshToken{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		TokenHash:   arg.TokenHash,
+		ExpiryDate:  arg.ExpiryDate,
+		CreatedAt:   dbNow(),
+		UpdatedAt:   dbNow(),
+		DeviceLabel: arg.DeviceLabel,
+		LastUsedAt:  dbNow(),
+	}
+
+	d.tokens = append(d.tokens, newToken)
+
+	return newToken, nil
+}
+
+func (d *DBQueriesMock) GetTokenById(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	idx := slices.IndexFunc(d.tokens, func(t db.RefreshToken) bool { return t.ID == id })
+	if idx == -1 {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	return d.tokens[idx], nil
+}
+
+func (d *DBQueriesMock) GetAllTokensByUserId(ctx context.Context, userId uuid.UUID) ([]db.RefreshToken, error) {
+	var tokens []db.RefreshToken
+	for _, token := range d.tokens {
+		if token.UserID == userId {
+			tokens = append(tokens, token)
+		}
+	}
+	slices.SortStableFunc(tokens, func(a, b db.RefreshToken) int { return b.LastUsedAt.Compare(a.LastUsedAt) })
+	return tokens, nil
+}
+
+func (d *DBQueriesMock) GetTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	idx := slices.IndexFunc(d.tokens, func(t db.RefreshToken) bool { return t.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	return d.tokens[idx], nil
 }
 
-func (d *DBQueriesMock) UpdateTokenByUserId(ctx context.Context, arg db.UpdateTokenByUserIdParams) (db.RefreshToken, error) {
-	return db.RefreshToken{}, nil
+func (d *DBQueriesMock) GetRotatedTokenByHash(ctx context.Context, tokenHash string) (db.RotatedRefreshToken, error) {
+	idx := slices.IndexFunc(d.rotatedTokens, func(t db.RotatedRefreshToken) bool { return t.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.RotatedRefreshToken{}, sql.ErrNoRows
+	}
+	return d.rotatedTokens[idx], nil
 }
 
-func (d *DBQueriesMock) GetTokenByUserId(ctx context.Context, userId uuid.UUID) (db.RefreshToken, error) {
-	return db.RefreshToken{}, nil
+func (d *DBQueriesMock) RotateTokenById(ctx context.Context, arg db.RotateTokenByIdParams) (db.RefreshToken, error) {
+	idx := slices.IndexFunc(d.tokens, func(t db.RefreshToken) bool { return t.ID == arg.ID && t.TokenHash == arg.OldTokenHash })
+	if idx == -1 {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	if contains(d.tokens, func(t db.RefreshToken) bool { return t.TokenHash == arg.TokenHash }) {
+		return db.RefreshToken{}, uniqueViolation("refresh_tokens_token_key")
+	}
+	if contains(d.rotatedTokens, func(t db.RotatedRefreshToken) bool { return t.TokenHash == d.tokens[idx].TokenHash }) {
+		return db.RefreshToken{}, uniqueViolation("rotated_refresh_tokens_pkey")
+	}
+	d.rotatedTokens = append(d.rotatedTokens, db.RotatedRefreshToken{
+		TokenHash: d.tokens[idx].TokenHash,
+		SessionID: arg.ID,
+		RotatedAt: dbNow(),
+	})
+	d.tokens[idx].TokenHash = arg.TokenHash
+	d.tokens[idx].ExpiryDate = arg.ExpiryDate
+	d.tokens[idx].LastUsedAt = dbNow()
+	d.tokens[idx].UpdatedAt = dbNow()
+	return d.tokens[idx], nil
+}
+
+func (d *DBQueriesMock) TouchTokenById(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	idx := slices.IndexFunc(d.tokens, func(t db.RefreshToken) bool { return t.ID == id })
+	if idx == -1 {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	d.tokens[idx].LastUsedAt = dbNow()
+	return d.tokens[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteUserTokenById(ctx context.Context, arg db.DeleteUserTokenByIdParams) (db.RefreshToken, error) {
+	idx := slices.IndexFunc(d.tokens, func(t db.RefreshToken) bool { return t.ID == arg.ID && t.UserID == arg.UserID })
+	if idx == -1 {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	token := d.tokens[idx]
+	d.deleteToken(token.ID)
+	return token, nil
 }
 
 func (d *DBQueriesMock) DeleteTokenByUserId(ctx context.Context, id uuid.UUID) error {
+	for _, token := range slices.Clone(d.tokens) {
+		if token.UserID == id {
+			d.deleteToken(token.ID)
+		}
+	}
+	return nil
+}
+
+func (d *DBQueriesMock) DeleteOtherTokensByUserId(ctx context.Context, arg db.DeleteOtherTokensByUserIdParams) error {
+	for _, token := range slices.Clone(d.tokens) {
+		if token.UserID == arg.UserID && token.ID != arg.ID {
+			d.deleteToken(token.ID)
+		}
+	}
 	return nil
 }
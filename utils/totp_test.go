@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTotpCode(t *testing.T) {
+	// test vectors from RFC 6238 appendix B, cut to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range vectors {
+		got, err := TotpCode(secret, TotpStep(time.Unix(unix, 0)))
+		if err != nil || got != want {
+			t.Fatalf("TotpCode(secret, %d) = %s, %v, want %s", unix, got, err, want)
+		}
+	}
+}
+
+func TestValidateTotp(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	if err != nil {
+		t.Fatalf("GenerateTotpSecret() = %v", err)
+	}
+	now := time.Now()
+	previous, _ := TotpCode(secret, TotpStep(now)-1)
+
+	step, ok := ValidateTotp(secret, previous, now, 1)
+	if !ok || step != TotpStep(now)-1 {
+		t.Fatalf("ValidateTotp(previous code) = %d, %v, want %d, true", step, ok, TotpStep(now)-1)
+	}
+	if _, ok := ValidateTotp(secret, previous, now, 0); ok {
+		t.Fatalf("ValidateTotp(previous code) without skew = true, want false")
+	}
+	if _, ok := ValidateTotp(secret, "12345", now, 1); ok {
+		t.Fatalf("ValidateTotp(short code) = true, want false")
+	}
+}
+
+func TestTotpProvisioningUri(t *testing.T) {
+	uri := TotpProvisioningUri("Tennis Analysis", "laurin@test.de", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/Tennis%20Analysis:laurin@test.de?") || !strings.Contains(uri, "secret=ABC") {
+		t.Fatalf("TotpProvisioningUri() = %s", uri)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil || len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes(10) = %v, %v", codes, err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || seen[code] {
+			t.Fatalf("GenerateRecoveryCodes(10) returned bad or duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) UpsertTotpSecret(ctx context.Context, arg db.UpsertTotpSecretParams) (db.UserTotp, error) {
+	if !d.hasUser(arg.UserID) {
+		return db.UserTotp{}, foreignKeyViolation("user_totp", "FK_User_totp.user_id")
+	}
+	totp := db.UserTotp{
+		UserID:    arg.UserID,
+		Secret:    arg.Secret,
+		CreatedAt: dbNow(),
+	}
+
+	idx := slices.IndexFunc(d.totps, func(t db.UserTotp) bool { return t.UserID == arg.UserID })
+	if idx == -1 {
+		d.totps = append(d.totps, totp)
+	} else {
+		d.totps[idx] = totp
+	}
+
+	return totp, nil
+}
+
+func (d *DBQueriesMock) GetTotpByUserId(ctx context.Context, userId uuid.UUID) (db.UserTotp, error) {
+	idx := slices.IndexFunc(d.totps, func(t db.UserTotp) bool { return t.UserID == userId })
+	if idx == -1 {
+		return db.UserTotp{}, sql.ErrNoRows
+	}
+	return d.totps[idx], nil
+}
+
+func (d *DBQueriesMock) ConfirmTotpByUserId(ctx context.Context, arg db.ConfirmTotpByUserIdParams) (db.UserTotp, error) {
+	idx := slices.IndexFunc(d.totps, func(t db.UserTotp) bool { return t.UserID == arg.UserID })
+	if idx == -1 {
+		return db.UserTotp{}, sql.ErrNoRows
+	}
+	d.totps[idx].ConfirmedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	d.totps[idx].LastUsedStep = arg.LastUsedStep
+	return d.totps[idx], nil
+}
+
+func (d *DBQueriesMock) UseTotpStep(ctx context.Context, arg db.UseTotpStepParams) (db.UserTotp, error) {
+	idx := slices.IndexFunc(d.totps, func(t db.UserTotp) bool {
+		return t.UserID == arg.UserID && t.LastUsedStep < arg.LastUsedStep
+	})
+	if idx == -1 {
+		return db.UserTotp{}, sql.ErrNoRows
+	}
+	d.totps[idx].LastUsedStep = arg.LastUsedStep
+	return d.totps[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteTotpByUserId(ctx context.Context, userId uuid.UUID) error {
+	var tempTotps []db.UserTotp
+	for _, totp := range d.totps {
+		if totp.UserID != userId {
+			tempTotps = append(tempTotps, totp)
+		}
+	}
+	d.totps = tempTotps
+	return nil
+}
+
+func (d *DBQueriesMock) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	if !d.hasUser(arg.UserID) {
+		return db.RecoveryCode{}, foreignKeyViolation("recovery_codes", "FK_Recovery_codes.user_id")
+	}
+	code := db.RecoveryCode{
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		CodeHash:  arg.CodeHash,
+		CreatedAt: dbNow(),
+	}
+
+	d.recoveryCodes = append(d.recoveryCodes, code)
+
+	return code, nil
+}
+
+func (d *DBQueriesMock) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	idx := slices.IndexFunc(d.recoveryCodes, func(c db.RecoveryCode) bool {
+		return c.UserID == arg.UserID && c.CodeHash == arg.CodeHash && !c.UsedAt.Valid
+	})
+	if idx == -1 {
+		return db.RecoveryCode{}, sql.ErrNoRows
+	}
+	d.recoveryCodes[idx].UsedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	return d.recoveryCodes[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteRecoveryCodesByUserId(ctx context.Context, userId uuid.UUID) error {
+	var tempCodes []db.RecoveryCode
+	for _, code := range d.recoveryCodes {
+		if code.UserID != userId {
+			tempCodes = append(tempCodes, code)
+		}
+	}
+	d.recoveryCodes = tempCodes
+	return nil
+}
+
+func (d *DBQueriesMock) CreateTwoFactorChallenge(ctx context.Context, arg db.CreateTwoFactorChallengeParams) (db.TwoFactorChallenge, error) {
+	if contains(d.twoFactorChallenges, func(c db.TwoFactorChallenge) bool { return c.TokenHash == arg.TokenHash }) {
+		return db.TwoFactorChallenge{}, uniqueViolation("two_factor_challenges_token_hash_key")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.TwoFactorChallenge{}, foreignKeyViolation("two_factor_challenges", "FK_Two_factor_challenges.user_id")
+	}
+	challenge := db.TwoFactorChallenge{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		TokenHash:   arg.TokenHash,
+		DeviceLabel: arg.DeviceLabel,
+		ExpiryDate:  arg.ExpiryDate,
+		CreatedAt:   dbNow(),
+	}
+
+	d.twoFactorChallenges = append(d.twoFactorChallenges, challenge)
+
+	return challenge, nil
+}
+
+func (d *DBQueriesMock) GetTwoFactorChallengeByHash(ctx context.Context, tokenHash string) (db.TwoFactorChallenge, error) {
+	idx := slices.IndexFunc(d.twoFactorChallenges, func(c db.TwoFactorChallenge) bool { return c.TokenHash == tokenHash })
+	if idx == -1 {
+		return db.TwoFactorChallenge{}, sql.ErrNoRows
+	}
+	return d.twoFactorChallenges[idx], nil
+}
+
+func (d *DBQueriesMock) CountTwoFactorChallengeAttempt(ctx context.Context, id uuid.UUID) (db.TwoFactorChallenge, error) {
+	idx := slices.IndexFunc(d.twoFactorChallenges, func(c db.TwoFactorChallenge) bool { return c.ID == id })
+	if idx == -1 {
+		return db.TwoFactorChallenge{}, sql.ErrNoRows
+	}
+	d.twoFactorChallenges[idx].Attempts++
+	return d.twoFactorChallenges[idx], nil
+}
+
+func (d *DBQueriesMock) DeleteTwoFactorChallengeById(ctx context.Context, id uuid.UUID) error {
+	var tempChallenges []db.TwoFactorChallenge
+	for _, challenge := range d.twoFactorChallenges {
+		if challenge.ID != id {
+			tempChallenges = append(tempChallenges, challenge)
+		}
+	}
+	d.twoFactorChallenges = tempChallenges
+	return nil
+}
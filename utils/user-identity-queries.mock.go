@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Laurin-Notemann/tennis-analysis/db"
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+)
+
+func (d *DBQueriesMock) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	if contains(d.userIdentities, func(i db.UserIdentity) bool { return i.Issuer == arg.Issuer && i.Subject == arg.Subject }) {
+		return db.UserIdentity{}, uniqueViolation("user_identities_issuer_subject_unique")
+	}
+	if !d.hasUser(arg.UserID) {
+		return db.UserIdentity{}, foreignKeyViolation("user_identities", "FK_User_identities.user_id")
+	}
+	identity := db.UserIdentity{
+		ID:        uuid.New(),
+		UserID:    arg.UserID,
+		Issuer:    arg.Issuer,
+		Subject:   arg.Subject,
+		Email:     arg.Email,
+		CreatedAt: dbNow(),
+	}
+	d.userIdentities = append(d.userIdentities, identity)
+	return identity, nil
+}
+
+func (d *DBQueriesMock) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	idx := slices.IndexFunc(d.userIdentities, func(i db.UserIdentity) bool {
+		return i.Issuer == arg.Issuer && i.Subject == arg.Subject
+	})
+	if idx == -1 {
+		return db.UserIdentity{}, sql.ErrNoRows
+	}
+	return d.userIdentities[idx], nil
+}
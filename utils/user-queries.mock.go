@@ -2,9 +2,8 @@ package utils
 
 import (
 	"context"
-	"errors"
+	"database/sql"
 	"log"
-	"time"
 
 	"github.com/Laurin-Notemann/tennis-analysis/db"
 	"github.com/google/uuid"
@@ -12,18 +11,21 @@ import (
 )
 
 func (d *DBQueriesMock) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	if err := d.checkUser(uuid.Nil, arg.Username, arg.Email); err != nil {
+		return db.User{}, err
+	}
+
 	id, err := uuid.NewUUID()
 	if err != nil {
 		log.Fatalf("could not create uuid: %v", err)
 	}
 	newUser := db.User{
-		ID:             id,
-		Username:       arg.Username,
-		Email:          arg.Email,
-		PasswordHash:   arg.PasswordHash,
-		RefreshTokenID: nil,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:           id,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		CreatedAt:    dbNow(),
+		UpdatedAt:    dbNow(),
 	}
 
 	d.users = append(d.users, newUser)
@@ -38,7 +40,7 @@ func (d *DBQueriesMock) GetAllUsers(ctx context.Context) ([]db.User, error) {
 func (d *DBQueriesMock) GetUserById(ctx context.Context, id uuid.UUID) (db.User, error) {
 	idx := slices.IndexFunc(d.users, func(u db.User) bool { return u.ID == id })
 	if idx == -1 {
-		return db.User{}, errors.New("User not found")
+		return db.User{}, sql.ErrNoRows
 	}
 	user := d.users[idx]
 	return user, nil
@@ -47,7 +49,7 @@ func (d *DBQueriesMock) GetUserById(ctx context.Context, id uuid.UUID) (db.User,
 func (d *DBQueriesMock) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
 	idx := slices.IndexFunc(d.users, func(u db.User) bool { return u.Email == email })
 	if idx == -1 {
-		return db.User{}, errors.New("User not found")
+		return db.User{}, sql.ErrNoRows
 	}
 	user := d.users[idx]
 	return user, nil
@@ -56,7 +58,7 @@ func (d *DBQueriesMock) GetUserByEmail(ctx context.Context, email string) (db.Us
 func (d *DBQueriesMock) GetUserByUsername(ctx context.Context, username string) (db.User, error) {
 	idx := slices.IndexFunc(d.users, func(u db.User) bool { return u.Username == username })
 	if idx == -1 {
-		return db.User{}, errors.New("User not found")
+		return db.User{}, sql.ErrNoRows
 	}
 	user := d.users[idx]
 	return user, nil
@@ -68,15 +70,7 @@ func (d *DBQueriesMock) DeleteUserById(ctx context.Context, id uuid.UUID) (db.Us
 		return db.User{}, err
 	}
 
-	var tempUsers []db.User
-
-	for _, item := range d.users {
-		if item.ID != id {
-			tempUsers = append(tempUsers, item)
-		}
-	}
-
-	d.users = tempUsers
+	d.deleteUser(id)
 
 	return user, nil
 }
@@ -87,10 +81,50 @@ func (d *DBQueriesMock) UpdateUserById(ctx context.Context, args db.UpdateUserBy
 	if err != nil {
 		return db.User{}, err
 	}
+	if err := d.checkUser(id, args.Username, args.Email); err != nil {
+		return db.User{}, err
+	}
 
 	user.Email = args.Email
 	user.Username = args.Username
 	user.PasswordHash = args.PasswordHash
+	user.UpdatedAt = dbNow()
+
+	for idx, item := range d.users {
+		if item.ID == id {
+			d.users[idx] = user
+		}
+	}
+
+	return user, nil
+}
+
+func (d *DBQueriesMock) UpdateUserPasswordById(ctx context.Context, args db.UpdateUserPasswordByIdParams) (db.User, error) {
+	user, err := d.GetUserById(ctx, args.ID)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user.PasswordHash = args.PasswordHash
+	user.UpdatedAt = dbNow()
+
+	for idx, item := range d.users {
+		if item.ID == args.ID {
+			d.users[idx] = user
+		}
+	}
+
+	return user, nil
+}
+
+func (d *DBQueriesMock) VerifyUserEmailById(ctx context.Context, id uuid.UUID) (db.User, error) {
+	user, err := d.GetUserById(ctx, id)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user.EmailVerifiedAt = sql.NullTime{Time: dbNow(), Valid: true}
+	user.UpdatedAt = dbNow()
 
 	for idx, item := range d.users {
 		if item.ID == id {
@@ -100,3 +134,82 @@ func (d *DBQueriesMock) UpdateUserById(ctx context.Context, args db.UpdateUserBy
 
 	return user, nil
 }
+
+func (d *DBQueriesMock) UpdateUserProfileById(ctx context.Context, args db.UpdateUserProfileByIdParams) (db.User, error) {
+	user, err := d.GetUserById(ctx, args.ID)
+	if err != nil {
+		return db.User{}, err
+	}
+	if err := d.checkUser(args.ID, args.Username, args.Email); err != nil {
+		return db.User{}, err
+	}
+
+	if user.Email != args.Email {
+		user.EmailVerifiedAt = sql.NullTime{}
+	}
+	user.Username = args.Username
+	user.Email = args.Email
+	user.UpdatedAt = dbNow()
+
+	for idx, item := range d.users {
+		if item.ID == args.ID {
+			d.users[idx] = user
+		}
+	}
+
+	return user, nil
+}
+
+// CountUserResources counts what deleting the user takes down: the clubs
+// nobody else owns with their teams, players and matches, and the sessions.
+func (d *DBQueriesMock) CountUserResources(ctx context.Context, userId uuid.UUID) (db.CountUserResourcesRow, error) {
+	var count db.CountUserResourcesRow
+	var clubs []uuid.UUID
+	for _, member := range d.clubMembers {
+		if member.UserID != userId || member.Role != "owner" {
+			continue
+		}
+		if owners, _ := d.CountClubOwners(ctx, member.ClubID); owners == 1 {
+			clubs = append(clubs, member.ClubID)
+		}
+	}
+	count.Clubs = int64(len(clubs))
+
+	var players []uuid.UUID
+	for _, team := range d.teams {
+		if !slices.Contains(clubs, team.ClubID) {
+			continue
+		}
+		count.Teams++
+		for _, player := range []*uuid.UUID{&team.PlayerOne, team.PlayerTwo} {
+			if player != nil && !slices.Contains(players, *player) && d.hasPlayer(*player) {
+				players = append(players, *player)
+			}
+		}
+	}
+	count.Players = int64(len(players))
+
+	for _, match := range d.matches {
+		if slices.Contains(clubs, match.ClubID) {
+			count.Matches++
+		}
+	}
+	for _, token := range d.tokens {
+		if token.UserID == userId {
+			count.Sessions++
+		}
+	}
+	return count, nil
+}
+
+// checkUser checks the unique username and email of users, except for the
+// user that is being changed.
+func (d *DBQueriesMock) checkUser(except uuid.UUID, username string, email string) error {
+	if contains(d.users, func(u db.User) bool { return u.ID != except && u.Username == username }) {
+		return uniqueViolation("users_username_unique")
+	}
+	if contains(d.users, func(u db.User) bool { return u.ID != except && u.Email == email }) {
+		return uniqueViolation("users_email_unique")
+	}
+	return nil
+}